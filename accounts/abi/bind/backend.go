@@ -39,6 +39,20 @@ var (
 	// This error is returned by WaitDeployed if contract creation leaves an
 	// empty contract behind.
 	ErrNoCodeAfterDeploy = errors.New("no contract code after deployment")
+
+	// This error is raised when attempting to send a private transaction
+	// through a backend that doesn't implement PrivateContractTransactor.
+	ErrNoPrivateTransactions = errors.New("backend does not support private transactions")
+
+	// This error is raised when attempting to send a private transaction without
+	// a gas limit. Private contracts have no code in the public state, so the
+	// gas they need cannot be estimated.
+	ErrNoPrivateGasLimit = errors.New("private transactions require an explicit gas limit")
+
+	// This error is raised when the signer of a private transaction signs it
+	// with a chain ID. Marking it private would overwrite the replay protected
+	// signature, so that the sender couldn't be recovered anymore.
+	ErrPrivateProtected = errors.New("transaction signed with a chain ID can't be made private")
 )
 
 // ContractCaller defines the methods needed to allow operating with contract on a read
@@ -90,6 +104,15 @@ type ContractTransactor interface {
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 }
 
+// PrivateContractTransactor defines the methods needed to send private transactions.
+// Transact will try to discover this interface when TransactOpts.PrivateFor is set.
+// If the backend does not support it, Transact returns ErrNoPrivateTransactions.
+type PrivateContractTransactor interface {
+	// SendPrivatePayload hands the payload of a private transaction to the private
+	// transaction manager and returns the digest to put in the transaction instead.
+	SendPrivatePayload(ctx context.Context, payload []byte, privateFrom string, privateFor []string) ([]byte, error)
+}
+
 // ContractBackend defines the methods needed to work with contracts on a read-write basis.
 type ContractBackend interface {
 	ContractCaller
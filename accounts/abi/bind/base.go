@@ -50,7 +50,10 @@ type TransactOpts struct {
 
 	Value    *big.Int // Funds to transfer along along the transaction (nil = 0 = no funds)
 	GasPrice *big.Int // Gas price to use for the transaction execution (nil = gas price oracle)
-	GasLimit *big.Int // Gas limit to set for the transaction execution (nil = estimate + 10%)
+	GasLimit *big.Int // Gas limit to set for the transaction execution (nil = estimate + 10%, mandatory for private transactions)
+
+	PrivateFrom string   // Public key of the sender in the private transaction manager (empty = its default key)
+	PrivateFor  []string // Public keys of the recipients of a private transaction (nil = public transaction)
 
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 }
@@ -166,6 +169,11 @@ func (c *BoundContract) Transfer(opts *TransactOpts) (*types.Transaction, error)
 func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, input []byte) (*types.Transaction, error) {
 	var err error
 
+	// Private contracts have no code in the public state to estimate gas against
+	if opts.PrivateFor != nil && opts.GasLimit == nil {
+		return nil, ErrNoPrivateGasLimit
+	}
+
 	// Ensure a valid value field and resolve the account nonce
 	value := opts.Value
 	if value == nil {
@@ -205,6 +213,17 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
 		}
 	}
+	// Hand private payloads to the transaction manager, only their digest goes on chain
+	if opts.PrivateFor != nil {
+		pt, ok := c.transactor.(PrivateContractTransactor)
+		if !ok {
+			return nil, ErrNoPrivateTransactions
+		}
+		input, err = pt.SendPrivatePayload(ensureContext(opts.Context), input, opts.PrivateFrom, opts.PrivateFor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send private payload: %v", err)
+		}
+	}
 	// Create the transaction, sign it and schedule it for execution
 	var rawTx *types.Transaction
 	if contract == nil {
@@ -219,6 +238,13 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	if err != nil {
 		return nil, err
 	}
+	if opts.PrivateFor != nil {
+		// A chain ID of 1 yields the V values of private transactions
+		if signedTx.Protected() || signedTx.IsPrivate() {
+			return nil, ErrPrivateProtected
+		}
+		signedTx.SetPrivate()
+	}
 	if err := c.transactor.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
 		return nil, err
 	}
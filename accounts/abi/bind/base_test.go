@@ -0,0 +1,199 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/InsighterInc/bxmp"
+	"github.com/InsighterInc/bxmp/accounts/abi"
+	"github.com/InsighterInc/bxmp/accounts/abi/bind"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
+)
+
+// mockTransactor is a ContractTransactor that records the transactions sent
+// through it instead of executing them.
+type mockTransactor struct {
+	sent []*types.Transaction
+}
+
+func (mt *mockTransactor) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (mt *mockTransactor) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(len(mt.sent)), nil
+}
+
+func (mt *mockTransactor) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (mt *mockTransactor) EstimateGas(ctx context.Context, call bitmed.CallMsg) (*big.Int, error) {
+	return big.NewInt(21000), nil
+}
+
+func (mt *mockTransactor) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	mt.sent = append(mt.sent, tx)
+	return nil
+}
+
+// mockPrivateTransactor additionally supports private transactions, replacing
+// every payload with a fixed digest.
+type mockPrivateTransactor struct {
+	mockTransactor
+
+	payload []byte
+	from    string
+	to      []string
+}
+
+var mockDigest = bytes.Repeat([]byte{0xaa}, 64)
+
+func (mt *mockPrivateTransactor) SendPrivatePayload(ctx context.Context, payload []byte, privateFrom string, privateFor []string) ([]byte, error) {
+	mt.payload, mt.from, mt.to = payload, privateFrom, privateFor
+	return mockDigest, nil
+}
+
+const mockABI = `[{"constant":false,"inputs":[{"name":"x","type":"uint256"}],"name":"set","outputs":[],"type":"function"}]`
+
+func TestPrivateTransact(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(mockABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := parsed.Pack("set", big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	backend := new(mockPrivateTransactor)
+	contract := bind.NewBoundContract(common.Address{1}, parsed, nil, backend)
+
+	// A public transaction must not touch the transaction manager
+	opts := bind.NewKeyedTransactor(key)
+	tx, err := contract.Transact(opts, "set", big.NewInt(42))
+	if err != nil {
+		t.Fatalf("public transact failed: %v", err)
+	}
+	if tx.IsPrivate() || backend.payload != nil || !bytes.Equal(tx.Data(), input) {
+		t.Fatalf("public transaction went through the transaction manager")
+	}
+	// A private one must carry the digest and be marked private
+	opts.PrivateFrom = "A"
+	opts.PrivateFor = []string{"B", "C"}
+	opts.GasLimit = big.NewInt(100000)
+	if tx, err = contract.Transact(opts, "set", big.NewInt(42)); err != nil {
+		t.Fatalf("private transact failed: %v", err)
+	}
+	if !tx.IsPrivate() {
+		t.Errorf("transaction not marked private")
+	}
+	if tx.Gas().Cmp(opts.GasLimit) != 0 {
+		t.Errorf("gas limit mismatch: have %v, want %v", tx.Gas(), opts.GasLimit)
+	}
+	if !bytes.Equal(tx.Data(), mockDigest) {
+		t.Errorf("data mismatch: have %x, want %x", tx.Data(), mockDigest)
+	}
+	if !bytes.Equal(backend.payload, input) {
+		t.Errorf("payload mismatch: have %x, want %x", backend.payload, input)
+	}
+	if backend.from != "A" || !reflect.DeepEqual(backend.to, []string{"B", "C"}) {
+		t.Errorf("parties mismatch: have %q -> %q", backend.from, backend.to)
+	}
+	if from, err := types.Sender(types.HomesteadSigner{}, tx); err != nil || from != opts.From {
+		t.Errorf("sender mismatch: have %x (err %v), want %x", from, err, opts.From)
+	}
+	if len(backend.sent) != 2 || backend.sent[1] != tx {
+		t.Errorf("private transaction not submitted")
+	}
+}
+
+func TestPrivateTransactUnsupported(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(mockABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	backend := new(mockTransactor)
+	contract := bind.NewBoundContract(common.Address{1}, parsed, nil, backend)
+
+	opts := bind.NewKeyedTransactor(key)
+	opts.PrivateFor = []string{"B"}
+	opts.GasLimit = big.NewInt(100000)
+	if _, err := contract.Transact(opts, "set", big.NewInt(42)); err != bind.ErrNoPrivateTransactions {
+		t.Fatalf("error mismatch: have %v, want %v", err, bind.ErrNoPrivateTransactions)
+	}
+	if len(backend.sent) != 0 {
+		t.Errorf("transaction submitted despite error")
+	}
+}
+
+// Tests that private transactions aren't sent with a gas limit estimated
+// against the public state, where the private contract has no code.
+func TestPrivateTransactNoGasLimit(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(mockABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	backend := new(mockPrivateTransactor)
+	contract := bind.NewBoundContract(common.Address{1}, parsed, nil, backend)
+
+	opts := bind.NewKeyedTransactor(key)
+	opts.PrivateFor = []string{"B"}
+	if _, err := contract.Transact(opts, "set", big.NewInt(42)); err != bind.ErrNoPrivateGasLimit {
+		t.Fatalf("error mismatch: have %v, want %v", err, bind.ErrNoPrivateGasLimit)
+	}
+	if backend.payload != nil || len(backend.sent) != 0 {
+		t.Errorf("transaction submitted despite error")
+	}
+}
+
+// Tests that private transactions signed with a chain ID are refused, as marking
+// them private would corrupt their signature.
+func TestPrivateTransactProtected(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(mockABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	backend := new(mockPrivateTransactor)
+	contract := bind.NewBoundContract(common.Address{1}, parsed, nil, backend)
+
+	opts := bind.NewKeyedTransactor(key)
+	opts.PrivateFor = []string{"B"}
+	opts.GasLimit = big.NewInt(100000)
+	for _, chainID := range []int64{1, 10} {
+		opts.Signer = func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, types.NewEIP155Signer(big.NewInt(chainID)), key)
+		}
+		if _, err := contract.Transact(opts, "set", big.NewInt(42)); err != bind.ErrPrivateProtected {
+			t.Errorf("chain ID %d: error mismatch: have %v, want %v", chainID, err, bind.ErrPrivateProtected)
+		}
+	}
+	if len(backend.sent) != 0 {
+		t.Errorf("transaction submitted despite error")
+	}
+}
@@ -66,6 +66,8 @@ package {{.Package}}
 		const {{.Type}}Bin = ` + "`" + `{{.InputBin}}` + "`" + `
 
 		// Deploy{{.Type}} deploys a new BitMED contract, binding an instance of {{.Type}} to it.
+		// Setting auth.PrivateFor and auth.GasLimit deploys it as a private contract visible only to
+		// the given parties, see bind.TransactOpts.
 		func Deploy{{.Type}}(auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
 		  parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
 		  if err != nil {
@@ -287,6 +289,8 @@ import org.bitmed.geth.internal.*;
 			public final static byte[] BYTECODE = "{{.InputBin}}".getBytes();
 
 			// deploy deploys a new BitMED contract, binding an instance of {{.Type}} to it.
+			// Setting the private recipients and the gas limit on auth deploys it as a private
+			// contract, see TransactOpts.
 			public static {{.Type}} deploy(TransactOpts auth, BitmedClient client{{range .Constructor.Inputs}}, {{bindtype .Type}} {{.Name}}{{end}}) throws Exception {
 				Interfaces args = Geth.newInterfaces({{(len .Constructor.Inputs)}});
 				{{range $index, $element := .Constructor.Inputs}}
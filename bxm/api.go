@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -71,6 +72,21 @@ func (api *PublicBitmedAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().HashRate())
 }
 
+// GetPrivateStateRootForContract returns the storage root of the private
+// contract at the given address and block. Parties to the contract can compare
+// it out-of-band to detect a diverging private state.
+func (api *PublicBitmedAPI) GetPrivateStateRootForContract(address common.Address, blockNr rpc.BlockNumber) (common.Hash, error) {
+	_, privateState, err := privateStateAt(api.e, blockNr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	storageTrie := privateState.StorageTrie(address)
+	if storageTrie == nil {
+		return common.Hash{}, fmt.Errorf("private contract %x not found", address)
+	}
+	return storageTrie.Hash(), nil
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only methods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
@@ -310,39 +326,147 @@ func NewPublicDebugAPI(bxm *BitMED) *PublicDebugAPI {
 	return &PublicDebugAPI{bxm: bxm}
 }
 
-// DumpBlock retrieves the entire state of the database at a given block.
-func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber, typ string) (state.Dump, error) {
-	var publicState, privateState *state.StateDB
-	var err error
+// DumpBlock retrieves the entire state of the database at a given block. The
+// type selects the "public" state, the default, or the "private" one.
+func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber, typ *string) (state.Dump, error) {
+	publicState, privateState, err := api.stateAt(blockNr)
+	if err != nil {
+		return state.Dump{}, err
+	}
+	if typ == nil {
+		return publicState.RawDump(), nil
+	}
+	switch *typ {
+	case "public":
+		return publicState.RawDump(), nil
+	case "private":
+		return privateState.RawDump(), nil
+	default:
+		return state.Dump{}, fmt.Errorf("unknown type: '%s'", *typ)
+	}
+}
+
+// DumpPrivateBlock retrieves the entire private state of the node at a given
+// block, that is every private contract the node is party to.
+func (api *PublicDebugAPI) DumpPrivateBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
+	_, privateState, err := api.stateAt(blockNr)
+	if err != nil {
+		return state.Dump{}, err
+	}
+	return privateState.RawDump(), nil
+}
+
+// stateAt retrieves the public and private states at a given block.
+func (api *PublicDebugAPI) stateAt(blockNr rpc.BlockNumber) (*state.StateDB, *state.StateDB, error) {
 	if blockNr == rpc.PendingBlockNumber {
 		// If we're dumping the pending state, we need to request
 		// both the pending block as well as the pending state from
 		// the miner and operate on those
-		_, publicState, privateState = api.bxm.miner.Pending()
+		_, publicState, privateState := api.bxm.miner.Pending()
+		return publicState, privateState, nil
+	}
+	var block *types.Block
+	if blockNr == rpc.LatestBlockNumber {
+		block = api.bxm.blockchain.CurrentBlock()
 	} else {
-		var block *types.Block
-		if blockNr == rpc.LatestBlockNumber {
-			block = api.bxm.blockchain.CurrentBlock()
-		} else {
-			block = api.bxm.blockchain.GetBlockByNumber(uint64(blockNr))
-		}
-		if block == nil {
-			return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
-		}
-		publicState, privateState, err = api.bxm.BlockChain().StateAt(block.Root())
+		block = api.bxm.blockchain.GetBlockByNumber(uint64(blockNr))
+	}
+	if block == nil {
+		return nil, nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return api.bxm.BlockChain().StateAt(block.Root())
+}
+
+// PrivateStateProof is the result of a debug_privateStateProof call. It proves
+// the storage of a single private contract against the private state root of
+// a block, so that the parties to the contract can compare the storage root
+// out-of-band without disclosing the rest of their private state.
+type PrivateStateProof struct {
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        common.Hash     `json:"blockHash"`
+	PrivateStateRoot common.Hash     `json:"privateStateRoot"`
+	Address          common.Address  `json:"address"`
+	AccountProof     []hexutil.Bytes `json:"accountProof"`
+	StorageRoot      common.Hash     `json:"storageRoot"`
+	StorageProof     []StorageProof  `json:"storageProof"`
+}
+
+// StorageProof proves the value of a single storage slot against the storage
+// root of a contract.
+type StorageProof struct {
+	Key   common.Hash     `json:"key"`
+	Value common.Hash     `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// PrivateStateProof returns the storage root of the private contract at the
+// given address, the proof linking it to the private state root of the block
+// and the proofs of the requested storage slots.
+func (api *PublicDebugAPI) PrivateStateProof(address common.Address, storageKeys []common.Hash, blockNr rpc.BlockNumber) (*PrivateStateProof, error) {
+	block, privateState, err := privateStateAt(api.bxm, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	storageTrie := privateState.StorageTrie(address)
+	if storageTrie == nil {
+		return nil, fmt.Errorf("private contract %x not found", address)
+	}
+	accountProof, err := privateState.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	result := &PrivateStateProof{
+		BlockNumber:      hexutil.Uint64(block.NumberU64()),
+		BlockHash:        block.Hash(),
+		PrivateStateRoot: core.GetPrivateStateRoot(api.bxm.ChainDb(), block.Root()),
+		Address:          address,
+		AccountProof:     toHexSlice(accountProof),
+		StorageRoot:      storageTrie.Hash(),
+		StorageProof:     make([]StorageProof, len(storageKeys)),
+	}
+	for i, key := range storageKeys {
+		proof, err := privateState.GetStorageProof(address, key)
 		if err != nil {
-			return state.Dump{}, err
+			return nil, err
+		}
+		result.StorageProof[i] = StorageProof{
+			Key:   key,
+			Value: privateState.GetState(address, key),
+			Proof: toHexSlice(proof),
 		}
 	}
+	return result, nil
+}
 
-	switch typ {
-	case "public":
-		return publicState.RawDump(), nil
-	case "private":
-		return privateState.RawDump(), nil
+// privateStateAt returns the block with the given number and its private
+// state. The pending block isn't supported as its private state isn't
+// committed yet.
+func privateStateAt(bxm *BitMED, blockNr rpc.BlockNumber) (*types.Block, *state.StateDB, error) {
+	var block *types.Block
+	switch blockNr {
+	case rpc.PendingBlockNumber:
+		return nil, nil, errors.New("private state of the pending block is not available")
+	case rpc.LatestBlockNumber:
+		block = bxm.blockchain.CurrentBlock()
 	default:
-		return state.Dump{}, fmt.Errorf("unknown type: '%s'", typ)
+		block = bxm.blockchain.GetBlockByNumber(uint64(blockNr))
+	}
+	if block == nil {
+		return nil, nil, fmt.Errorf("block #%d not found", blockNr)
 	}
+	_, privateState, err := bxm.BlockChain().StateAt(block.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, privateState, nil
+}
+
+func toHexSlice(proof []rlp.RawValue) []hexutil.Bytes {
+	result := make([]hexutil.Bytes, len(proof))
+	for i, node := range proof {
+		result[i] = hexutil.Bytes(node)
+	}
+	return result
 }
 
 // PrivateDebugAPI is the collection of BitMED full node APIs exposed over
@@ -454,8 +578,9 @@ func (api *PrivateDebugAPI) traceBlock(block *types.Block, logConfig *vm.LogConf
 	structLogger := vm.NewStructLogger(logConfig)
 
 	config := vm.Config{
-		Debug:  true,
-		Tracer: structLogger,
+		Debug:            true,
+		Tracer:           structLogger,
+		PrivateTxManager: api.bxm.ptm,
 	}
 	if err := api.bxm.engine.VerifyHeader(blockchain, block.Header(), true); err != nil {
 		return false, structLogger.StructLogs(), err
@@ -532,7 +657,7 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.
 	}
 
 	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(context, statedb, privateStateDb, api.config, vm.Config{Debug: true, Tracer: tracer})
+	vmenv := vm.NewEVM(context, statedb, privateStateDb, api.config, vm.Config{Debug: true, Tracer: tracer, PrivateTxManager: api.bxm.ptm})
 	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas()))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
@@ -579,7 +704,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int) (co
 			return msg, context, statedb, privateStateDb, nil
 		}
 
-		vmenv := vm.NewEVM(context, statedb, privateStateDb, api.config, vm.Config{})
+		vmenv := vm.NewEVM(context, statedb, privateStateDb, api.config, vm.Config{PrivateTxManager: api.bxm.ptm})
 		gp := new(core.GasPool).AddGas(tx.Gas())
 		_, _, _, err := core.ApplyMessage(vmenv, msg, gp)
 		if err != nil {
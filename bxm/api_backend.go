@@ -33,6 +33,7 @@ import (
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rpc"
 )
 
@@ -215,6 +216,10 @@ func (b *BxmApiBackend) AccountManager() *accounts.Manager {
 	return b.bxm.AccountManager()
 }
 
+func (b *BxmApiBackend) PrivateTxManager() private.PrivateTransactionManager {
+	return b.bxm.PrivateTxManager()
+}
+
 func (b *BxmApiBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.bxm.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -17,13 +17,25 @@
 package bxm
 
 import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/ethash"
+	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/state"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/core/vm"
+	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/bxmdb"
+	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
+	"github.com/InsighterInc/bxmp/rpc"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -86,3 +98,115 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestDumpPrivateBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "private-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Create private transaction managers sharing a payload store, A sends
+	// the private contract to B while C is not a party to it.
+	newManager := func(key string) private.PrivateTransactionManager {
+		cfg := filepath.Join(dir, key+".toml")
+		content := "storagePath = \"" + filepath.Join(dir, "store") + "\"\npublickeys = [\"" + key + "\"]\n"
+		if err := ioutil.WriteFile(cfg, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		ptm, err := private.New(private.MemoryBackend, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ptm
+	}
+	sender, party, outsider := newManager("A"), newManager("B"), newManager("C")
+
+	// Store 0x0a at slot 0 and deploy a single byte of runtime code.
+	code := common.FromHex("600a600055600160005360016000f3")
+	digest, err := sender.Send(code, "A", []string{"B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(types.NewContractCreation(0, new(big.Int), big.NewInt(1000000), new(big.Int), digest), types.HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.SetPrivate()
+
+	var (
+		config  = params.BitmedTestChainConfig
+		gspec   = &core.Genesis{Config: config, GasLimit: 10000000}
+		engine  = ethash.NewFaker()
+		newNode = func(ptm private.PrivateTransactionManager) *core.BlockChain {
+			db, _ := bxmdb.NewMemDatabase()
+			gspec.MustCommit(db)
+			bc, err := core.NewBlockChain(db, config, engine, vm.Config{PrivateTxManager: ptm})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return bc
+		}
+		partyChain    = newNode(party)
+		outsiderChain = newNode(outsider)
+	)
+	defer partyChain.Stop()
+	defer outsiderChain.Stop()
+
+	// Assemble the block on the party node and import it on both nodes.
+	parent := partyChain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent),
+		GasUsed:    new(big.Int),
+		Time:       new(big.Int).Add(parent.Time(), big.NewInt(10)),
+	}
+	if err := engine.Prepare(partyChain, header); err != nil {
+		t.Fatal(err)
+	}
+	publicState, privateState, err := partyChain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicState.Prepare(tx.Hash(), common.Hash{}, 0)
+	privateState.Prepare(tx.Hash(), common.Hash{}, 0)
+	receipt, _, _, err := core.ApplyTransaction(config, partyChain, nil, new(core.GasPool).AddGas(header.GasLimit), publicState, privateState, header, tx, header.GasUsed, vm.Config{PrivateTxManager: party})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := engine.Finalize(partyChain, header, publicState, types.Transactions{tx}, nil, types.Receipts{receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bc := range []*core.BlockChain{partyChain, outsiderChain} {
+		if _, err := bc.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contract := crypto.CreateAddress(crypto.PubkeyToAddress(key.PublicKey), 0)
+	dump, err := (&PublicDebugAPI{bxm: &BitMED{blockchain: partyChain}}).DumpPrivateBlock(rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, ok := dump.Accounts[common.Bytes2Hex(contract.Bytes())]
+	if !ok {
+		t.Fatalf("private contract %x missing from party dump: %v", contract, dumper.Sdump(dump))
+	}
+	if account.Code != "01" {
+		t.Errorf("private contract code mismatch: have %q, want %q", account.Code, "01")
+	}
+	if value := account.Storage[common.Bytes2Hex(common.Hash{}.Bytes())]; value != "0a" {
+		t.Errorf("private contract storage mismatch: have %q, want %q", value, "0a")
+	}
+
+	dump, err = (&PublicDebugAPI{bxm: &BitMED{blockchain: outsiderChain}}).DumpPrivateBlock(rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Accounts) != 0 {
+		t.Errorf("expected empty private dump on non-party node, got %v", dumper.Sdump(dump))
+	}
+}
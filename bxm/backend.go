@@ -49,6 +49,7 @@ import (
 	"github.com/InsighterInc/bxmp/node"
 	"github.com/InsighterInc/bxmp/p2p"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rlp"
 	"github.com/InsighterInc/bxmp/rpc"
 )
@@ -80,6 +81,7 @@ type BitMED struct {
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
 	accountManager *accounts.Manager
+	ptm            private.PrivateTransactionManager
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
@@ -156,11 +158,26 @@ func New(ctx *node.ServiceContext, config *Config) (*BitMED, error) {
 		core.WriteBlockChainVersion(chainDb, core.BlockChainVersion)
 	}
 
-	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
+	if config.PrivateTxManager != "" {
+		if bxm.ptm, err = private.New(config.PrivateTxManager, config.PrivateConfig); err != nil {
+			return nil, fmt.Errorf("private transaction manager: %v", err)
+		}
+		log.Info("Initialised private transaction manager", "backend", config.PrivateTxManager, "config", config.PrivateConfig)
+	}
+
+	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording, PrivateTxManager: bxm.ptm}
 	bxm.blockchain, err = core.NewBlockChain(chainDb, bxm.chainConfig, bxm.engine, vmConfig)
 	if err != nil {
 		return nil, err
 	}
+	if config.AccountPolicy != "" {
+		policy, err := core.LoadAccountPolicy(ctx.ResolvePath(config.AccountPolicy))
+		if err != nil {
+			return nil, err
+		}
+		bxm.blockchain.SetAccountPolicy(policy)
+		log.Info("Loaded account policy", "path", config.AccountPolicy, "default", policy.Default, "accounts", len(policy.Accounts))
+	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -231,6 +248,30 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *Config, chainConfig
 			config.Istanbul.Epoch = chainConfig.Istanbul.Epoch
 		}
 		config.Istanbul.ProposerPolicy = istanbul.ProposerPolicy(chainConfig.Istanbul.ProposerPolicy)
+		if chainConfig.Istanbul.BlockPeriod != 0 {
+			config.Istanbul.BlockPeriod = chainConfig.Istanbul.BlockPeriod
+			if config.Istanbul.BlockPauseTime < config.Istanbul.BlockPeriod {
+				config.Istanbul.BlockPauseTime = config.Istanbul.BlockPeriod
+			}
+		}
+		if chainConfig.Istanbul.Governance != nil {
+			config.Istanbul.Governance = *chainConfig.Istanbul.Governance
+		}
+		if chainConfig.Istanbul.RequestTimeout != 0 {
+			config.Istanbul.RequestTimeout = chainConfig.Istanbul.RequestTimeout
+		}
+		if chainConfig.Istanbul.BlockPauseTime != 0 {
+			config.Istanbul.BlockPauseTime = chainConfig.Istanbul.BlockPauseTime
+		}
+		config.Istanbul.Transitions = nil
+		for _, t := range chainConfig.Istanbul.Transitions {
+			config.Istanbul.Transitions = append(config.Istanbul.Transitions, istanbul.Transition{
+				Block:          t.Block,
+				RequestTimeout: t.RequestTimeout,
+				BlockPeriod:    t.BlockPeriod,
+				BlockPauseTime: t.BlockPauseTime,
+			})
+		}
 		return istanbulBackend.New(&config.Istanbul, ctx.NodeKey(), db)
 	}
 
@@ -390,6 +431,10 @@ func (s *BitMED) BxmVersion() int                    { return int(s.protocolMana
 func (s *BitMED) NetVersion() uint64                 { return s.networkId }
 func (s *BitMED) Downloader() *downloader.Downloader { return s.protocolManager.downloader }
 
+// PrivateTxManager returns the node's private transaction manager, nil if
+// private transactions are disabled.
+func (s *BitMED) PrivateTxManager() private.PrivateTransactionManager { return s.ptm }
+
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *BitMED) Protocols() []p2p.Protocol {
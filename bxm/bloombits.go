@@ -120,9 +120,14 @@ func (b *BloomIndexer) Reset(section uint64) {
 }
 
 // Process implements core.ChainIndexerBackend, adding a new header's bloom into
-// the index.
+// the index, merged with the bloom of the private logs of the block.
 func (b *BloomIndexer) Process(header *types.Header) {
-	b.gen.AddBloom(uint(header.Number.Uint64()-b.section*b.size), header.Bloom)
+	bloom := header.Bloom
+	private := core.GetPrivateBlockBloom(b.db, header.Hash(), header.Number.Uint64())
+	for i := range bloom {
+		bloom[i] |= private[i]
+	}
+	b.gen.AddBloom(uint(header.Number.Uint64()-b.section*b.size), bloom)
 	b.head = header.Hash()
 }
 
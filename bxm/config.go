@@ -113,6 +113,14 @@ type Config struct {
 
 	RaftMode             bool
 	EnableNodePermission bool
+
+	// Private transaction manager options
+	PrivateTxManager string `toml:",omitempty"` // Backend resolving private payloads, disabled if empty
+	PrivateConfig    string `toml:",omitempty"` // Backend specific configuration file
+
+	// Account permission options
+	AccountPolicy string `toml:",omitempty"` // JSON file restricting the transactions of the accounts, none if empty
+
 	// Istanbul options
 	Istanbul istanbul.Config
 
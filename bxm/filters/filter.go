@@ -182,7 +182,10 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 		if header == nil || err != nil {
 			return logs, err
 		}
-		if bloomFilter(header.Bloom, f.addresses, f.topics) {
+		// BitMED
+		// Private logs are only in the private bloom of the block
+		private := core.GetPrivateBlockBloom(f.db, header.Hash(), header.Number.Uint64())
+		if bloomFilter(header.Bloom, f.addresses, f.topics) || bloomFilter(private, f.addresses, f.topics) {
 			found, err := f.checkMatches(ctx, header)
 			if err != nil {
 				return logs, err
@@ -240,3 +240,59 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+func TestPrivateFilters(t *testing.T) {
+	var (
+		db, _      = bxmdb.NewMemDatabase()
+		mux        = new(event.TypeMux)
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr       = crypto.PubkeyToAddress(key1.PublicKey)
+		contract   = common.BytesToAddress([]byte("private"))
+		txHash     = common.BytesToHash([]byte("tx"))
+	)
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, _ := core.GenerateChain(params.TestChainConfig, genesis, db, 10, func(i int, gen *core.BlockGen) {})
+
+	for _, block := range chain {
+		core.WriteBlock(db, block)
+		if err := core.WriteCanonicalHash(db, block.Hash(), block.NumberU64()); err != nil {
+			t.Fatalf("failed to insert block number: %v", err)
+		}
+		if err := core.WriteHeadBlockHash(db, block.Hash()); err != nil {
+			t.Fatalf("failed to insert block number: %v", err)
+		}
+		var receipts, privateReceipts types.Receipts
+		if block.NumberU64() == 5 {
+			// The public receipt of a private transaction has no logs, the
+			// private one follows the public receipts
+			receipt := types.NewReceipt(nil, false, new(big.Int))
+			receipt.TxHash = txHash
+			receipts = append(receipts, receipt)
+
+			privateReceipt := makeReceipt(contract)
+			privateReceipt.TxHash = txHash
+			privateReceipts = append(privateReceipts, privateReceipt)
+		}
+		if err := core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), append(receipts, privateReceipts...)); err != nil {
+			t.Fatal("error writing block receipts:", err)
+		}
+		if err := core.WritePrivateBlockBloom(db, block.Hash(), block.NumberU64(), privateReceipts); err != nil {
+			t.Fatal("error writing private bloom:", err)
+		}
+	}
+	logs, err := New(backend, 0, -1, []common.Address{contract}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(logs))
+	}
+	if logs[0].Address != contract || !logs[0].Private {
+		t.Errorf("log mismatch: have %+v", logs[0])
+	}
+}
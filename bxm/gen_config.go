@@ -37,6 +37,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		PrivateTxManager        string `toml:",omitempty"`
+		PrivateConfig           string `toml:",omitempty"`
+		AccountPolicy           string `toml:",omitempty"`
 		Istanbul                istanbul.Config
 		DocRoot                 string `toml:"-"`
 		PowFake                 bool   `toml:"-"`
@@ -65,6 +68,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.PrivateTxManager = c.PrivateTxManager
+	enc.PrivateConfig = c.PrivateConfig
+	enc.AccountPolicy = c.AccountPolicy
 	enc.Istanbul = c.Istanbul
 	enc.DocRoot = c.DocRoot
 	enc.PowFake = c.PowFake
@@ -97,6 +103,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		PrivateTxManager        *string `toml:",omitempty"`
+		PrivateConfig           *string `toml:",omitempty"`
+		AccountPolicy           *string `toml:",omitempty"`
 		Istanbul                *istanbul.Config
 		DocRoot                 *string `toml:"-"`
 		PowFake                 *bool   `toml:"-"`
@@ -170,6 +179,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.PrivateTxManager != nil {
+		c.PrivateTxManager = *dec.PrivateTxManager
+	}
+	if dec.PrivateConfig != nil {
+		c.PrivateConfig = *dec.PrivateConfig
+	}
+	if dec.AccountPolicy != nil {
+		c.AccountPolicy = *dec.AccountPolicy
+	}
 	if dec.Istanbul != nil {
 		c.Istanbul = *dec.Istanbul
 	}
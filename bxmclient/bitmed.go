@@ -0,0 +1,308 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package bxmclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/InsighterInc/bxmp"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/backend"
+	istanbulCore "github.com/InsighterInc/bxmp/consensus/istanbul/core"
+	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/raft"
+)
+
+// SendTxArgs represents the arguments of a transaction that is signed by one of
+// the unlocked accounts of the node rather than by the client.
+type SendTxArgs struct {
+	From     common.Address  // the sender of the transaction, must be unlocked on the node
+	To       *common.Address // the destination contract (nil for contract creation)
+	Gas      *big.Int        // if nil, the node uses its default gas
+	GasPrice *big.Int        // if nil, the node suggests a gas price
+	Value    *big.Int        // amount of wei sent along with the transaction
+	Data     []byte          // input data, usually an ABI-encoded contract method invocation
+	Nonce    *uint64         // if nil, the node uses the pending nonce of the sender
+
+	PrivateFrom string   // public key of the sender in the transaction manager (empty = its default key)
+	PrivateFor  []string // public keys of the recipients (nil = public transaction)
+}
+
+// Private Transactions
+
+// SendTransactionArgs creates a transaction from the given arguments, has the
+// node sign it with the sending account and injects it into the pending pool.
+// Setting PrivateFor sends the payload through the node's private transaction
+// manager and submits a private transaction.
+func (ec *Client) SendTransactionArgs(ctx context.Context, args SendTxArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := ec.c.CallContext(ctx, &hash, "bxm_sendTransaction", toSendTxArg(args))
+	return hash, err
+}
+
+// SendTransactionAsync is like SendTransactionArgs, but returns as soon as the
+// node accepted the request, without waiting for the transaction manager. If
+// callbackURL is not empty, the node POSTs the outcome to it as a JSON object
+// holding either the transaction hash or an error.
+func (ec *Client) SendTransactionAsync(ctx context.Context, args SendTxArgs, callbackURL string) error {
+	arg := toSendTxArg(args)
+	if callbackURL != "" {
+		arg["callbackUrl"] = callbackURL
+	}
+	return ec.c.CallContext(ctx, nil, "bxm_sendTransactionAsync", arg)
+}
+
+// SendPrivatePayload hands the payload of a private transaction to the private
+// transaction manager of the node, which distributes it to the given recipients.
+// It returns the digest to put in the transaction, which must then be marked
+// private with SetPrivate after signing.
+func (ec *Client) SendPrivatePayload(ctx context.Context, payload []byte, privateFrom string, privateFor []string) ([]byte, error) {
+	var digest hexutil.Bytes
+	err := ec.c.CallContext(ctx, &digest, "bxm_sendPrivatePayload", hexutil.Bytes(payload), privateFrom, privateFor)
+	return digest, err
+}
+
+// BitmedPayload returns the payload stored under the given digest by the node's
+// private transaction manager. The payload is empty if the node is not a party
+// to the private transaction.
+func (ec *Client) BitmedPayload(ctx context.Context, digest []byte) ([]byte, error) {
+	var payload hexutil.Bytes
+	err := ec.c.CallContext(ctx, &payload, "bxm_getBitmedPayload", hexutil.Encode(digest))
+	return payload, err
+}
+
+// PrivateTransactionReceipt returns the receipt of a private transaction
+// against the private state, holding the logs of the private contracts. It
+// returns NotFound if the transaction is public or not yet mined.
+func (ec *Client) PrivateTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	var r *types.Receipt
+	err := ec.c.CallContext(ctx, &r, "bxm_getPrivateTransactionReceipt", hash)
+	if err == nil && r == nil {
+		return nil, bitmed.NotFound
+	}
+	return r, err
+}
+
+// PrivateTransactionParties returns the private transaction manager keys the
+// private transaction with the given hash was sent from and to. It returns nil
+// if the node neither sent nor received the transaction.
+func (ec *Client) PrivateTransactionParties(ctx context.Context, hash common.Hash) (*core.PrivateTxParties, error) {
+	var parties *core.PrivateTxParties
+	err := ec.c.CallContext(ctx, &parties, "bxm_getPrivateTransactionParties", hash)
+	return parties, err
+}
+
+// PrivateTransactionsByParty returns the hashes of the private transactions the
+// node sent from or to the given private transaction manager key.
+func (ec *Client) PrivateTransactionsByParty(ctx context.Context, key string) ([]common.Hash, error) {
+	var hashes []common.Hash
+	err := ec.c.CallContext(ctx, &hashes, "bxm_getPrivateTransactionsByParty", key)
+	return hashes, err
+}
+
+func toSendTxArg(args SendTxArgs) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": args.From,
+		"to":   args.To,
+	}
+	if len(args.Data) > 0 {
+		arg["data"] = hexutil.Bytes(args.Data)
+	}
+	if args.Value != nil {
+		arg["value"] = (*hexutil.Big)(args.Value)
+	}
+	if args.Gas != nil {
+		arg["gas"] = (*hexutil.Big)(args.Gas)
+	}
+	if args.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(args.GasPrice)
+	}
+	if args.Nonce != nil {
+		arg["nonce"] = hexutil.Uint64(*args.Nonce)
+	}
+	if args.PrivateFrom != "" {
+		arg["privateFrom"] = args.PrivateFrom
+	}
+	if args.PrivateFor != nil {
+		arg["privateFor"] = args.PrivateFor
+	}
+	return arg
+}
+
+// Raft
+
+// RaftRole returns the role of the node in the Raft cluster, either "minter"
+// or "verifier".
+func (ec *Client) RaftRole(ctx context.Context) (string, error) {
+	var role string
+	err := ec.c.CallContext(ctx, &role, "raft_role")
+	return role, err
+}
+
+// RaftAddPeer proposes adding the node with the given enode URL to the Raft
+// cluster and returns the Raft ID assigned to it.
+func (ec *Client) RaftAddPeer(ctx context.Context, enode string) (uint16, error) {
+	var raftID uint16
+	err := ec.c.CallContext(ctx, &raftID, "raft_addPeer", enode)
+	return raftID, err
+}
+
+// RaftRemovePeer proposes removing the member with the given Raft ID from the
+// Raft cluster.
+func (ec *Client) RaftRemovePeer(ctx context.Context, raftID uint16) error {
+	return ec.c.CallContext(ctx, nil, "raft_removePeer", raftID)
+}
+
+// RaftTransferLeadership hands the minter role over to the voting member with
+// the given Raft ID. It must be called on the current minter.
+func (ec *Client) RaftTransferLeadership(ctx context.Context, raftID uint16) error {
+	return ec.c.CallContext(ctx, nil, "raft_transferLeadership", raftID)
+}
+
+// RaftLeader returns the enode ID of the current minter of the Raft cluster.
+func (ec *Client) RaftLeader(ctx context.Context) (string, error) {
+	var leader string
+	err := ec.c.CallContext(ctx, &leader, "raft_leader")
+	return leader, err
+}
+
+// RaftCluster returns the members of the Raft cluster. If the node is the
+// minter, the members include their replication progress.
+func (ec *Client) RaftCluster(ctx context.Context) ([]*raft.ClusterInfo, error) {
+	var cluster []*raft.ClusterInfo
+	err := ec.c.CallContext(ctx, &cluster, "raft_cluster")
+	return cluster, err
+}
+
+// RaftNodeInfo returns the state of the node in the Raft cluster.
+func (ec *Client) RaftNodeInfo(ctx context.Context) (*raft.RaftNodeInfo, error) {
+	var info *raft.RaftNodeInfo
+	err := ec.c.CallContext(ctx, &info, "raft_nodeInfo")
+	return info, err
+}
+
+// Istanbul
+
+// IstanbulSnapshot returns the Istanbul voting snapshot at the given block.
+// The block number can be nil, in which case the latest known block is used.
+func (ec *Client) IstanbulSnapshot(ctx context.Context, number *big.Int) (*backend.Snapshot, error) {
+	var snap *backend.Snapshot
+	err := ec.c.CallContext(ctx, &snap, "istanbul_getSnapshot", toBlockNumArg(number))
+	if err == nil && snap == nil {
+		err = bitmed.NotFound
+	}
+	return snap, err
+}
+
+// IstanbulSnapshotAtHash returns the Istanbul voting snapshot at the given block.
+func (ec *Client) IstanbulSnapshotAtHash(ctx context.Context, hash common.Hash) (*backend.Snapshot, error) {
+	var snap *backend.Snapshot
+	err := ec.c.CallContext(ctx, &snap, "istanbul_getSnapshotAtHash", hash)
+	if err == nil && snap == nil {
+		err = bitmed.NotFound
+	}
+	return snap, err
+}
+
+// IstanbulValidators returns the validators authorized at the given block in
+// ascending order. The block number can be nil, in which case the latest known
+// block is used.
+func (ec *Client) IstanbulValidators(ctx context.Context, number *big.Int) ([]common.Address, error) {
+	var validators []common.Address
+	err := ec.c.CallContext(ctx, &validators, "istanbul_getValidators", toBlockNumArg(number))
+	return validators, err
+}
+
+// IstanbulValidatorsAtHash returns the validators authorized at the given block
+// in ascending order.
+func (ec *Client) IstanbulValidatorsAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	var validators []common.Address
+	err := ec.c.CallContext(ctx, &validators, "istanbul_getValidatorsAtHash", hash)
+	return validators, err
+}
+
+// IstanbulStatus returns a summary of the consensus state of the current view.
+// It fails if the node is not running the consensus engine, i.e. not mining.
+func (ec *Client) IstanbulStatus(ctx context.Context) (*istanbulCore.Status, error) {
+	var status *istanbulCore.Status
+	err := ec.c.CallContext(ctx, &status, "istanbul_status")
+	return status, err
+}
+
+// IstanbulRoundState returns the consensus messages collected from each
+// validator in the current view.
+func (ec *Client) IstanbulRoundState(ctx context.Context) (*istanbulCore.RoundState, error) {
+	var state *istanbulCore.RoundState
+	err := ec.c.CallContext(ctx, &state, "istanbul_roundState")
+	return state, err
+}
+
+// IstanbulSignerStatus reports how many of the given number of most recent
+// blocks each validator proposed and sealed. If blocks is nil, the node uses its
+// default.
+func (ec *Client) IstanbulSignerStatus(ctx context.Context, blocks *uint64) (*backend.SignerActivity, error) {
+	var activity *backend.SignerActivity
+	err := ec.c.CallContext(ctx, &activity, "istanbul_getSignerStatus", blocks)
+	return activity, err
+}
+
+// IstanbulMisbehaviour returns the recorded evidence of validators sending
+// conflicting consensus messages. If validator is nil, that of all validators is
+// returned.
+func (ec *Client) IstanbulMisbehaviour(ctx context.Context, validator *common.Address) ([]*istanbul.Misbehaviour, error) {
+	var misbehaviours []*istanbul.Misbehaviour
+	err := ec.c.CallContext(ctx, &misbehaviours, "istanbul_getMisbehaviour", validator)
+	return misbehaviours, err
+}
+
+// IstanbulCandidates returns the candidates the node currently votes on, mapped
+// to whether it votes to authorize or to kick them.
+func (ec *Client) IstanbulCandidates(ctx context.Context) (map[common.Address]bool, error) {
+	var candidates map[common.Address]bool
+	err := ec.c.CallContext(ctx, &candidates, "istanbul_candidates")
+	return candidates, err
+}
+
+// IstanbulPropose makes the node vote on authorizing (auth = true) or kicking
+// the given validator in the blocks it proposes.
+func (ec *Client) IstanbulPropose(ctx context.Context, address common.Address, auth bool) error {
+	return ec.c.CallContext(ctx, nil, "istanbul_propose", address, auth)
+}
+
+// IstanbulDiscard drops the given candidate, stopping the node from voting on it.
+func (ec *Client) IstanbulDiscard(ctx context.Context, address common.Address) error {
+	return ec.c.CallContext(ctx, nil, "istanbul_discard", address)
+}
+
+// IstanbulWeightCandidates returns the proposer selection weights the node
+// currently votes on.
+func (ec *Client) IstanbulWeightCandidates(ctx context.Context) (map[common.Address]uint64, error) {
+	var candidates map[common.Address]uint64
+	err := ec.c.CallContext(ctx, &candidates, "istanbul_weightCandidates")
+	return candidates, err
+}
+
+// IstanbulProposeWeight makes the node vote on the proposer selection weight of
+// the given validator in the blocks it proposes. It fails unless the network
+// uses the weighted proposer policy.
+func (ec *Client) IstanbulProposeWeight(ctx context.Context, address common.Address, weight uint64) error {
+	return ec.c.CallContext(ctx, nil, "istanbul_proposeWeight", address, weight)
+}
@@ -0,0 +1,275 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package bxmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/accounts"
+	"github.com/InsighterInc/bxmp/accounts/keystore"
+	"github.com/InsighterInc/bxmp/bxm"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/backend"
+	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/node"
+	"github.com/InsighterInc/bxmp/p2p"
+	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
+	"github.com/InsighterInc/bxmp/rlp"
+)
+
+type testNode struct {
+	stack     *node.Node
+	client    *Client
+	account   accounts.Account
+	validator common.Address
+	workspace string
+}
+
+// newTestNode starts an in-process Istanbul node with an in-memory private
+// transaction manager and a funded, unlocked account.
+func newTestNode(t *testing.T) *testNode {
+	workspace, err := ioutil.TempDir("", "bxmclient-tester-")
+	if err != nil {
+		t.Fatalf("failed to create temporary workspace: %v", err)
+	}
+	nodeKey, _ := crypto.GenerateKey()
+	stack, err := node.New(&node.Config{
+		DataDir:           workspace,
+		UseLightweightKDF: true,
+		Name:              "bxmclient-tester",
+		P2P:               p2p.Config{PrivateKey: nodeKey, MaxPeers: 0, NoDiscovery: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	validator := crypto.PubkeyToAddress(nodeKey.PublicKey)
+	extra, _ := rlp.EncodeToBytes(&types.IstanbulExtra{Validators: []common.Address{validator}, Seal: []byte{}, CommittedSeal: [][]byte{}})
+
+	config := *params.TestChainConfig
+	config.Ethash, config.Istanbul = nil, &params.IstanbulConfig{}
+	bxmConf := bxm.DefaultConfig
+	bxmConf.Genesis = &core.Genesis{
+		Config:     &config,
+		GasLimit:   4712388,
+		Difficulty: big.NewInt(1),
+		Mixhash:    types.IstanbulDigest,
+		ExtraData:  append(make([]byte, types.IstanbulExtraVanity), extra...),
+		Alloc:      core.GenesisAlloc{account.Address: {Balance: big.NewInt(1e18)}},
+	}
+	bxmConf.PrivateTxManager = private.MemoryBackend
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) { return bxm.New(ctx, &bxmConf) }); err != nil {
+		t.Fatalf("failed to register BitMED protocol: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start test stack: %v", err)
+	}
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return &testNode{
+		stack:     stack,
+		client:    NewClient(rpcClient),
+		account:   account,
+		validator: validator,
+		workspace: workspace,
+	}
+}
+
+func (n *testNode) Close() {
+	n.stack.Stop()
+	os.RemoveAll(n.workspace)
+}
+
+// TestBitmedAPIs runs the BitMED specific client methods against a single node,
+// as starting and stopping one per test is slow.
+func TestBitmedAPIs(t *testing.T) {
+	n := newTestNode(t)
+	defer n.Close()
+
+	t.Run("PrivateTransactions", func(t *testing.T) { testPrivateTransactions(t, n) })
+	t.Run("SendTransactionAsync", func(t *testing.T) { testSendTransactionAsync(t, n) })
+	t.Run("Istanbul", func(t *testing.T) { testIstanbul(t, n) })
+}
+
+func testPrivateTransactions(t *testing.T, n *testNode) {
+	ctx := context.Background()
+
+	payload := []byte("private payload")
+	hash, err := n.client.SendTransactionArgs(ctx, SendTxArgs{
+		From:       n.account.Address,
+		To:         &common.Address{1},
+		Data:       payload,
+		PrivateFor: []string{"B"},
+	})
+	if err != nil {
+		t.Fatalf("failed to send private transaction: %v", err)
+	}
+	tx, pending, err := n.client.TransactionByHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("failed to retrieve transaction: %v", err)
+	}
+	if !pending {
+		t.Errorf("transaction not pending")
+	}
+	if bytes.Equal(tx.Data(), payload) {
+		t.Fatalf("private payload leaked into the transaction")
+	}
+	have, err := n.client.BitmedPayload(ctx, tx.Data())
+	if err != nil {
+		t.Fatalf("failed to retrieve private payload: %v", err)
+	}
+	if !bytes.Equal(have, payload) {
+		t.Errorf("payload mismatch: have %x, want %x", have, payload)
+	}
+	// Digests handed out directly must resolve the same way
+	digest, err := n.client.SendPrivatePayload(ctx, []byte("another payload"), "", []string{"B"})
+	if err != nil {
+		t.Fatalf("failed to send private payload: %v", err)
+	}
+	if have, err := n.client.BitmedPayload(ctx, digest); err != nil || string(have) != "another payload" {
+		t.Errorf("payload mismatch: have %q (err %v), want %q", have, err, "another payload")
+	}
+}
+
+func testSendTransactionAsync(t *testing.T, n *testNode) {
+
+	results := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+			t.Errorf("invalid callback body: %v", err)
+		}
+		results <- res
+	}))
+	defer server.Close()
+
+	err := n.client.SendTransactionAsync(context.Background(), SendTxArgs{
+		From:       n.account.Address,
+		To:         &common.Address{1},
+		Data:       []byte("async payload"),
+		PrivateFor: []string{"B"},
+	}, server.URL)
+	if err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	select {
+	case res := <-results:
+		if res["error"] != "" {
+			t.Fatalf("async send failed: %v", res["error"])
+		}
+		if _, _, err := n.client.TransactionByHash(context.Background(), common.HexToHash(res["txHash"])); err != nil {
+			t.Errorf("failed to retrieve transaction %s: %v", res["txHash"], err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback timed out")
+	}
+}
+
+func testIstanbul(t *testing.T, n *testNode) {
+	ctx := context.Background()
+
+	want := []common.Address{n.validator}
+	if have, err := n.client.IstanbulValidators(ctx, nil); err != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("validators mismatch: have %x (err %v), want %x", have, err, want)
+	}
+	genesis, err := n.client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("failed to retrieve genesis: %v", err)
+	}
+	if have, err := n.client.IstanbulValidatorsAtHash(ctx, genesis.Hash()); err != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("validators mismatch: have %x (err %v), want %x", have, err, want)
+	}
+	snap, err := n.client.IstanbulSnapshot(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	if snap.Number != 0 || snap.Hash != genesis.Hash() {
+		t.Errorf("snapshot mismatch: have #%d [%x], want #0 [%x]", snap.Number, snap.Hash, genesis.Hash())
+	}
+	if snap.ValSet.Size() != 1 || snap.ValSet.GetByIndex(0).Address() != n.validator {
+		t.Errorf("snapshot validators mismatch: have %v, want %x", snap.ValSet.List(), want)
+	}
+	if snap, err := n.client.IstanbulSnapshotAtHash(ctx, genesis.Hash()); err != nil || snap.Hash != genesis.Hash() {
+		t.Errorf("snapshot at hash mismatch: have %v (err %v)", snap, err)
+	}
+
+	activity, err := n.client.IstanbulSignerStatus(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve signer status: %v", err)
+	}
+	if len(activity.Signers) != 1 || *activity.Signers[0] != (backend.SignerStatus{Address: n.validator, Validator: true}) {
+		t.Errorf("signer status mismatch: have %+v", activity.Signers)
+	}
+
+	misbehaviours, err := n.client.IstanbulMisbehaviour(ctx, nil)
+	if err != nil || len(misbehaviours) != 0 {
+		t.Errorf("misbehaviour mismatch: have %v (err %v)", misbehaviours, err)
+	}
+
+	// The node does not mine, so there is no consensus state to report
+	if _, err := n.client.IstanbulStatus(ctx); err == nil || err.Error() != istanbul.ErrStoppedEngine.Error() {
+		t.Errorf("status error mismatch: have %v, want %v", err, istanbul.ErrStoppedEngine)
+	}
+	if _, err := n.client.IstanbulRoundState(ctx); err == nil || err.Error() != istanbul.ErrStoppedEngine.Error() {
+		t.Errorf("round state error mismatch: have %v, want %v", err, istanbul.ErrStoppedEngine)
+	}
+
+	candidate := common.Address{1}
+	if err := n.client.IstanbulPropose(ctx, candidate, true); err != nil {
+		t.Fatalf("failed to propose candidate: %v", err)
+	}
+	if have, err := n.client.IstanbulCandidates(ctx); err != nil || !reflect.DeepEqual(have, map[common.Address]bool{candidate: true}) {
+		t.Errorf("candidates mismatch: have %v (err %v)", have, err)
+	}
+	if err := n.client.IstanbulDiscard(ctx, candidate); err != nil {
+		t.Fatalf("failed to discard candidate: %v", err)
+	}
+	if have, err := n.client.IstanbulCandidates(ctx); err != nil || len(have) != 0 {
+		t.Errorf("candidates mismatch: have %v (err %v), want none", have, err)
+	}
+
+	// Weights are only voted on under the weighted proposer policy
+	if err := n.client.IstanbulProposeWeight(ctx, n.validator, 2); err == nil {
+		t.Errorf("expected weight proposal to fail under the round-robin policy")
+	}
+	if have, err := n.client.IstanbulWeightCandidates(ctx); err != nil || len(have) != 0 {
+		t.Errorf("weight candidates mismatch: have %v (err %v), want none", have, err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	gometrics "github.com/rcrowley/go-metrics"
 )
@@ -149,6 +150,12 @@ func (db *LDBDatabase) NewIterator() iterator.Iterator {
 	return db.db.NewIterator(nil, nil)
 }
 
+// NewIteratorWithPrefix returns an iterator over the entries whose key starts
+// with the given prefix.
+func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
+}
+
 func (db *LDBDatabase) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -333,6 +340,28 @@ func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+func (dt *table) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	return &tableIterator{dt.db.NewIteratorWithPrefix(append([]byte(dt.prefix), prefix...)), dt.prefix}
+}
+
+// tableIterator hides the table prefix from the keys of the wrapped iterator.
+type tableIterator struct {
+	iterator.Iterator
+	prefix string
+}
+
+func (it *tableIterator) Seek(key []byte) bool {
+	return it.Iterator.Seek(append([]byte(it.prefix), key...))
+}
+
+func (it *tableIterator) Key() []byte {
+	key := it.Iterator.Key()
+	if key == nil {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
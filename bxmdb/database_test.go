@@ -190,3 +190,43 @@ func testParallelPutGet(db bxmdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IteratorWithPrefix(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIteratorWithPrefix(db, t)
+}
+
+func TestMemoryDB_IteratorWithPrefix(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	testIteratorWithPrefix(db, t)
+}
+
+func TestTable_IteratorWithPrefix(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	db.Put([]byte("abd"), []byte("outside"))
+	testIteratorWithPrefix(bxmdb.NewTable(db, "t"), t)
+}
+
+func testIteratorWithPrefix(db bxmdb.Database, t *testing.T) {
+	t.Parallel()
+
+	for _, k := range []string{"ab", "b", "abc", "a", "ac", "aa"} {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	it := db.NewIteratorWithPrefix([]byte("ab"))
+	defer it.Release()
+
+	var keys []string
+	for it.Next() {
+		if !bytes.Equal(it.Value(), []byte("v"+string(it.Key()))) {
+			t.Errorf("key %q: value mismatch: have %q", it.Key(), it.Value())
+		}
+		keys = append(keys, string(it.Key()))
+	}
+	if fmt.Sprint(keys) != "[ab abc]" {
+		t.Errorf("iterated keys mismatch: have %q, want [ab abc]", keys)
+	}
+}
@@ -16,6 +16,8 @@
 
 package bxmdb
 
+import "github.com/syndtr/goleveldb/leveldb/iterator"
+
 // Code using batches should try to add this much data to the batch.
 // The value was determined empirically.
 const IdealBatchSize = 100 * 1024
@@ -33,6 +35,7 @@ type Database interface {
 	Delete(key []byte) error
 	Close()
 	NewBatch() Batch
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
 }
 
 // Batch is a write-only database that commits changes to its host database
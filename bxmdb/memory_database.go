@@ -18,9 +18,13 @@ package bxmdb
 
 import (
 	"errors"
+	"strings"
 	"sync"
 
 	"github.com/InsighterInc/bxmp/common"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
 )
 
 /*
@@ -96,6 +100,21 @@ func (db *MemDatabase) NewBatch() Batch {
 	return &memBatch{db: db}
 }
 
+// NewIteratorWithPrefix returns an iterator over a copy of the entries whose
+// key starts with the given prefix, in key order.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	entries := memdb.New(comparer.DefaultComparer, 0)
+	for key, value := range db.db {
+		if strings.HasPrefix(key, string(prefix)) {
+			entries.Put([]byte(key), value)
+		}
+	}
+	return entries.NewIterator(nil)
+}
+
 type kv struct{ k, v []byte }
 
 type memBatch struct {
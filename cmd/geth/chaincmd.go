@@ -42,6 +42,11 @@ import (
 )
 
 var (
+	dumpPrivateFlag = cli.BoolFlag{
+		Name:  "private",
+		Usage: "Dump the private state of the node instead of the public state",
+	}
+
 	initCommand = cli.Command{
 		Action:    utils.MigrateFlags(initGenesis),
 		Name:      "init",
@@ -133,11 +138,15 @@ Remove blockchain and state databases`,
 			utils.DataDirFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
+			dumpPrivateFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The arguments are interpreted as block numbers or hashes.
-Use "bitmed dump 0" to dump the genesis block.`,
+Use "bitmed dump 0" to dump the genesis block.
+
+With --private, the private state of the node at the blocks is dumped, which
+holds the private contracts the node is party to.`,
 	}
 )
 
@@ -402,7 +411,11 @@ func dump(ctx *cli.Context) error {
 			fmt.Println("{}")
 			utils.Fatalf("block not found")
 		} else {
-			state, err := state.New(block.Root(), state.NewDatabase(chainDb))
+			root := block.Root()
+			if ctx.Bool(dumpPrivateFlag.Name) {
+				root = core.GetPrivateStateRoot(chainDb, root)
+			}
+			state, err := state.New(root, state.NewDatabase(chainDb))
 			if err != nil {
 				utils.Fatalf("could not create new state: %v", err)
 			}
@@ -29,6 +29,7 @@ import (
 	cli "gopkg.in/urfave/cli.v1"
 
 	"github.com/InsighterInc/bxmp/cmd/utils"
+	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/contracts/release"
 	"github.com/InsighterInc/bxmp/bxm"
 	"github.com/InsighterInc/bxmp/node"
@@ -174,6 +175,15 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Take the permissioned nodes from a contract if requested.
+	if ctx.GlobalIsSet(utils.PermissionContractFlag.Name) {
+		address := ctx.GlobalString(utils.PermissionContractFlag.Name)
+		if !common.IsHexAddress(address) {
+			utils.Fatalf("Invalid permission contract address: %s", address)
+		}
+		utils.RegisterPermissionService(stack, common.HexToAddress(address))
+	}
+
 	// Add the BitMED Stats daemon if requested.
 	if cfg.Bxmstats.URL != "" {
 		utils.RegisterBxmStatsService(stack, cfg.Bxmstats.URL)
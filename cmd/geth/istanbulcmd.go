@@ -0,0 +1,131 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of BXMP.
+//
+// BXMP is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BXMP is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BXMP. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/InsighterInc/bxmp/cmd/utils"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/core/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	istanbulVanityFlag = cli.StringFlag{
+		Name:  "vanity",
+		Usage: "Hex encoded vanity data leading the extra-data, at most 32 bytes",
+	}
+
+	istanbulCommand = cli.Command{
+		Name:     "istanbul",
+		Usage:    "Istanbul BFT tooling",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Tools to set up an Istanbul BFT network.`,
+		Subcommands: []cli.Command{
+			{
+				Name:  "extra",
+				Usage: "Encode or decode the extra-data of Istanbul blocks",
+				Subcommands: []cli.Command{
+					{
+						Name:      "encode",
+						Usage:     "Build the extra-data of an Istanbul genesis block",
+						Action:    utils.MigrateFlags(istanbulExtraEncode),
+						ArgsUsage: "<validator> [<validator>...]",
+						Flags: []cli.Flag{
+							istanbulVanityFlag,
+						},
+						Description: `
+    geth istanbul extra encode [--vanity 0x...] <validator> [<validator>...]
+
+Prints the hex encoded extra-data listing the given validator addresses, to be
+used as the extraData of an Istanbul genesis block.`,
+					},
+					{
+						Name:      "decode",
+						Usage:     "Print the fields of Istanbul extra-data",
+						Action:    utils.MigrateFlags(istanbulExtraDecode),
+						ArgsUsage: "<extraData>",
+						Description: `
+    geth istanbul extra decode <extraData>
+
+Prints the vanity, validators, proposer seal and committed seals held in the
+hex encoded extra-data of an Istanbul block.`,
+					},
+				},
+			},
+		},
+	}
+)
+
+// istanbulExtraEncode prints the genesis extra-data for the given validators.
+func istanbulExtraEncode(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("At least one validator address is required")
+	}
+	var vanity []byte
+	if ctx.IsSet(istanbulVanityFlag.Name) {
+		var err error
+		if vanity, err = hexutil.Decode(ctx.String(istanbulVanityFlag.Name)); err != nil {
+			utils.Fatalf("Invalid vanity: %v", err)
+		}
+		if len(vanity) > types.IstanbulExtraVanity {
+			utils.Fatalf("Vanity too long: %d bytes, at most %d allowed", len(vanity), types.IstanbulExtraVanity)
+		}
+	}
+	validators := make([]common.Address, len(ctx.Args()))
+	for i, arg := range ctx.Args() {
+		if !common.IsHexAddress(arg) {
+			utils.Fatalf("Invalid validator address: %s", arg)
+		}
+		validators[i] = common.HexToAddress(arg)
+	}
+	extra, err := types.EncodeIstanbulExtra(vanity, validators)
+	if err != nil {
+		utils.Fatalf("Failed to encode extra-data: %v", err)
+	}
+	fmt.Println(hexutil.Encode(extra))
+	return nil
+}
+
+// istanbulExtraDecode prints the fields of the given extra-data.
+func istanbulExtraDecode(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	extra, err := hexutil.Decode(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Invalid extra-data: %v", err)
+	}
+	istanbulExtra, err := types.ExtractIstanbulExtra(&types.Header{Extra: extra})
+	if err != nil {
+		utils.Fatalf("Failed to decode extra-data: %v", err)
+	}
+	fmt.Printf("Vanity: %s\n", hexutil.Encode(extra[:types.IstanbulExtraVanity]))
+	fmt.Println("Validators:")
+	for _, validator := range istanbulExtra.Validators {
+		fmt.Printf("  %s\n", validator.Hex())
+	}
+	fmt.Printf("Seal: %s\n", hexutil.Encode(istanbulExtra.Seal))
+	fmt.Println("Committed seals:")
+	for _, seal := range istanbulExtra.CommittedSeal {
+		fmt.Printf("  %s\n", hexutil.Encode(seal))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of BXMP.
+//
+// BXMP is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BXMP is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BXMP. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+)
+
+const testIstanbulExtra = "0x76616e6974790000000000000000000000000000000000000000000000000000f858f8549444add0ec310f115a0e603b2d7db9f067778eaf8a94294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212946beaaed781d2d2ab6350f5c4566a2c6eaac407a6948be76812f765c24641ec63dc2852b378aba2b44080c0"
+
+func TestIstanbulExtraEncode(t *testing.T) {
+	geth := runGeth(t, "istanbul", "extra", "encode", "--vanity", "0x76616e697479",
+		"0x44add0ec310f115a0e603b2d7db9f067778eaf8a",
+		"0x294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212",
+		"0x6beaaed781d2d2ab6350f5c4566a2c6eaac407a6",
+		"0x8be76812f765c24641ec63dc2852b378aba2b440")
+	defer geth.ExpectExit()
+	geth.Expect(testIstanbulExtra + "\n")
+}
+
+func TestIstanbulExtraDecode(t *testing.T) {
+	geth := runGeth(t, "istanbul", "extra", "decode", testIstanbulExtra)
+	defer geth.ExpectExit()
+	geth.Expect(`
+Vanity: 0x76616e6974790000000000000000000000000000000000000000000000000000
+Validators:
+  0x44aDd0eC310F115A0E603b2D7dB9F067778eaf8a
+  0x294fc7e8F22B3bCDcf955dd7FF3BA2Ed833f8212
+  0x6beAaEd781d2D2ab6350F5c4566a2c6EaAc407A6
+  0x8be76812F765C24641ec63DC2852B378ABA2B440
+Seal: 0x
+Committed seals:
+`)
+}
@@ -77,6 +77,9 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAccountRateFlag,
+		utils.TxPoolAccountGasQuotaFlag,
+		utils.TxPoolQuotaWindowFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
@@ -108,6 +111,7 @@ var (
 		utils.RPCCORSDomainFlag,
 		utils.BxmStatsURLFlag,
 		utils.MetricsEnabledFlag,
+		utils.MetricsAddrFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
@@ -115,6 +119,10 @@ var (
 		utils.ExtraDataFlag,
 		configFileFlag,
 		utils.EnableNodePermissionFlag,
+		utils.PermissionContractFlag,
+		utils.PrivateTxManagerFlag,
+		utils.PrivateConfigFlag,
+		utils.AccountPolicyFlag,
 		utils.RaftModeFlag,
 		utils.RaftBlockTimeFlag,
 		utils.RaftJoinExistingFlag,
@@ -135,6 +143,11 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.RPCTLSCertFlag,
+		utils.RPCTLSKeyFlag,
+		utils.RPCTLSClientCAFlag,
+		utils.RPCJWTSecretFlag,
+		utils.RPCPermissionsFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -159,6 +172,8 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		// See istanbulcmd.go:
+		istanbulCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -191,6 +206,7 @@ func init() {
 			return err
 		}
 		// Start system runtime metrics collection
+		utils.SetupMetrics(ctx)
 		go metrics.CollectProcessMetrics(3 * time.Second)
 
 		utils.SetupNetwork(ctx)
@@ -106,6 +106,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolAccountRateFlag,
+			utils.TxPoolAccountGasQuotaFlag,
+			utils.TxPoolQuotaWindowFlag,
 		},
 	},
 	{
@@ -119,6 +122,10 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "QUORUM",
 		Flags: []cli.Flag{
 			utils.EnableNodePermissionFlag,
+			utils.PermissionContractFlag,
+			utils.PrivateTxManagerFlag,
+			utils.PrivateConfigFlag,
+			utils.AccountPolicyFlag,
 		},
 	},
 	{
@@ -149,6 +156,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.RPCTLSCertFlag,
+			utils.RPCTLSKeyFlag,
+			utils.RPCTLSClientCAFlag,
+			utils.RPCJWTSecretFlag,
+			utils.RPCPermissionsFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -202,6 +214,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "LOGGING AND DEBUGGING",
 		Flags: append([]cli.Flag{
 			utils.MetricsEnabledFlag,
+			utils.MetricsAddrFlag,
 			utils.FakePoWFlag,
 			utils.NoCompactionFlag,
 		}, debug.Flags...),
@@ -26,7 +26,9 @@ import (
 	"time"
 
 	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
 	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/params"
 )
@@ -52,6 +54,8 @@ func (w *wizard) makeGenesis() {
 	fmt.Println("Which consensus engine to use? (default = clique)")
 	fmt.Println(" 1. Ethash - proof-of-work")
 	fmt.Println(" 2. Clique - proof-of-authority")
+	fmt.Println(" 3. Istanbul - byzantine fault tolerant proof-of-authority")
+	fmt.Println(" 4. Raft - crash fault tolerant ordering, chosen with --raft when starting the nodes")
 
 	choice := w.read()
 	switch {
@@ -98,6 +102,44 @@ func (w *wizard) makeGenesis() {
 			copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
 		}
 
+	case choice == "3":
+		// In the case of istanbul, configure the consensus parameters
+		genesis.Difficulty = big.NewInt(1)
+		genesis.Mixhash = types.IstanbulDigest
+		genesis.Config.Istanbul = &params.IstanbulConfig{
+			Epoch:          30000,
+			ProposerPolicy: uint64(istanbul.RoundRobin),
+		}
+		fmt.Println()
+		fmt.Println("How many seconds should blocks take at least? (default = 1)")
+		genesis.Config.Istanbul.BlockPeriod = uint64(w.readDefaultInt(1))
+
+		// We also need the initial list of validators
+		fmt.Println()
+		fmt.Println("Which accounts are the validators? (mandatory at least one)")
+
+		var validators []common.Address
+		for {
+			if address := w.readAddress(); address != nil {
+				validators = append(validators, *address)
+				continue
+			}
+			if len(validators) > 0 {
+				break
+			}
+		}
+		extra, err := types.EncodeIstanbulExtra(nil, validators)
+		if err != nil {
+			log.Crit("Failed to encode Istanbul extra-data", "err", err)
+		}
+		genesis.ExtraData = extra
+
+	case choice == "4":
+		// Raft needs no consensus parameters, only transactions without gas price
+		genesis.Difficulty = big.NewInt(0)
+		genesis.Config.IsBitmed = true
+		genesis.ExtraData = make([]byte, 32)
+
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
 	}
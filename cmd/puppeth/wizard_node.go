@@ -140,9 +140,14 @@ func (w *wizard) deployNode(boot bool) {
 		fmt.Printf("What gas limit should empty blocks target (MGas)? (default = %0.3f)\n", infos.gasTarget)
 		infos.gasTarget = w.readDefaultFloat(infos.gasTarget)
 
-		fmt.Println()
-		fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", infos.gasPrice)
-		infos.gasPrice = w.readDefaultFloat(infos.gasPrice)
+		// Networks of BitMED nodes only accept transactions without gas price
+		if w.conf.genesis.Config.IsBitmed {
+			infos.gasPrice = 0
+		} else {
+			fmt.Println()
+			fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", infos.gasPrice)
+			infos.gasPrice = w.readDefaultFloat(infos.gasPrice)
+		}
 	}
 	// Try to deploy the full node on the host
 	if out, err := deployNode(client, w.network, w.conf.bootFull, w.conf.bootLight, infos); err != nil {
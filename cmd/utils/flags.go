@@ -34,6 +34,7 @@ import (
 	"github.com/InsighterInc/bxmp/consensus"
 	"github.com/InsighterInc/bxmp/consensus/clique"
 	"github.com/InsighterInc/bxmp/consensus/ethash"
+	"github.com/InsighterInc/bxmp/contracts/permission"
 	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/state"
 	"github.com/InsighterInc/bxmp/core/vm"
@@ -53,6 +54,7 @@ import (
 	"github.com/InsighterInc/bxmp/p2p/nat"
 	"github.com/InsighterInc/bxmp/p2p/netutil"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	whisper "github.com/InsighterInc/bxmp/whisper/whisperv5"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -263,6 +265,21 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: bxm.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolAccountRateFlag = cli.Uint64Flag{
+		Name:  "txpool.accountrate",
+		Usage: "Maximum number of transactions per second admitted from a non-local account (0 = unlimited)",
+		Value: bxm.DefaultConfig.TxPool.AccountTxRate,
+	}
+	TxPoolAccountGasQuotaFlag = cli.Uint64Flag{
+		Name:  "txpool.accountgasquota",
+		Usage: "Maximum gas limits of the transactions of a non-local account per quota window (0 = unlimited)",
+		Value: bxm.DefaultConfig.TxPool.AccountGasQuota,
+	}
+	TxPoolQuotaWindowFlag = cli.Uint64Flag{
+		Name:  "txpool.quotawindow",
+		Usage: "Number of blocks the account gas quota applies to",
+		Value: bxm.DefaultConfig.TxPool.QuotaWindow,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -328,6 +345,10 @@ var (
 		Name:  metrics.MetricsEnabledFlag,
 		Usage: "Enable metrics collection and reporting",
 	}
+	MetricsAddrFlag = cli.StringFlag{
+		Name:  metrics.MetricsAddrFlag,
+		Usage: "Serve the metrics for Prometheus on the given listening address, implies --" + metrics.MetricsEnabledFlag,
+	}
 	FakePoWFlag = cli.BoolFlag{
 		Name:  "fakepow",
 		Usage: "Disables proof-of-work verification",
@@ -393,6 +414,26 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	RPCTLSCertFlag = cli.StringFlag{
+		Name:  "rpctlscert",
+		Usage: "PEM certificate to serve the HTTP-RPC and WS-RPC interfaces over TLS with",
+	}
+	RPCTLSKeyFlag = cli.StringFlag{
+		Name:  "rpctlskey",
+		Usage: "PEM private key of the HTTP-RPC and WS-RPC TLS certificate",
+	}
+	RPCTLSClientCAFlag = cli.StringFlag{
+		Name:  "rpctlsclientca",
+		Usage: "PEM certificates of the authorities signing the required HTTP-RPC and WS-RPC client certificates",
+	}
+	RPCJWTSecretFlag = cli.StringFlag{
+		Name:  "rpcjwtsecret",
+		Usage: "File holding the hex secret of the HS256 bearer tokens required by the HTTP-RPC and WS-RPC interfaces",
+	}
+	RPCPermissionsFlag = cli.StringFlag{
+		Name:  "rpcpermissions",
+		Usage: "JSON file listing the methods the HTTP-RPC and WS-RPC clients may call",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -522,21 +563,37 @@ var (
 		Name:  "permissioned",
 		Usage: "If enabled, the node will allow only a defined list of nodes to connect",
 	}
+	PermissionContractFlag = cli.StringFlag{
+		Name:  "permissioncontract",
+		Usage: "Address of a NodePermissions contract to take the list of permissioned nodes from (permissioned-nodes.json is used until it is deployed)",
+	}
+	PrivateTxManagerFlag = cli.StringFlag{
+		Name:  "privatetxmanager",
+		Usage: "Private transaction manager backend (" + strings.Join(private.Backends(), ", ") + ")",
+	}
+	PrivateConfigFlag = cli.StringFlag{
+		Name:  "privateconfig",
+		Usage: "Configuration file of the private transaction manager (defaults to $PRIVATE_CONFIG)",
+	}
+	AccountPolicyFlag = cli.StringFlag{
+		Name:  "accountpolicy",
+		Usage: "JSON file assigning the accounts the transactions they may send (readonly, transact or deploy)",
+	}
 
 	// Istanbul settings
 	IstanbulRequestTimeoutFlag = cli.Uint64Flag{
 		Name:  "istanbul.requesttimeout",
-		Usage: "Timeout for each Istanbul round in milliseconds",
+		Usage: "Timeout for each Istanbul round in milliseconds, unless set by the chain config",
 		Value: bxm.DefaultConfig.Istanbul.RequestTimeout,
 	}
 	IstanbulBlockPeriodFlag = cli.Uint64Flag{
 		Name:  "istanbul.blockperiod",
-		Usage: "Default minimum difference between two consecutive block's timestamps in seconds",
+		Usage: "Default minimum difference between two consecutive block's timestamps in seconds, unless set by the chain config",
 		Value: bxm.DefaultConfig.Istanbul.BlockPeriod,
 	}
 	IstanbulBlockPauseTimeFlag = cli.Uint64Flag{
 		Name:  "istanbul.blockpausetime",
-		Usage: "Pause time when zero tx in previous block, values should be larger than istanbul.blockperiod",
+		Usage: "Pause time when zero tx in previous block, values should be larger than istanbul.blockperiod, unless set by the chain config",
 		Value: bxm.DefaultConfig.Istanbul.BlockPauseTime,
 	}
 )
@@ -731,6 +788,26 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCSecurity applies the TLS and client authentication settings of the
+// HTTP and WebSocket RPC interfaces from the set command line flags.
+func setRPCSecurity(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCTLSCertFlag.Name) {
+		cfg.RPCTLSCert = ctx.GlobalString(RPCTLSCertFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSKeyFlag.Name) {
+		cfg.RPCTLSKey = ctx.GlobalString(RPCTLSKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSClientCAFlag.Name) {
+		cfg.RPCTLSClientCA = ctx.GlobalString(RPCTLSClientCAFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCJWTSecretFlag.Name) {
+		cfg.RPCJWTSecret = ctx.GlobalString(RPCJWTSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCPermissionsFlag.Name) {
+		cfg.RPCPermissions = ctx.GlobalString(RPCPermissionsFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -869,6 +946,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setRPCSecurity(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	cfg.EnableNodePermission = ctx.GlobalBool(EnableNodePermissionFlag.Name)
@@ -937,6 +1015,15 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolAccountRateFlag.Name) {
+		cfg.AccountTxRate = ctx.GlobalUint64(TxPoolAccountRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolAccountGasQuotaFlag.Name) {
+		cfg.AccountGasQuota = ctx.GlobalUint64(TxPoolAccountGasQuotaFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolQuotaWindowFlag.Name) {
+		cfg.QuotaWindow = ctx.GlobalUint64(TxPoolQuotaWindowFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *bxm.Config) {
@@ -972,6 +1059,23 @@ func setIstanbul(ctx *cli.Context, cfg *bxm.Config) {
 	}
 }
 
+func setPrivateTxManager(ctx *cli.Context, cfg *bxm.Config) {
+	// PRIVATE_CONFIG predates the flags and always meant constellation
+	if path := os.Getenv("PRIVATE_CONFIG"); path != "" {
+		cfg.PrivateTxManager = private.DefaultBackend
+		cfg.PrivateConfig = path
+	}
+	if ctx.GlobalIsSet(PrivateConfigFlag.Name) {
+		cfg.PrivateConfig = ctx.GlobalString(PrivateConfigFlag.Name)
+		if cfg.PrivateTxManager == "" {
+			cfg.PrivateTxManager = private.DefaultBackend
+		}
+	}
+	if ctx.GlobalIsSet(PrivateTxManagerFlag.Name) {
+		cfg.PrivateTxManager = ctx.GlobalString(PrivateTxManagerFlag.Name)
+	}
+}
+
 func checkExclusive(ctx *cli.Context, flags ...cli.Flag) {
 	set := make([]string, 0, 1)
 	for _, flag := range flags {
@@ -1006,7 +1110,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *bxm.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
 	setIstanbul(ctx, cfg)
+	setPrivateTxManager(ctx, cfg)
 
+	if ctx.GlobalIsSet(AccountPolicyFlag.Name) {
+		cfg.AccountPolicy = ctx.GlobalString(AccountPolicyFlag.Name)
+	}
 	switch {
 	case ctx.GlobalIsSet(SyncModeFlag.Name):
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1130,6 +1238,29 @@ func RegisterBxmStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterPermissionService configures the service enforcing the node allow-list
+// of the NodePermissions contract at the given address and adds it to the node.
+func RegisterPermissionService(stack *node.Node, address common.Address) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		return permission.NewPermissionService(ctx, address)
+	}); err != nil {
+		Fatalf("Failed to register the node permission service: %v", err)
+	}
+}
+
+// SetupMetrics enables metrics collection and starts serving the metrics for
+// Prometheus if requested.
+func SetupMetrics(ctx *cli.Context) {
+	if ctx.GlobalBool(MetricsEnabledFlag.Name) {
+		metrics.Enable()
+	}
+	if addr := ctx.GlobalString(MetricsAddrFlag.Name); addr != "" {
+		if err := metrics.StartPrometheus(addr); err != nil {
+			Fatalf("Failed to start metrics server: %v", err)
+		}
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
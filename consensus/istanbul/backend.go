@@ -57,4 +57,8 @@ type Backend interface {
 
 	// LastProposal retrieves latest committed proposal and the address of proposer
 	LastProposal() (Proposal, common.Address)
+
+	// ReportMisbehaviour records the evidence of a validator sending conflicting
+	// messages
+	ReportMisbehaviour(misbehaviour *Misbehaviour)
 }
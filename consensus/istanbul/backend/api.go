@@ -17,8 +17,13 @@
 package backend
 
 import (
+	"fmt"
+
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/consensus"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	istanbulCore "github.com/InsighterInc/bxmp/consensus/istanbul/core"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/validator"
 	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/rpc"
 )
@@ -87,6 +92,55 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 	return snap.validators(), nil
 }
 
+// GetSignerStatus reports, for the given number of most recent blocks, how many
+// blocks each validator proposed and how many committed seals it contributed.
+// Validators of the latest block that did not sign any of them are included, so
+// that validators which have gone quiet stand out.
+func (api *API) GetSignerStatus(blocks *uint64) (*SignerActivity, error) {
+	count := uint64(defaultSignerStatusBlocks)
+	if blocks != nil {
+		count = *blocks
+	}
+	if count > maxSignerStatusBlocks {
+		return nil, fmt.Errorf("too many blocks requested, at most %d allowed", maxSignerStatusBlocks)
+	}
+	head := api.chain.CurrentHeader()
+	snap, err := api.istanbul.snapshot(api.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Collect the headers, leaving out the unsigned genesis
+	if number := head.Number.Uint64(); count > number {
+		count = number
+	}
+	headers := make([]*types.Header, count)
+	header := head
+	for i := len(headers) - 1; i >= 0; i-- {
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		headers[i] = header
+		header = api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return signerActivity(headers, snap.validators())
+}
+
+// GetMisbehaviour retrieves the recorded evidence of validators sending
+// conflicting messages, optionally only that of the given validator.
+func (api *API) GetMisbehaviour(validator *common.Address) ([]*istanbul.Misbehaviour, error) {
+	misbehaviours, err := loadMisbehaviours(api.istanbul.db)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*istanbul.Misbehaviour, 0, len(misbehaviours))
+	for _, m := range misbehaviours {
+		if validator == nil || m.Validator == *validator {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
 // Candidates returns the current candidates the node tries to uphold and vote on.
 func (api *API) Candidates() map[common.Address]bool {
 	api.istanbul.candidatesLock.RLock()
@@ -100,19 +154,74 @@ func (api *API) Candidates() map[common.Address]bool {
 }
 
 // Propose injects a new authorization candidate that the validator will attempt to
-// push through.
-func (api *API) Propose(address common.Address, auth bool) {
+// push through. Validators can't be voted on while a governance contract lists
+// them.
+func (api *API) Propose(address common.Address, auth bool) error {
+	if api.istanbul.config.Governance != (common.Address{}) {
+		return errGovernedValidators
+	}
 	api.istanbul.candidatesLock.Lock()
 	defer api.istanbul.candidatesLock.Unlock()
 
 	api.istanbul.candidates[address] = auth
+	return nil
 }
 
 // Discard drops a currently running candidate, stopping the validator from casting
-// further votes (either for or against).
+// further votes (either for or against) on its authorization or weight.
 func (api *API) Discard(address common.Address) {
 	api.istanbul.candidatesLock.Lock()
 	defer api.istanbul.candidatesLock.Unlock()
 
 	delete(api.istanbul.candidates, address)
+	delete(api.istanbul.weightCandidates, address)
+}
+
+// WeightCandidates returns the proposer selection weights the node tries to
+// uphold and vote on.
+func (api *API) WeightCandidates() map[common.Address]uint64 {
+	api.istanbul.candidatesLock.RLock()
+	defer api.istanbul.candidatesLock.RUnlock()
+
+	proposals := make(map[common.Address]uint64)
+	for address, weight := range api.istanbul.weightCandidates {
+		proposals[address] = weight
+	}
+	return proposals
+}
+
+// ProposeWeight injects a new proposer selection weight for a validator that the
+// validator will attempt to push through. Weights are only voted on under the
+// weighted proposer policy.
+func (api *API) ProposeWeight(address common.Address, weight uint64) error {
+	if api.istanbul.config.ProposerPolicy != istanbul.Weighted {
+		return errWeightedPolicyRequired
+	}
+	if weight == 0 || weight > validator.MaxWeight {
+		return errInvalidWeight
+	}
+	api.istanbul.candidatesLock.Lock()
+	defer api.istanbul.candidatesLock.Unlock()
+
+	api.istanbul.weightCandidates[address] = weight
+	return nil
+}
+
+// Status returns a summary of the consensus state of the current view: the
+// sequence and round, the state of the core, the proposer, the proposal being
+// agreed on or locked, the number of messages collected and the backlog size.
+func (api *API) Status() (*istanbulCore.Status, error) {
+	if !api.istanbul.isCoreStarted() {
+		return nil, istanbul.ErrStoppedEngine
+	}
+	return api.istanbul.core.Status()
+}
+
+// RoundState returns the PREPARE, COMMIT and ROUND-CHANGE messages collected
+// from each validator in the current view, along with its backlog size.
+func (api *API) RoundState() (*istanbulCore.RoundState, error) {
+	if !api.istanbul.isCoreStarted() {
+		return nil, istanbul.ErrStoppedEngine
+	}
+	return api.istanbul.core.RoundState()
 }
@@ -0,0 +1,169 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	istanbulCore "github.com/InsighterInc/bxmp/consensus/istanbul/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
+)
+
+func TestGetSignerStatus(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	block := makeBlock(chain, engine, chain.Genesis())
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	api := &API{chain: chain, istanbul: engine}
+
+	none := uint64(0)
+	tests := []struct {
+		blocks *uint64
+		want   *SignerActivity
+	}{
+		{
+			blocks: nil,
+			want: &SignerActivity{From: 1, To: 1, Signers: []*SignerStatus{
+				{Address: engine.address, Validator: true, Proposed: 1, Sealed: 1, LastProposed: 1, LastSealed: 1},
+			}},
+		},
+		{
+			// Quiet validators are reported even without any blocks
+			blocks: &none,
+			want: &SignerActivity{Signers: []*SignerStatus{
+				{Address: engine.address, Validator: true},
+			}},
+		},
+	}
+	for i, test := range tests {
+		have, err := api.GetSignerStatus(test.blocks)
+		if err != nil {
+			t.Errorf("test %d: failed to retrieve signer status: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("test %d: signer status mismatch: have %+v, want %+v", i, have, test.want)
+		}
+	}
+	tooMany := uint64(maxSignerStatusBlocks + 1)
+	if _, err := api.GetSignerStatus(&tooMany); err == nil {
+		t.Errorf("expected error for too many blocks")
+	}
+}
+
+// newSignedHeader creates a header proposed by the first key and sealed by all.
+func newSignedHeader(number int64, keys ...*ecdsa.PrivateKey) *types.Header {
+	header := &types.Header{Number: big.NewInt(number), MixDigest: types.IstanbulDigest}
+	header.Extra, _ = prepareExtra(header, nil)
+
+	sig, _ := crypto.Sign(crypto.Keccak256(sigHash(header).Bytes()), keys[0])
+	writeSeal(header, sig)
+
+	seals := make([][]byte, len(keys))
+	for i, key := range keys {
+		seals[i], _ = crypto.Sign(crypto.Keccak256(istanbulCore.PrepareCommittedSeal(header.Hash())), key)
+	}
+	writeCommittedSeals(header, seals)
+	return header
+}
+
+func TestSignerActivity(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	addrs := make([]common.Address, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	// The last validator has gone quiet, the third stopped sealing after a block
+	headers := []*types.Header{
+		newSignedHeader(5, keys[0], keys[1], keys[2]),
+		newSignedHeader(6, keys[1], keys[0]),
+		newSignedHeader(7, keys[0], keys[1]),
+	}
+	activity, err := signerActivity(headers, addrs)
+	if err != nil {
+		t.Fatalf("failed to scan headers: %v", err)
+	}
+	want := map[common.Address]SignerStatus{
+		addrs[0]: {Address: addrs[0], Validator: true, Proposed: 2, Sealed: 3, LastProposed: 7, LastSealed: 7},
+		addrs[1]: {Address: addrs[1], Validator: true, Proposed: 1, Sealed: 3, LastProposed: 6, LastSealed: 7},
+		addrs[2]: {Address: addrs[2], Validator: true, Sealed: 1, LastSealed: 5},
+		addrs[3]: {Address: addrs[3], Validator: true},
+	}
+	if activity.From != 5 || activity.To != 7 {
+		t.Errorf("range mismatch: have %d-%d, want 5-7", activity.From, activity.To)
+	}
+	if len(activity.Signers) != len(want) {
+		t.Fatalf("signer count mismatch: have %d, want %d", len(activity.Signers), len(want))
+	}
+	for _, have := range activity.Signers {
+		if have == nil || *have != want[have.Address] {
+			t.Errorf("signer %x status mismatch: have %+v, want %+v", have.Address, have, want[have.Address])
+		}
+	}
+}
+
+func TestGetMisbehaviour(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	api := &API{chain: chain, istanbul: engine}
+
+	sub := engine.EventMux().Subscribe(istanbul.MisbehaviourEvent{})
+	defer sub.Unsubscribe()
+
+	a, b := common.Address{1}, common.Address{2}
+	reports := []*istanbul.Misbehaviour{
+		{Validator: a, Code: 1, Sequence: 5, Evidence: []hexutil.Bytes{{1}, {2}}},
+		{Validator: b, Code: 2, Sequence: 5, Evidence: []hexutil.Bytes{{3}, {4}}},
+		{Validator: a, Code: 1, Sequence: 5, Evidence: []hexutil.Bytes{{1}, {5}}}, // Same view, not recorded again
+		{Validator: a, Code: 1, Sequence: 6, Evidence: []hexutil.Bytes{{6}, {7}}},
+	}
+	for _, m := range reports {
+		engine.ReportMisbehaviour(m)
+	}
+	for i := 0; i < 3; i++ {
+		ev := <-sub.Chan()
+		if _, ok := ev.Data.(istanbul.MisbehaviourEvent); !ok {
+			t.Errorf("event type mismatch: have %T", ev.Data)
+		}
+	}
+	tests := []struct {
+		validator *common.Address
+		want      []*istanbul.Misbehaviour
+	}{
+		{nil, []*istanbul.Misbehaviour{reports[0], reports[1], reports[3]}},
+		{&a, []*istanbul.Misbehaviour{reports[0], reports[3]}},
+		{&b, []*istanbul.Misbehaviour{reports[1]}},
+		{&common.Address{3}, []*istanbul.Misbehaviour{}},
+	}
+	for i, test := range tests {
+		have, err := api.GetMisbehaviour(test.validator)
+		if err != nil {
+			t.Fatalf("test %d: failed to get misbehaviour: %v", i, err)
+		}
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("test %d: misbehaviour mismatch: have %v, want %v", i, have, test.want)
+		}
+	}
+}
@@ -51,6 +51,7 @@ func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db bxmdb.Databas
 		commitCh:         make(chan *types.Block, 1),
 		recents:          recents,
 		candidates:       make(map[common.Address]bool),
+		weightCandidates: make(map[common.Address]uint64),
 		coreStarted:      false,
 		recentMessages:   recentMessages,
 		knownMessages:    knownMessages,
@@ -81,6 +82,8 @@ type backend struct {
 
 	// Current list of candidates we are pushing
 	candidates map[common.Address]bool
+	// Current proposer selection weights we are pushing
+	weightCandidates map[common.Address]uint64
 	// Protects the signer fields
 	candidatesLock sync.RWMutex
 	// Snapshots for recent block to speed up reorgs
@@ -91,6 +94,8 @@ type backend struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	misbehaviourMu sync.Mutex // Serializes the updates of the recorded misbehaviours
 }
 
 // Address implements istanbul.Backend.Address
@@ -80,6 +80,17 @@ var (
 	errInvalidCommittedSeals = errors.New("invalid committed seals")
 	// errEmptyCommittedSeals is returned if the field of committed seals is zero.
 	errEmptyCommittedSeals = errors.New("zero committed seals")
+	// errWeightedPolicyRequired is returned when a proposer selection weight is
+	// proposed while the weighted proposer policy is not in use.
+	errWeightedPolicyRequired = errors.New("proposer selection weights require the weighted proposer policy")
+	// errInvalidWeight is returned when a proposed weight is out of range.
+	errInvalidWeight = errors.New("invalid proposer selection weight")
+	// errGovernedValidators is returned when a validator is proposed while the
+	// validators are read from a governance contract.
+	errGovernedValidators = errors.New("validators are governed by contract")
+	// errNoSnapshotValidators is returned when a stored snapshot has no
+	// validators, as the snapshots written before they were encoded did.
+	errNoSnapshotValidators = errors.New("snapshot without validators")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -127,9 +138,12 @@ func (sb *backend) verifyHeader(chain consensus.ChainReader, header *types.Heade
 		return errInvalidExtraDataFormat
 	}
 
-	// Ensure that the coinbase is valid
+	// Ensure that the coinbase is valid, any other nonce being a weight vote
+	// under the weighted proposer policy
 	if header.Nonce != (emptyNonce) && !bytes.Equal(header.Nonce[:], nonceAuthVote) && !bytes.Equal(header.Nonce[:], nonceDropVote) {
-		return errInvalidNonce
+		if sb.config.ProposerPolicy != istanbul.Weighted || header.Nonce.Uint64() > validator.MaxWeight {
+			return errInvalidNonce
+		}
 	}
 	// Ensure that the mix digest is zero as we don't have fork protection currently
 	if header.MixDigest != types.IstanbulDigest {
@@ -167,7 +181,7 @@ func (sb *backend) verifyCascadingFields(chain consensus.ChainReader, header *ty
 	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
 		return consensus.ErrUnknownAncestor
 	}
-	if parent.Time.Uint64()+sb.config.BlockPeriod > header.Time.Uint64() {
+	if parent.Time.Uint64()+sb.config.At(header.Number).BlockPeriod > header.Time.Uint64() {
 		return errInvalidTimestamp
 	}
 	// Verify validators in extraData. Validators in snapshot and extraData should be the same.
@@ -337,11 +351,23 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	// get valid candidate list
 	sb.candidatesLock.RLock()
 	var addresses []common.Address
-	var authorizes []bool
+	var nonces []types.BlockNonce
 	for address, authorize := range sb.candidates {
-		if snap.checkVote(address, authorize) {
+		if !snap.Governed && snap.checkVote(address, authorize) {
+			var nonce types.BlockNonce
+			if authorize {
+				copy(nonce[:], nonceAuthVote)
+			} else {
+				copy(nonce[:], nonceDropVote)
+			}
+			addresses = append(addresses, address)
+			nonces = append(nonces, nonce)
+		}
+	}
+	for address, weight := range sb.weightCandidates {
+		if snap.checkWeightVote(address, weight) {
 			addresses = append(addresses, address)
-			authorizes = append(authorizes, authorize)
+			nonces = append(nonces, types.EncodeNonce(weight))
 		}
 	}
 	sb.candidatesLock.RUnlock()
@@ -351,11 +377,7 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 		index := rand.Intn(len(addresses))
 		// add validator voting in coinbase
 		header.Coinbase = addresses[index]
-		if authorizes[index] {
-			copy(header.Nonce[:], nonceAuthVote)
-		} else {
-			copy(header.Nonce[:], nonceDropVote)
-		}
+		header.Nonce = nonces[index]
 	}
 
 	// add validators in snapshot to extraData's validators section
@@ -378,6 +400,11 @@ func (sb *backend) Finalize(chain consensus.ChainReader, header *types.Header, s
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = nilUncleHash
 
+	// List the validators of a governed validator set at checkpoints
+	if err := sb.governValidators(chain, header, state); err != nil {
+		return nil, err
+	}
+
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts), nil
 }
@@ -446,11 +473,12 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, stop <-
 // update timestamp and signature of the block based on its number of transactions
 func (sb *backend) updateBlock(parent *types.Header, block *types.Block) (*types.Block, error) {
 	// set block period based the number of tx
+	config := sb.config.At(block.Number())
 	var period uint64
 	if len(block.Transactions()) == 0 {
-		period = sb.config.BlockPauseTime
+		period = config.BlockPauseTime
 	} else {
-		period = sb.config.BlockPeriod
+		period = config.BlockPeriod
 	}
 
 	// set header timestamp
@@ -538,6 +566,14 @@ func (sb *backend) Stop() error {
 	return nil
 }
 
+// isCoreStarted reports whether the consensus core is running.
+func (sb *backend) isCoreStarted() bool {
+	sb.coreMu.Lock()
+	defer sb.coreMu.Unlock()
+
+	return sb.coreStarted
+}
+
 // snapshot retrieves the authorization snapshot at a given point in time.
 func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
 	// Search for a snapshot in memory or on disk for checkpoints
@@ -556,6 +592,7 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 			if s, err := loadSnapshot(sb.config.Epoch, sb.db, hash); err == nil {
 				log.Trace("Loaded voting snapshot form disk", "number", number, "hash", hash)
 				snap = s
+				snap.Governed = sb.config.Governance != (common.Address{})
 				break
 			}
 		}
@@ -570,6 +607,7 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 				return nil, err
 			}
 			snap = newSnapshot(sb.config.Epoch, 0, genesis.Hash(), validator.NewSet(istanbulExtra.Validators, sb.config.ProposerPolicy))
+			snap.Governed = sb.config.Governance != (common.Address{})
 			if err := snap.store(sb.db); err != nil {
 				return nil, err
 			}
@@ -0,0 +1,144 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/InsighterInc/bxmp/accounts/abi"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus"
+	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/state"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/core/vm"
+	"github.com/InsighterInc/bxmp/log"
+)
+
+// GovernanceABI is the interface a governance contract has to implement for the
+// validator set to be read from it: a constant getValidators method returning
+// the validator addresses.
+const GovernanceABI = `[{"constant":true,"inputs":[],"name":"getValidators","outputs":[{"name":"","type":"address[]"}],"payable":false,"type":"function"}]`
+
+// governanceCallGas is the gas available to the governance contract to list
+// the validators.
+const governanceCallGas = 10000000
+
+var governanceABI abi.ABI
+
+func init() {
+	var err error
+	if governanceABI, err = abi.JSON(strings.NewReader(GovernanceABI)); err != nil {
+		panic(err)
+	}
+}
+
+// chainContext provides the header chain and the engine to the EVM.
+type chainContext struct {
+	consensus.ChainReader
+	engine consensus.Engine
+}
+
+func (c chainContext) Engine() consensus.Engine { return c.engine }
+
+// governedValidators calls the governance contract on the given state and
+// returns the validators it lists, in ascending order and without duplicates.
+func (sb *backend) governedValidators(chain consensus.ChainReader, header *types.Header, statedb *state.StateDB) ([]common.Address, error) {
+	input, err := governanceABI.Pack("getValidators")
+	if err != nil {
+		return nil, err
+	}
+	// Call the contract on a copy, so that the state of the block is left as is
+	statedb = statedb.Copy()
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     core.GetHashFn(header, chainContext{chain, sb}),
+		Coinbase:    header.Coinbase,
+		GasLimit:    new(big.Int).Set(header.GasLimit),
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        new(big.Int).Set(header.Time),
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+		GasPrice:    new(big.Int),
+	}
+	evm := vm.NewEVM(context, statedb, statedb, chain.Config(), vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), sb.config.Governance, input, governanceCallGas)
+	if err != nil {
+		return nil, err
+	}
+	var listed []common.Address
+	if err := governanceABI.Unpack(&listed, "getValidators", ret); err != nil {
+		return nil, err
+	}
+	sort.Sort(addresses(listed))
+
+	validators := make([]common.Address, 0, len(listed))
+	for i, addr := range listed {
+		if i == 0 || addr != listed[i-1] {
+			validators = append(validators, addr)
+		}
+	}
+	return validators, nil
+}
+
+// governValidators lists the validators read from the governance contract in
+// the extra data of checkpoint blocks, from which the snapshot picks them up.
+// The header of a block being assembled gets them filled in, while a sealed
+// header has to list them already. If the contract fails to list any validator,
+// the current ones are kept.
+func (sb *backend) governValidators(chain consensus.ChainReader, header *types.Header, statedb *state.StateDB) error {
+	number := header.Number.Uint64()
+	if sb.config.Governance == (common.Address{}) || number == 0 || number%sb.config.Epoch != 0 {
+		return nil
+	}
+	validators, err := sb.governedValidators(chain, header, statedb)
+	if err != nil || len(validators) == 0 {
+		log.Warn("Failed to read validators from governance contract, keeping the current ones", "number", number, "contract", sb.config.Governance, "err", err)
+
+		snap, err := sb.snapshot(chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			return err
+		}
+		validators = snap.validators()
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+	if len(extra.CommittedSeal) == 0 {
+		header.Extra, err = prepareExtra(header, validators)
+		return err
+	}
+	if len(extra.Validators) != len(validators) {
+		return errInconsistentValidatorSet
+	}
+	for i, addr := range validators {
+		if extra.Validators[i] != addr {
+			return errInconsistentValidatorSet
+		}
+	}
+	return nil
+}
+
+type addresses []common.Address
+
+func (a addresses) Len() int           { return len(a) }
+func (a addresses) Less(i, j int) bool { return bytes.Compare(a[i][:], a[j][:]) < 0 }
+func (a addresses) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -0,0 +1,144 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/validator"
+	"github.com/InsighterInc/bxmp/core/types"
+)
+
+// governanceCode returns the runtime code of a contract answering any call with
+// the ABI encoded list of validators.
+func governanceCode(validators ...common.Address) []byte {
+	data := common.LeftPadBytes(big.NewInt(32).Bytes(), 32)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(validators))).Bytes(), 32)...)
+	for _, addr := range validators {
+		data = append(data, common.LeftPadBytes(addr[:], 32)...)
+	}
+	// PUSH2 len DUP1 PUSH1 12 PUSH1 0 CODECOPY PUSH1 0 RETURN, followed by the data
+	code := []byte{0x61, byte(len(data) >> 8), byte(len(data)), 0x80, 0x60, 12, 0x60, 0, 0x39, 0x60, 0, 0xf3}
+	return append(code, data...)
+}
+
+func TestGovernedValidators(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	config := *engine.config
+	config.Governance = common.StringToAddress("governance")
+	config.Epoch = 1
+	engine.config = &config
+
+	statedb, _, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve state: %v", err)
+	}
+	a, b, c := common.Address{1}, common.Address{2}, common.Address{3}
+	statedb.SetCode(config.Governance, governanceCode(c, a, b, a))
+
+	header := makeHeader(chain.Genesis(), engine.config)
+	if err := engine.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	validators, err := engine.governedValidators(chain, header, statedb)
+	if err != nil {
+		t.Fatalf("failed to call governance contract: %v", err)
+	}
+	if want := []common.Address{a, b, c}; !reflect.DeepEqual(validators, want) {
+		t.Errorf("validators mismatch: have %x, want %x", validators, want)
+	}
+
+	// The validators are filled into a block being assembled
+	if err := engine.governValidators(chain, header, statedb); err != nil {
+		t.Fatalf("failed to govern validators: %v", err)
+	}
+	extra, _ := types.ExtractIstanbulExtra(header)
+	if !reflect.DeepEqual(extra.Validators, validators) {
+		t.Errorf("extra validators mismatch: have %x, want %x", extra.Validators, validators)
+	}
+	// A sealed block has to list them already
+	writeCommittedSeals(header, [][]byte{make([]byte, types.IstanbulExtraSeal)})
+	if err := engine.governValidators(chain, header, statedb); err != nil {
+		t.Errorf("failed to verify governed validators: %v", err)
+	}
+	statedb.SetCode(config.Governance, governanceCode(a, b))
+	if err := engine.governValidators(chain, header, statedb); err != errInconsistentValidatorSet {
+		t.Errorf("error mismatch: have %v, want %v", err, errInconsistentValidatorSet)
+	}
+	// Without a usable contract the current validators are kept
+	statedb.SetCode(config.Governance, nil)
+	header = makeHeader(chain.Genesis(), engine.config)
+	engine.Prepare(chain, header)
+	if err := engine.governValidators(chain, header, statedb); err != nil {
+		t.Fatalf("failed to govern validators: %v", err)
+	}
+	extra, _ = types.ExtractIstanbulExtra(header)
+	if want := []common.Address{engine.address}; !reflect.DeepEqual(extra.Validators, want) {
+		t.Errorf("extra validators mismatch: have %x, want %x", extra.Validators, want)
+	}
+	// Validators can't be voted on
+	api := &API{chain: chain, istanbul: engine}
+	if err := api.Propose(a, true); err != errGovernedValidators {
+		t.Errorf("error mismatch: have %v, want %v", err, errGovernedValidators)
+	}
+}
+
+func TestGovernedSnapshot(t *testing.T) {
+	accounts := newTesterAccountPool()
+	validators := []common.Address{accounts.address("A"), accounts.address("B")}
+
+	// A votes in C, which is ignored, and the checkpoint hands over to B and C
+	headers := make([]*types.Header, 2)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number:     big.NewInt(int64(i) + 1),
+			Coinbase:   accounts.address("C"),
+			Difficulty: defaultDifficulty,
+			MixDigest:  types.IstanbulDigest,
+		}
+		copy(headers[i].Nonce[:], nonceAuthVote)
+	}
+	headers[0].Extra, _ = prepareExtra(headers[0], validators)
+	headers[1].Extra, _ = prepareExtra(headers[1], []common.Address{accounts.address("B"), accounts.address("C")})
+	for _, header := range headers {
+		accounts.sign(header, "A")
+	}
+	snap := newSnapshot(2, 0, common.Hash{}, validator.NewSet(validators, istanbul.RoundRobin))
+	snap.Governed = true
+
+	snap, err := snap.apply(headers[:1])
+	if err != nil {
+		t.Fatalf("failed to apply headers: %v", err)
+	}
+	if len(snap.Votes) != 0 || len(snap.Tally) != 0 || snap.ValSet.Size() != 2 {
+		t.Errorf("vote should be ignored: votes %v, tally %v, validators %d", snap.Votes, snap.Tally, snap.ValSet.Size())
+	}
+	snap, err = snap.apply(headers[1:])
+	if err != nil {
+		t.Fatalf("failed to apply headers: %v", err)
+	}
+	want := []common.Address{accounts.address("B"), accounts.address("C")}
+	sort.Sort(addresses(want))
+	if have := snap.validators(); !reflect.DeepEqual(have, want) {
+		t.Errorf("validators mismatch: have %x, want %x", have, want)
+	}
+}
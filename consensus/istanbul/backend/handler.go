@@ -94,6 +94,8 @@ func (sb *backend) SetBroadcaster(broadcaster consensus.Broadcaster) {
 }
 
 func (sb *backend) NewChainHead(block *types.Block) error {
+	updateSignerMetrics(block.Header(), sb.address)
+
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
 	if !sb.coreStarted {
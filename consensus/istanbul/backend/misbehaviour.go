@@ -0,0 +1,87 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/json"
+
+	"github.com/InsighterInc/bxmp/bxmdb"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+)
+
+const (
+	dbKeyMisbehaviour = "istanbul-misbehaviour"
+	maxMisbehaviours  = 1024 // Number of most recent misbehaviours kept in the database
+)
+
+// loadMisbehaviours retrieves the recorded misbehaviours from the database,
+// oldest first.
+func loadMisbehaviours(db bxmdb.Database) ([]*istanbul.Misbehaviour, error) {
+	if ok, err := db.Has([]byte(dbKeyMisbehaviour)); err != nil || !ok {
+		return nil, err
+	}
+	blob, err := db.Get([]byte(dbKeyMisbehaviour))
+	if err != nil {
+		return nil, err
+	}
+	var misbehaviours []*istanbul.Misbehaviour
+	if err := json.Unmarshal(blob, &misbehaviours); err != nil {
+		return nil, err
+	}
+	return misbehaviours, nil
+}
+
+// storeMisbehaviours writes the misbehaviours into the database.
+func storeMisbehaviours(db bxmdb.Database, misbehaviours []*istanbul.Misbehaviour) error {
+	blob, err := json.Marshal(misbehaviours)
+	if err != nil {
+		return err
+	}
+	return db.Put([]byte(dbKeyMisbehaviour), blob)
+}
+
+// ReportMisbehaviour implements istanbul.Backend.ReportMisbehaviour, persisting
+// the evidence and posting a MisbehaviourEvent. A validator is only recorded
+// once per kind of message and view.
+func (sb *backend) ReportMisbehaviour(misbehaviour *istanbul.Misbehaviour) {
+	sb.misbehaviourMu.Lock()
+	defer sb.misbehaviourMu.Unlock()
+
+	misbehaviours, err := loadMisbehaviours(sb.db)
+	if err != nil {
+		sb.logger.Error("Failed to load misbehaviours", "err", err)
+		return
+	}
+	for _, m := range misbehaviours {
+		if m.Validator == misbehaviour.Validator && m.Code == misbehaviour.Code && m.Sequence == misbehaviour.Sequence && m.Round == misbehaviour.Round {
+			return
+		}
+	}
+	misbehaviours = append(misbehaviours, misbehaviour)
+	if len(misbehaviours) > maxMisbehaviours {
+		misbehaviours = misbehaviours[len(misbehaviours)-maxMisbehaviours:]
+	}
+	if err := storeMisbehaviours(sb.db, misbehaviours); err != nil {
+		sb.logger.Error("Failed to store misbehaviour", "err", err)
+		return
+	}
+	sb.logger.Warn("Recorded validator misbehaviour", "validator", misbehaviour.Validator, "code", misbehaviour.Code, "sequence", misbehaviour.Sequence, "round", misbehaviour.Round)
+
+	go sb.istanbulEventMux.Post(istanbul.MisbehaviourEvent{
+		Misbehaviour: misbehaviour,
+	})
+}
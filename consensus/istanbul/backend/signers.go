@@ -0,0 +1,157 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	istanbulCore "github.com/InsighterInc/bxmp/consensus/istanbul/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/metrics"
+)
+
+const (
+	defaultSignerStatusBlocks = 64   // Number of blocks scanned by GetSignerStatus by default
+	maxSignerStatusBlocks     = 8192 // Maximum number of blocks scanned by GetSignerStatus
+)
+
+// SignerStatus is the signing activity of a validator over a range of blocks.
+type SignerStatus struct {
+	Address      common.Address `json:"address"`
+	Validator    bool           `json:"validator"`    // Whether it is in the validator set at the last block
+	Proposed     uint64         `json:"proposed"`     // Number of blocks proposed
+	Sealed       uint64         `json:"sealed"`       // Number of committed seals contributed
+	LastProposed uint64         `json:"lastProposed"` // Number of the last block proposed, 0 if none
+	LastSealed   uint64         `json:"lastSealed"`   // Number of the last block sealed, 0 if none
+}
+
+// SignerActivity reports the signing activity of the validators over a range
+// of blocks.
+type SignerActivity struct {
+	From    uint64          `json:"from"`
+	To      uint64          `json:"to"`
+	Signers []*SignerStatus `json:"signers"`
+}
+
+type signersByAddress []*SignerStatus
+
+func (s signersByAddress) Len() int { return len(s) }
+func (s signersByAddress) Less(i, j int) bool {
+	return bytes.Compare(s[i].Address[:], s[j].Address[:]) < 0
+}
+func (s signersByAddress) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// signers returns the proposer of the block and the validators that contributed
+// a committed seal to it.
+func signers(header *types.Header) (common.Address, []common.Address, error) {
+	proposer, err := ecrecover(header)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+
+	committers := make([]common.Address, 0, len(extra.CommittedSeal))
+	for _, seal := range extra.CommittedSeal {
+		addr, err := istanbul.GetSignatureAddress(proposalSeal, seal)
+		if err != nil {
+			return common.Address{}, nil, errInvalidSignature
+		}
+		committers = append(committers, addr)
+	}
+	return proposer, committers, nil
+}
+
+// signerActivity scans the given headers, which must be in ascending order, and
+// reports the activity of the signers and of the given validators.
+func signerActivity(headers []*types.Header, validators []common.Address) (*SignerActivity, error) {
+	activity := &SignerActivity{}
+	if len(headers) > 0 {
+		activity.From = headers[0].Number.Uint64()
+		activity.To = headers[len(headers)-1].Number.Uint64()
+	}
+	statuses := make(map[common.Address]*SignerStatus)
+	status := func(addr common.Address) *SignerStatus {
+		if statuses[addr] == nil {
+			statuses[addr] = &SignerStatus{Address: addr}
+		}
+		return statuses[addr]
+	}
+	for _, addr := range validators {
+		status(addr).Validator = true
+	}
+	for _, header := range headers {
+		number := header.Number.Uint64()
+
+		proposer, committers, err := signers(header)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", number, err)
+		}
+		s := status(proposer)
+		s.Proposed++
+		s.LastProposed = number
+
+		for _, addr := range committers {
+			s := status(addr)
+			s.Sealed++
+			s.LastSealed = number
+		}
+	}
+	for _, s := range statuses {
+		activity.Signers = append(activity.Signers, s)
+	}
+	sort.Sort(signersByAddress(activity.Signers))
+	return activity, nil
+}
+
+// Signing metrics are kept in aggregate and for the local validator only, so
+// that their number doesn't grow with the validators ever seen. The activity
+// of every validator is available through GetSignerStatus.
+var (
+	sealsGauge          = metrics.NewGauge("consensus/istanbul/signers/seals")
+	selfProposedCounter = metrics.NewCounter("consensus/istanbul/signers/self/proposed")
+	selfSealedCounter   = metrics.NewCounter("consensus/istanbul/signers/self/sealed")
+)
+
+// updateSignerMetrics records the number of committed seals of the block and
+// counts it towards the proposed and sealed blocks of the local validator.
+func updateSignerMetrics(header *types.Header, self common.Address) {
+	if !metrics.Enabled || header.Number.Sign() == 0 {
+		return
+	}
+	proposer, committers, err := signers(header)
+	if err != nil {
+		return
+	}
+	sealsGauge.Update(int64(len(committers)))
+
+	if proposer == self {
+		selfProposedCounter.Inc(1)
+	}
+	for _, addr := range committers {
+		if addr == self {
+			selfSealedCounter.Inc(1)
+		}
+	}
+}
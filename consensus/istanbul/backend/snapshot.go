@@ -22,6 +22,7 @@ import (
 
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/validator"
 	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/bxmdb"
 )
@@ -33,28 +34,33 @@ const (
 // Vote represents a single vote that an authorized validator made to modify the
 // list of authorizations.
 type Vote struct {
-	Validator common.Address `json:"validator"` // Authorized validator that cast this vote
-	Block     uint64         `json:"block"`     // Block number the vote was cast in (expire old votes)
-	Address   common.Address `json:"address"`   // Account being voted on to change its authorization
-	Authorize bool           `json:"authorize"` // Whether to authorize or deauthorize the voted account
+	Validator common.Address `json:"validator"`        // Authorized validator that cast this vote
+	Block     uint64         `json:"block"`            // Block number the vote was cast in (expire old votes)
+	Address   common.Address `json:"address"`          // Account being voted on to change its authorization
+	Authorize bool           `json:"authorize"`        // Whether to authorize or deauthorize the voted account
+	Weight    uint64         `json:"weight,omitempty"` // Proposer selection weight voted for, 0 for authorization votes
 }
 
 // Tally is a simple vote tally to keep the current score of votes. Votes that
 // go against the proposal aren't counted since it's equivalent to not voting.
 type Tally struct {
-	Authorize bool `json:"authorize"` // Whether the vote it about authorizing or kicking someone
-	Votes     int  `json:"votes"`     // Number of votes until now wanting to pass the proposal
+	Authorize bool   `json:"authorize"`        // Whether the vote it about authorizing or kicking someone
+	Votes     int    `json:"votes"`            // Number of votes until now wanting to pass the proposal
+	Weight    uint64 `json:"weight,omitempty"` // Proposer selection weight the votes are about, if any
 }
 
 // Snapshot is the state of the authorization voting at a given point in time.
 type Snapshot struct {
-	Epoch uint64 // The number of blocks after which to checkpoint and reset the pending votes
+	Epoch    uint64 // The number of blocks after which to checkpoint and reset the pending votes
+	Governed bool   // Whether the validators are taken from checkpoint headers instead of voted on
 
 	Number uint64                   `json:"number"`     // Block number where the snapshot was created
 	Hash   common.Hash              `json:"hash"`       // Block hash where the snapshot was created
 	Votes  []*Vote                  `json:"votes"`      // List of votes cast in chronological order
 	Tally  map[common.Address]Tally `json:"tally"`      // Current vote tally to avoid recalculating
 	ValSet istanbul.ValidatorSet    `json:"validators"` // Set of authorized validators at this moment
+
+	WeightTally map[common.Address]Tally `json:"weightTally"` // Current weight vote tally to avoid recalculating
 }
 
 // newSnapshot create a new snapshot with the specified startup parameters. This
@@ -67,6 +73,8 @@ func newSnapshot(epoch uint64, number uint64, hash common.Hash, valSet istanbul.
 		Hash:   hash,
 		ValSet: valSet,
 		Tally:  make(map[common.Address]Tally),
+
+		WeightTally: make(map[common.Address]Tally),
 	}
 	return snap
 }
@@ -98,17 +106,23 @@ func (s *Snapshot) store(db bxmdb.Database) error {
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	cpy := &Snapshot{
-		Epoch:  s.Epoch,
-		Number: s.Number,
-		Hash:   s.Hash,
-		ValSet: s.ValSet.Copy(),
-		Votes:  make([]*Vote, len(s.Votes)),
-		Tally:  make(map[common.Address]Tally),
+		Epoch:    s.Epoch,
+		Governed: s.Governed,
+		Number:   s.Number,
+		Hash:     s.Hash,
+		ValSet:   s.ValSet.Copy(),
+		Votes:    make([]*Vote, len(s.Votes)),
+		Tally:    make(map[common.Address]Tally),
+
+		WeightTally: make(map[common.Address]Tally),
 	}
 
 	for address, tally := range s.Tally {
 		cpy.Tally[address] = tally
 	}
+	for address, tally := range s.WeightTally {
+		cpy.WeightTally[address] = tally
+	}
 	copy(cpy.Votes, s.Votes)
 
 	return cpy
@@ -157,6 +171,66 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 	return true
 }
 
+// checkWeightVote return whether it's a valid vote on the proposer selection
+// weight of a validator.
+func (s *Snapshot) checkWeightVote(address common.Address, weight uint64) bool {
+	if s.ValSet.Policy() != istanbul.Weighted || weight == 0 || weight > validator.MaxWeight {
+		return false
+	}
+	_, v := s.ValSet.GetByAddress(address)
+	return v != nil && s.ValSet.Weight(address) != weight
+}
+
+// castWeight adds a new weight vote into the weight tally. Only one weight can
+// be voted on for a validator at a time, votes for other weights are ignored
+// until the running vote passes or is abandoned.
+func (s *Snapshot) castWeight(address common.Address, weight uint64) bool {
+	// Ensure the vote is meaningful
+	if !s.checkWeightVote(address, weight) {
+		return false
+	}
+	// Cast the vote into an existing or new tally
+	if old, ok := s.WeightTally[address]; ok {
+		if old.Weight != weight {
+			return false
+		}
+		old.Votes++
+		s.WeightTally[address] = old
+	} else {
+		s.WeightTally[address] = Tally{Votes: 1, Weight: weight}
+	}
+	return true
+}
+
+// uncastWeight removes a previously cast weight vote from the weight tally.
+func (s *Snapshot) uncastWeight(address common.Address, weight uint64) bool {
+	// If there's no tally, it's a dangling vote, just drop
+	tally, ok := s.WeightTally[address]
+	if !ok {
+		return false
+	}
+	// Ensure we only revert counted votes
+	if tally.Weight != weight {
+		return false
+	}
+	// Otherwise revert the vote
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.WeightTally[address] = tally
+	} else {
+		delete(s.WeightTally, address)
+	}
+	return true
+}
+
+// uncastVote removes a previously cast vote of any kind from its tally.
+func (s *Snapshot) uncastVote(vote *Vote) bool {
+	if vote.Weight != 0 {
+		return s.uncastWeight(vote.Address, vote.Weight)
+	}
+	return s.uncast(vote.Address, vote.Authorize)
+}
+
 // apply creates a new authorization snapshot by applying the given headers to
 // the original one.
 func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
@@ -182,6 +256,7 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 		if number%s.Epoch == 0 {
 			snap.Votes = nil
 			snap.Tally = make(map[common.Address]Tally)
+			snap.WeightTally = make(map[common.Address]Tally)
 		}
 		// Resolve the authorization key and check against validators
 		validator, err := ecrecover(header)
@@ -191,12 +266,27 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 		if _, v := snap.ValSet.GetByAddress(validator); v == nil {
 			return nil, errUnauthorized
 		}
+		// Move the weighted proposer selection past the block's proposer
+		if snap.ValSet.Policy() == istanbul.Weighted {
+			snap.ValSet.UpdatePriorities(validator)
+		}
+		// Take over the validators listed by the governance contract
+		if snap.Governed && number%s.Epoch == 0 {
+			extra, err := types.ExtractIstanbulExtra(header)
+			if err != nil {
+				return nil, err
+			}
+			if len(extra.Validators) == 0 {
+				return nil, errInconsistentValidatorSet
+			}
+			snap.setValidators(extra.Validators)
+		}
 
 		// Header authorized, discard any previous votes from the validator
 		for i, vote := range snap.Votes {
 			if vote.Validator == validator && vote.Address == header.Coinbase {
 				// Uncast the vote from the cached tally
-				snap.uncast(vote.Address, vote.Authorize)
+				snap.uncastVote(vote)
 
 				// Uncast the vote from the chronological list
 				snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
@@ -204,15 +294,48 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			}
 		}
 		// Tally up the new vote from the validator
-		var authorize bool
+		var (
+			authorize bool
+			weight    uint64
+		)
 		switch {
 		case bytes.Compare(header.Nonce[:], nonceAuthVote) == 0:
 			authorize = true
 		case bytes.Compare(header.Nonce[:], nonceDropVote) == 0:
 			authorize = false
+		case snap.ValSet.Policy() == istanbul.Weighted:
+			weight = header.Nonce.Uint64()
 		default:
 			return nil, errInvalidVote
 		}
+		// Validators are not voted on while a governance contract lists them
+		if weight == 0 && snap.Governed {
+			continue
+		}
+		if weight != 0 {
+			if snap.castWeight(header.Coinbase, weight) {
+				snap.Votes = append(snap.Votes, &Vote{
+					Validator: validator,
+					Block:     number,
+					Address:   header.Coinbase,
+					Weight:    weight,
+				})
+			}
+			// If the vote passed, update the weight of the validator
+			if tally := snap.WeightTally[header.Coinbase]; tally.Votes > snap.ValSet.Size()/2 {
+				snap.ValSet.SetWeight(header.Coinbase, tally.Weight)
+
+				// Discard any previous weight votes around the just changed validator
+				for i := 0; i < len(snap.Votes); i++ {
+					if snap.Votes[i].Address == header.Coinbase && snap.Votes[i].Weight != 0 {
+						snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+						i--
+					}
+				}
+				delete(snap.WeightTally, header.Coinbase)
+			}
+			continue
+		}
 		if snap.cast(header.Coinbase, authorize) {
 			snap.Votes = append(snap.Votes, &Vote{
 				Validator: validator,
@@ -232,7 +355,7 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				for i := 0; i < len(snap.Votes); i++ {
 					if snap.Votes[i].Validator == header.Coinbase {
 						// Uncast the vote from the cached tally
-						snap.uncast(snap.Votes[i].Address, snap.Votes[i].Authorize)
+						snap.uncastVote(snap.Votes[i])
 
 						// Uncast the vote from the chronological list
 						snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
@@ -249,6 +372,7 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				}
 			}
 			delete(snap.Tally, header.Coinbase)
+			delete(snap.WeightTally, header.Coinbase)
 		}
 	}
 	snap.Number += uint64(len(headers))
@@ -257,6 +381,21 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 	return snap, nil
 }
 
+// setValidators replaces the validator set with the given validators, keeping
+// the weights and priorities of those remaining.
+func (s *Snapshot) setValidators(validators []common.Address) {
+	listed := make(map[common.Address]bool)
+	for _, addr := range validators {
+		listed[addr] = true
+		s.ValSet.AddValidator(addr)
+	}
+	for _, addr := range s.validators() {
+		if !listed[addr] {
+			s.ValSet.RemoveValidator(addr)
+		}
+	}
+}
+
 // validators retrieves the list of authorized validators in ascending order.
 func (s *Snapshot) validators() []common.Address {
 	validators := make([]common.Address, 0, s.ValSet.Size())
@@ -272,3 +411,85 @@ func (s *Snapshot) validators() []common.Address {
 	}
 	return validators
 }
+
+type snapshotJSON struct {
+	Epoch  uint64                   `json:"epoch"`
+	Number uint64                   `json:"number"`
+	Hash   common.Hash              `json:"hash"`
+	Votes  []*Vote                  `json:"votes"`
+	Tally  map[common.Address]Tally `json:"tally"`
+
+	// for validator set
+	Validators []common.Address          `json:"validators"`
+	Policy     istanbul.ProposerPolicy   `json:"policy"`
+	Weights    map[common.Address]uint64 `json:"weights,omitempty"`    // Validators with a weight other than 1
+	Priorities map[common.Address]int64  `json:"priorities,omitempty"` // Validators with a non-zero priority
+
+	WeightTally map[common.Address]Tally `json:"weightTally,omitempty"`
+}
+
+func (s *Snapshot) toJSONStruct() *snapshotJSON {
+	j := &snapshotJSON{
+		Epoch:       s.Epoch,
+		Number:      s.Number,
+		Hash:        s.Hash,
+		Votes:       s.Votes,
+		Tally:       s.Tally,
+		Validators:  s.validators(),
+		Policy:      s.ValSet.Policy(),
+		WeightTally: s.WeightTally,
+	}
+	for _, addr := range j.Validators {
+		if weight := s.ValSet.Weight(addr); weight != 1 {
+			if j.Weights == nil {
+				j.Weights = make(map[common.Address]uint64)
+			}
+			j.Weights[addr] = weight
+		}
+		if priority := s.ValSet.Priority(addr); priority != 0 {
+			if j.Priorities == nil {
+				j.Priorities = make(map[common.Address]int64)
+			}
+			j.Priorities[addr] = priority
+		}
+	}
+	return j
+}
+
+// UnmarshalJSON unmarshals the snapshot, rebuilding its validator set from
+// the encoded addresses and proposer policy. Snapshots without validators are
+// refused, so that the ones stored in the older format are recomputed from the
+// headers instead.
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	var j snapshotJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if len(j.Validators) == 0 {
+		return errNoSnapshotValidators
+	}
+
+	s.Epoch = j.Epoch
+	s.Number = j.Number
+	s.Hash = j.Hash
+	s.Votes = j.Votes
+	s.Tally = j.Tally
+	s.ValSet = validator.NewSet(j.Validators, j.Policy)
+	for addr, weight := range j.Weights {
+		s.ValSet.SetWeight(addr, weight)
+	}
+	for addr, priority := range j.Priorities {
+		s.ValSet.SetPriority(addr, priority)
+	}
+	s.WeightTally = j.WeightTally
+	if s.WeightTally == nil {
+		s.WeightTally = make(map[common.Address]Tally)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the snapshot with its validator set flattened into the
+// list of validator addresses, as the set itself is an interface.
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toJSONStruct())
+}
@@ -20,10 +20,12 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/consensus/istanbul"
+	"github.com/InsighterInc/bxmp/consensus/istanbul/validator"
 	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/core/vm"
@@ -400,3 +402,158 @@ func TestVoting(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveAndLoad(t *testing.T) {
+	snap := &Snapshot{
+		Epoch:  5,
+		Number: 10,
+		Hash:   common.HexToHash("1234567890"),
+		Votes: []*Vote{
+			{
+				Validator: common.StringToAddress("1234567891"),
+				Block:     15,
+				Address:   common.StringToAddress("1234567892"),
+				Authorize: false,
+			},
+		},
+		Tally: map[common.Address]Tally{
+			common.StringToAddress("1234567893"): {
+				Authorize: false,
+				Votes:     20,
+			},
+		},
+		ValSet: validator.NewSet([]common.Address{
+			common.StringToAddress("1234567894"),
+			common.StringToAddress("1234567895"),
+		}, istanbul.Sticky),
+	}
+	db, _ := bxmdb.NewMemDatabase()
+	if err := snap.store(db); err != nil {
+		t.Fatalf("failed to store snapshot: %v", err)
+	}
+	snap1, err := loadSnapshot(snap.Epoch, db, snap.Hash)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	if snap.Epoch != snap1.Epoch || snap.Number != snap1.Number || snap.Hash != snap1.Hash {
+		t.Errorf("header mismatch: have %d/%d/%x, want %d/%d/%x", snap1.Epoch, snap1.Number, snap1.Hash, snap.Epoch, snap.Number, snap.Hash)
+	}
+	if !reflect.DeepEqual(snap.Votes, snap1.Votes) {
+		t.Errorf("votes mismatch: have %v, want %v", snap1.Votes, snap.Votes)
+	}
+	if !reflect.DeepEqual(snap.Tally, snap1.Tally) {
+		t.Errorf("tally mismatch: have %v, want %v", snap1.Tally, snap.Tally)
+	}
+	if !reflect.DeepEqual(snap.validators(), snap1.validators()) {
+		t.Errorf("validators mismatch: have %v, want %v", snap1.validators(), snap.validators())
+	}
+	if snap1.ValSet.Policy() != istanbul.Sticky {
+		t.Errorf("policy mismatch: have %v, want %v", snap1.ValSet.Policy(), istanbul.Sticky)
+	}
+}
+
+// Tests that the snapshots stored before the validators were encoded are
+// recomputed rather than loaded with an empty validator set.
+func TestLoadLegacySnapshot(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	defer engine.Stop()
+	genesis := chain.Genesis()
+
+	legacy := []string{
+		`{"Epoch":30000,"number":0,"hash":"` + genesis.Hash().Hex() + `","votes":[],"tally":{},"validators":{}}`,
+		`{"Epoch":30000,"number":0,"hash":"` + genesis.Hash().Hex() + `","votes":[],"tally":{},"ValSet":{}}`,
+	}
+	for _, blob := range legacy {
+		if err := engine.db.Put(append([]byte(dbKeySnapshotPrefix), genesis.Hash().Bytes()...), []byte(blob)); err != nil {
+			t.Fatalf("failed to store legacy snapshot: %v", err)
+		}
+		if _, err := loadSnapshot(30000, engine.db, genesis.Hash()); err == nil {
+			t.Errorf("legacy snapshot %s loaded", blob)
+		}
+		engine.recents.Purge()
+		snap, err := engine.snapshot(chain, 0, genesis.Hash(), nil)
+		if err != nil {
+			t.Fatalf("failed to recompute snapshot: %v", err)
+		}
+		if want := []common.Address{engine.Address()}; !reflect.DeepEqual(snap.validators(), want) {
+			t.Errorf("validators mismatch: have %v, want %v", snap.validators(), want)
+		}
+	}
+}
+
+// Tests that proposer selection weights are voted on like validators are.
+func TestWeightVoting(t *testing.T) {
+	accounts := newTesterAccountPool()
+	validators := []common.Address{accounts.address("A"), accounts.address("B"), accounts.address("C")}
+
+	votes := []struct {
+		validator string
+		voted     string
+		weight    uint64
+	}{
+		{validator: "A", voted: "B", weight: 3},
+		{validator: "C", voted: "B", weight: 5}, // ignored while another weight is being voted on
+		{validator: "B", voted: "B", weight: 3}, // passes
+		{validator: "A", voted: "C", weight: 2},
+	}
+	headers := make([]*types.Header, len(votes))
+	for i, vote := range votes {
+		headers[i] = &types.Header{
+			Number:     big.NewInt(int64(i) + 1),
+			Coinbase:   accounts.address(vote.voted),
+			Nonce:      types.EncodeNonce(vote.weight),
+			Difficulty: defaultDifficulty,
+			MixDigest:  types.IstanbulDigest,
+		}
+		headers[i].Extra, _ = prepareExtra(headers[i], validators)
+		accounts.sign(headers[i], vote.validator)
+	}
+	// Weight votes are rejected under other policies
+	snap := newSnapshot(30000, 0, common.Hash{}, validator.NewSet(validators, istanbul.RoundRobin))
+	if _, err := snap.apply(headers); err != errInvalidVote {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidVote)
+	}
+	snap = newSnapshot(30000, 0, common.Hash{}, validator.NewSet(validators, istanbul.Weighted))
+	snap, err := snap.apply(headers)
+	if err != nil {
+		t.Fatalf("failed to apply headers: %v", err)
+	}
+	if weight := snap.ValSet.Weight(accounts.address("B")); weight != 3 {
+		t.Errorf("weight mismatch: have %d, want 3", weight)
+	}
+	if weight := snap.ValSet.Weight(accounts.address("C")); weight != 1 {
+		t.Errorf("weight mismatch: have %d, want 1", weight)
+	}
+	wantTally := map[common.Address]Tally{accounts.address("C"): {Votes: 1, Weight: 2}}
+	if !reflect.DeepEqual(snap.WeightTally, wantTally) {
+		t.Errorf("weight tally mismatch: have %v, want %v", snap.WeightTally, wantTally)
+	}
+	if len(snap.Votes) != 1 || snap.Votes[0].Address != accounts.address("C") {
+		t.Errorf("votes mismatch: have %v", snap.Votes)
+	}
+	// Every block moves the priorities along, keeping their sum at zero
+	var sum int64
+	for _, addr := range validators {
+		sum += snap.ValSet.Priority(addr)
+	}
+	if sum != 0 || snap.ValSet.Priority(accounts.address("A")) == 0 {
+		t.Errorf("priorities mismatch: A %d, B %d, C %d", snap.ValSet.Priority(validators[0]), snap.ValSet.Priority(validators[1]), snap.ValSet.Priority(validators[2]))
+	}
+	// Weights, priorities and the weight tally survive storing the snapshot
+	db, _ := bxmdb.NewMemDatabase()
+	if err := snap.store(db); err != nil {
+		t.Fatalf("failed to store snapshot: %v", err)
+	}
+	snap1, err := loadSnapshot(snap.Epoch, db, snap.Hash)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	for _, addr := range validators {
+		if snap1.ValSet.Weight(addr) != snap.ValSet.Weight(addr) || snap1.ValSet.Priority(addr) != snap.ValSet.Priority(addr) {
+			t.Errorf("weight or priority mismatch for %x", addr)
+		}
+	}
+	if !reflect.DeepEqual(snap1.WeightTally, snap.WeightTally) {
+		t.Errorf("weight tally mismatch: have %v, want %v", snap1.WeightTally, snap.WeightTally)
+	}
+}
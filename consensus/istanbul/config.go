@@ -16,11 +16,18 @@
 
 package istanbul
 
+import (
+	"math/big"
+
+	"github.com/InsighterInc/bxmp/common"
+)
+
 type ProposerPolicy uint64
 
 const (
 	RoundRobin ProposerPolicy = iota
 	Sticky
+	Weighted
 )
 
 type Config struct {
@@ -29,6 +36,17 @@ type Config struct {
 	BlockPauseTime uint64         `toml:",omitempty"` // Delay time if no tx in block, the value should be larger than BlockPeriod
 	ProposerPolicy ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
 	Epoch          uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
+	Governance     common.Address `toml:"-"`          // The contract the validators are read from at each checkpoint, taken from the chain config
+	Transitions    []Transition   `toml:"-"`          // Changes of the timing parameters at given blocks, taken from the chain config
+}
+
+// Transition changes the timing parameters from a block on, so that the whole
+// network switches at the same height. Zero values keep the previous settings.
+type Transition struct {
+	Block          *big.Int
+	RequestTimeout uint64
+	BlockPeriod    uint64
+	BlockPauseTime uint64
 }
 
 var DefaultConfig = &Config{
@@ -38,3 +56,33 @@ var DefaultConfig = &Config{
 	ProposerPolicy: RoundRobin,
 	Epoch:          30000,
 }
+
+// At returns the config with the timing parameters in effect at the given block
+// number. The transitions have to be in ascending block order.
+func (c *Config) At(number *big.Int) *Config {
+	if len(c.Transitions) == 0 {
+		return c
+	}
+	cfg := *c
+	for _, t := range c.Transitions {
+		if t.Block == nil {
+			continue
+		}
+		if t.Block.Cmp(number) > 0 {
+			break
+		}
+		if t.RequestTimeout != 0 {
+			cfg.RequestTimeout = t.RequestTimeout
+		}
+		if t.BlockPeriod != 0 {
+			cfg.BlockPeriod = t.BlockPeriod
+			if cfg.BlockPauseTime < cfg.BlockPeriod {
+				cfg.BlockPauseTime = cfg.BlockPeriod
+			}
+		}
+		if t.BlockPauseTime != 0 {
+			cfg.BlockPauseTime = t.BlockPauseTime
+		}
+	}
+	return &cfg
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestConfigAt(t *testing.T) {
+	config := &Config{
+		RequestTimeout: 10000,
+		BlockPeriod:    1,
+		BlockPauseTime: 2,
+		Transitions: []Transition{
+			{Block: big.NewInt(10), BlockPeriod: 5},
+			{Block: big.NewInt(20), RequestTimeout: 30000, BlockPauseTime: 10},
+			{Block: big.NewInt(30), BlockPeriod: 2},
+		},
+	}
+	tests := []struct {
+		number                       int64
+		timeout, period, pausePeriod uint64
+	}{
+		{0, 10000, 1, 2},
+		{9, 10000, 1, 2},
+		{10, 10000, 5, 5}, // The pause time is raised to the period
+		{19, 10000, 5, 5},
+		{20, 30000, 5, 10},
+		{30, 30000, 2, 10},
+		{1000, 30000, 2, 10},
+	}
+	for _, test := range tests {
+		have := config.At(big.NewInt(test.number))
+		if have.RequestTimeout != test.timeout || have.BlockPeriod != test.period || have.BlockPauseTime != test.pausePeriod {
+			t.Errorf("block %d: timing mismatch: have %d/%d/%d, want %d/%d/%d", test.number,
+				have.RequestTimeout, have.BlockPeriod, have.BlockPauseTime, test.timeout, test.period, test.pausePeriod)
+		}
+	}
+	if config.BlockPeriod != 1 {
+		t.Errorf("config modified: period %d", config.BlockPeriod)
+	}
+}
@@ -62,6 +62,10 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 		return err
 	}
 
+	// Catch the sender sending another COMMIT for this view before it is
+	// dropped as inconsistent with the proposal.
+	c.checkMisbehaviour(c.current.Commits, msg)
+
 	if err := c.verifyCommit(commit, src); err != nil {
 		return err
 	}
@@ -49,6 +49,8 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		roundMeter:         metrics.NewMeter("consensus/istanbul/core/round"),
 		sequenceMeter:      metrics.NewMeter("consensus/istanbul/core/sequence"),
 		consensusTimer:     metrics.NewTimer("consensus/istanbul/core/consensus"),
+		roundGauge:         metrics.NewGauge("consensus/istanbul/core/current/round"),
+		sequenceGauge:      metrics.NewGauge("consensus/istanbul/core/current/sequence"),
 	}
 	c.validateFn = c.checkValidatorSignature
 	return c
@@ -92,6 +94,14 @@ type core struct {
 	sequenceMeter goMetrics.Meter
 	// the timer to record consensus duration (from accepting a preprepare to final committed stage)
 	consensusTimer goMetrics.Timer
+	// the gauges to record the round and sequence currently in progress
+	roundGauge    goMetrics.Gauge
+	sequenceGauge goMetrics.Gauge
+
+	// quit is closed when the core stops, releasing the callers waiting for
+	// the event loop to inspect the consensus state
+	quit   chan struct{}
+	quitMu sync.Mutex
 }
 
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
@@ -255,6 +265,8 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 	} else {
 		c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil)
 	}
+	c.roundGauge.Update(view.Round.Int64())
+	c.sequenceGauge.Update(view.Sequence.Int64())
 }
 
 func (c *core) setState(state State) {
@@ -288,7 +300,7 @@ func (c *core) newRoundChangeTimer() {
 	c.stopTimer()
 
 	// set timeout based on the round number
-	t := uint64(math.Pow(2, float64(c.current.Round().Uint64()))) * c.config.RequestTimeout
+	t := uint64(math.Pow(2, float64(c.current.Round().Uint64()))) * c.config.At(c.current.Sequence()).RequestTimeout
 	timeout := time.Duration(t) * time.Millisecond
 	c.roundChangeTimer = time.AfterFunc(timeout, func() {
 		c.sendEvent(timeoutEvent{})
@@ -43,4 +43,7 @@ var (
 	errFailedDecodeCommit = errors.New("failed to decode COMMIT")
 	// errFailedDecodeMessageSet is returned when the message set is malformed.
 	errFailedDecodeMessageSet = errors.New("failed to decode message set")
+	// errInspectTimeout is returned when the event loop did not get around to
+	// report the consensus state in time, or is not running.
+	errInspectTimeout = errors.New("timeout inspecting consensus state")
 )
@@ -26,3 +26,10 @@ type backlogEvent struct {
 }
 
 type timeoutEvent struct{}
+
+// inspectEvent has the event loop run fn and send its return value on result,
+// which must be buffered.
+type inspectEvent struct {
+	fn     func() interface{}
+	result chan interface{}
+}
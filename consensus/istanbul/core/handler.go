@@ -39,6 +39,9 @@ func (c *core) Start(lastSequence *big.Int, lastProposer common.Address, lastPro
 	// Tests will handle events itself, so we have to make subscribeEvents()
 	// be able to call in test.
 	c.subscribeEvents()
+	c.quitMu.Lock()
+	c.quit = make(chan struct{})
+	c.quitMu.Unlock()
 	go c.handleEvents()
 
 	return nil
@@ -48,6 +51,13 @@ func (c *core) Start(lastSequence *big.Int, lastProposer common.Address, lastPro
 func (c *core) Stop() error {
 	c.stopTimer()
 	c.unsubscribeEvents()
+
+	c.quitMu.Lock()
+	if c.quit != nil {
+		close(c.quit)
+		c.quit = nil
+	}
+	c.quitMu.Unlock()
 	return nil
 }
 
@@ -61,6 +71,7 @@ func (c *core) subscribeEvents() {
 		istanbul.MessageEvent{},
 		// internal events
 		backlogEvent{},
+		inspectEvent{},
 	)
 	c.timeoutSub = c.backend.EventMux().Subscribe(
 		timeoutEvent{},
@@ -108,6 +119,8 @@ func (c *core) handleEvents() {
 					}
 					c.backend.Gossip(c.valSet, p)
 				}
+			case inspectEvent:
+				ev.result <- ev.fn()
 			}
 		case _, ok := <-c.timeoutSub.Chan():
 			if !ok {
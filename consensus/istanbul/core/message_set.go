@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"strings"
@@ -35,6 +36,7 @@ func newMessageSet(valSet istanbul.ValidatorSet) *messageSet {
 		},
 		messagesMu: new(sync.Mutex),
 		messages:   make(map[common.Hash]*message),
+		seen:       make(map[common.Address]*message),
 		valSet:     valSet,
 	}
 }
@@ -46,6 +48,7 @@ type messageSet struct {
 	valSet     istanbul.ValidatorSet
 	messagesMu *sync.Mutex
 	messages   map[common.Hash]*message
+	seen       map[common.Address]*message // First message seen from each validator
 }
 
 func (ms *messageSet) View() *istanbul.View {
@@ -74,6 +77,24 @@ func (ms *messageSet) Values() (result []*message) {
 	return result
 }
 
+// Conflict records msg as seen and returns the message its sender sent before
+// in the set for a different subject, if any. Every message of the set is for
+// the same view, so the two messages prove that the sender equivocated.
+func (ms *messageSet) Conflict(msg *message) *message {
+	ms.messagesMu.Lock()
+	defer ms.messagesMu.Unlock()
+
+	first, ok := ms.seen[msg.Address]
+	if !ok {
+		ms.seen[msg.Address] = msg
+		return nil
+	}
+	if first.Code != msg.Code || bytes.Equal(first.Msg, msg.Msg) {
+		return nil
+	}
+	return first
+}
+
 func (ms *messageSet) Size() int {
 	ms.messagesMu.Lock()
 	defer ms.messagesMu.Unlock()
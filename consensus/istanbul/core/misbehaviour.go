@@ -0,0 +1,52 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+)
+
+// checkMisbehaviour reports the sender of the message to the backend if it sent
+// a different message of the same kind for the current view before.
+func (c *core) checkMisbehaviour(set *messageSet, msg *message) {
+	first := set.Conflict(msg)
+	if first == nil {
+		return
+	}
+	logger := c.logger.New("from", msg.Address, "state", c.state)
+
+	evidence := make([]hexutil.Bytes, 0, 2)
+	for _, m := range []*message{first, msg} {
+		payload, err := m.Payload()
+		if err != nil {
+			logger.Error("Failed to encode conflicting message", "err", err)
+			return
+		}
+		evidence = append(evidence, payload)
+	}
+	view := c.currentView()
+	logger.Warn("Validator sent conflicting messages", "code", msg.Code, "sequence", view.Sequence, "round", view.Round)
+
+	c.backend.ReportMisbehaviour(&istanbul.Misbehaviour{
+		Validator: msg.Address,
+		Code:      msg.Code,
+		Sequence:  view.Sequence.Uint64(),
+		Round:     view.Round.Uint64(),
+		Evidence:  evidence,
+	})
+}
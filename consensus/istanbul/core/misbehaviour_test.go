@@ -0,0 +1,94 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+)
+
+func TestConflictingMessages(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	v0 := sys.backends[0]
+	r0 := v0.engine.(*core)
+	r0.valSet = v0.peers
+	r0.current = newTestRoundState(&istanbul.View{
+		Round:    big.NewInt(0),
+		Sequence: big.NewInt(1),
+	}, r0.valSet)
+	r0.state = StatePreprepared
+
+	src := r0.valSet.GetByIndex(1)
+	send := func(code uint64, digest common.Hash) error {
+		sub := r0.current.Subject()
+		sub.Digest = digest
+		encoded, _ := Encode(sub)
+		msg := &message{
+			Code:    code,
+			Msg:     encoded,
+			Address: src.Address(),
+		}
+		if code == msgPrepare {
+			return r0.handlePrepare(msg, src)
+		}
+		return r0.handleCommit(msg, src)
+	}
+	digest := r0.current.Subject().Digest
+	other := common.StringToHash("other")
+
+	// Repeated and consistent messages are fine
+	for _, code := range []uint64{msgPrepare, msgPrepare, msgCommit} {
+		if err := send(code, digest); err != nil {
+			t.Fatalf("failed to handle message %d: %v", code, err)
+		}
+	}
+	if len(v0.misbehaviours) != 0 {
+		t.Fatalf("unexpected misbehaviours: %v", v0.misbehaviours)
+	}
+	// Conflicting ones are reported along with the first message
+	for i, code := range []uint64{msgPrepare, msgCommit} {
+		if err := send(code, other); err != errInconsistentSubject {
+			t.Errorf("error mismatch: have %v, want %v", err, errInconsistentSubject)
+		}
+		if len(v0.misbehaviours) != i+1 {
+			t.Fatalf("misbehaviour count mismatch: have %d, want %d", len(v0.misbehaviours), i+1)
+		}
+		m := v0.misbehaviours[i]
+		if m.Validator != src.Address() || m.Code != code || m.Sequence != 1 || m.Round != 0 {
+			t.Errorf("misbehaviour mismatch: have %+v", m)
+		}
+		if len(m.Evidence) != 2 {
+			t.Fatalf("evidence count mismatch: have %d, want 2", len(m.Evidence))
+		}
+		for j, want := range []common.Hash{digest, other} {
+			msg := new(message)
+			if err := msg.FromPayload(m.Evidence[j], nil); err != nil {
+				t.Fatalf("failed to decode evidence: %v", err)
+			}
+			var sub *istanbul.Subject
+			if err := msg.Decode(&sub); err != nil {
+				t.Fatalf("failed to decode subject: %v", err)
+			}
+			if msg.Code != code || sub.Digest != want {
+				t.Errorf("evidence %d mismatch: have code %d digest %x, want code %d digest %x", j, msg.Code, sub.Digest, code, want)
+			}
+		}
+	}
+}
@@ -49,6 +49,10 @@ func (c *core) handlePrepare(msg *message, src istanbul.Validator) error {
 		return err
 	}
 
+	// Catch the sender sending another PREPARE for this view before it is
+	// dropped as inconsistent with the proposal.
+	c.checkMisbehaviour(c.current.Prepares, msg)
+
 	// If it is locked, it can only process on the locked block.
 	// Passing verifyPrepare and checkMessage implies it is processing on the locked block since it was verified in the Preprepared state.
 	if err := c.verifyPrepare(prepare, src); err != nil {
@@ -0,0 +1,235 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/InsighterInc/bxmp/common"
+)
+
+// inspectTimeout is how long to wait for the event loop to take a snapshot of
+// the consensus state.
+const inspectTimeout = 5 * time.Second
+
+// Status summarizes the consensus state of the current view.
+type Status struct {
+	Sequence              uint64         `json:"sequence"`
+	Round                 uint64         `json:"round"`
+	State                 string         `json:"state"`
+	Proposer              common.Address `json:"proposer"`
+	IsProposer            bool           `json:"isProposer"`
+	WaitingForRoundChange bool           `json:"waitingForRoundChange"`
+	Proposal              *common.Hash   `json:"proposal"`   // Proposal being agreed on, if any
+	LockedHash            *common.Hash   `json:"lockedHash"` // Proposal locked from an earlier round, if any
+	Validators            int            `json:"validators"`
+	Quorum                int            `json:"quorum"` // Messages needed to move on, 2F+1
+	Prepares              int            `json:"prepares"`
+	Commits               int            `json:"commits"`
+	RoundChanges          map[uint64]int `json:"roundChanges"`    // ROUND-CHANGE messages per requested round
+	Backlog               int            `json:"backlog"`         // Future messages waiting to be processed
+	PendingRequests       int            `json:"pendingRequests"` // Proposals waiting for their sequence
+}
+
+// RoundState details the messages collected from each validator in the
+// current view.
+type RoundState struct {
+	Sequence   uint64               `json:"sequence"`
+	Round      uint64               `json:"round"`
+	Proposal   *common.Hash         `json:"proposal"`
+	LockedHash *common.Hash         `json:"lockedHash"`
+	Validators []*ValidatorMessages `json:"validators"`
+}
+
+// ValidatorMessages lists the messages collected from a single validator.
+type ValidatorMessages struct {
+	Address      common.Address `json:"address"`
+	Proposer     bool           `json:"proposer"`
+	Prepare      bool           `json:"prepare"`
+	Commit       bool           `json:"commit"`
+	RoundChanges []uint64       `json:"roundChanges"` // Rounds the validator asked to move to
+	Backlog      int            `json:"backlog"`
+}
+
+// Status returns a summary of the consensus state of the current view.
+func (c *core) Status() (*Status, error) {
+	status, err := c.inspect(func() interface{} { return c.status() })
+	if err != nil {
+		return nil, err
+	}
+	return status.(*Status), nil
+}
+
+// RoundState returns the messages collected per validator in the current view.
+func (c *core) RoundState() (*RoundState, error) {
+	state, err := c.inspect(func() interface{} { return c.roundState() })
+	if err != nil {
+		return nil, err
+	}
+	return state.(*RoundState), nil
+}
+
+// inspect runs fn on the event loop, so that it can read the consensus state
+// without racing with the processing of messages, and returns its result. The
+// result is handed over on a buffered channel, so that fn completing after a
+// timeout neither blocks the event loop nor touches the caller's variables.
+func (c *core) inspect(fn func() interface{}) (interface{}, error) {
+	c.quitMu.Lock()
+	quit := c.quit
+	c.quitMu.Unlock()
+	if quit == nil {
+		return nil, errInspectTimeout
+	}
+	result := make(chan interface{}, 1)
+	if err := c.backend.EventMux().Post(inspectEvent{fn: fn, result: result}); err != nil {
+		return nil, err
+	}
+	select {
+	case res := <-result:
+		return res, nil
+	case <-quit:
+		return nil, errInspectTimeout
+	case <-time.After(inspectTimeout):
+		return nil, errInspectTimeout
+	}
+}
+
+func (c *core) status() *Status {
+	status := &Status{
+		Sequence:              c.current.Sequence().Uint64(),
+		Round:                 c.current.Round().Uint64(),
+		State:                 c.state.String(),
+		IsProposer:            c.isProposer(),
+		WaitingForRoundChange: c.waitingForRoundChange,
+		Proposal:              c.currentProposalHash(),
+		LockedHash:            c.currentLockedHash(),
+		Prepares:              c.current.Prepares.Size(),
+		Commits:               c.current.Commits.Size(),
+		RoundChanges:          make(map[uint64]int),
+	}
+	if c.valSet != nil {
+		if proposer := c.valSet.GetProposer(); proposer != nil {
+			status.Proposer = proposer.Address()
+		}
+		status.Validators = c.valSet.Size()
+		status.Quorum = 2*c.valSet.F() + 1
+	}
+	if c.roundChangeSet != nil {
+		c.roundChangeSet.mu.Lock()
+		for round, msgs := range c.roundChangeSet.roundChanges {
+			status.RoundChanges[round] = msgs.Size()
+		}
+		c.roundChangeSet.mu.Unlock()
+	}
+	for _, size := range c.backlogSizes() {
+		status.Backlog += size
+	}
+	c.pendingRequestsMu.Lock()
+	status.PendingRequests = c.pendingRequests.Size()
+	c.pendingRequestsMu.Unlock()
+
+	return status
+}
+
+func (c *core) roundState() *RoundState {
+	state := &RoundState{
+		Sequence:   c.current.Sequence().Uint64(),
+		Round:      c.current.Round().Uint64(),
+		Proposal:   c.currentProposalHash(),
+		LockedHash: c.currentLockedHash(),
+	}
+	if c.valSet == nil {
+		return state
+	}
+	validators := make(map[common.Address]*ValidatorMessages)
+	for _, val := range c.valSet.List() {
+		msgs := &ValidatorMessages{
+			Address:      val.Address(),
+			Proposer:     c.valSet.IsProposer(val.Address()),
+			RoundChanges: []uint64{},
+		}
+		validators[val.Address()] = msgs
+		state.Validators = append(state.Validators, msgs)
+	}
+	for _, msg := range c.current.Prepares.Values() {
+		if msgs := validators[msg.Address]; msgs != nil {
+			msgs.Prepare = true
+		}
+	}
+	for _, msg := range c.current.Commits.Values() {
+		if msgs := validators[msg.Address]; msgs != nil {
+			msgs.Commit = true
+		}
+	}
+	if c.roundChangeSet != nil {
+		c.roundChangeSet.mu.Lock()
+		for round, set := range c.roundChangeSet.roundChanges {
+			for _, msg := range set.Values() {
+				if msgs := validators[msg.Address]; msgs != nil {
+					msgs.RoundChanges = append(msgs.RoundChanges, round)
+				}
+			}
+		}
+		c.roundChangeSet.mu.Unlock()
+	}
+	for address, size := range c.backlogSizes() {
+		if msgs := validators[address]; msgs != nil {
+			msgs.Backlog = size
+		}
+	}
+	for _, msgs := range state.Validators {
+		sort.Sort(rounds(msgs.RoundChanges))
+	}
+	return state
+}
+
+// backlogSizes returns the number of future messages stored per validator.
+func (c *core) backlogSizes() map[common.Address]int {
+	c.backlogsMu.Lock()
+	defer c.backlogsMu.Unlock()
+
+	sizes := make(map[common.Address]int)
+	for src, backlog := range c.backlogs {
+		if backlog != nil {
+			sizes[src.Address()] += backlog.Size()
+		}
+	}
+	return sizes
+}
+
+func (c *core) currentProposalHash() *common.Hash {
+	if proposal := c.current.Proposal(); proposal != nil {
+		hash := proposal.Hash()
+		return &hash
+	}
+	return nil
+}
+
+func (c *core) currentLockedHash() *common.Hash {
+	if !c.current.IsHashLocked() {
+		return nil
+	}
+	hash := c.current.GetLockedHash()
+	return &hash
+}
+
+type rounds []uint64
+
+func (r rounds) Len() int           { return len(r) }
+func (r rounds) Less(i, j int) bool { return r[i] < r[j] }
+func (r rounds) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
@@ -0,0 +1,127 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/istanbul"
+)
+
+func TestStatus(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	backend := sys.backends[0]
+	c := backend.engine.(*core)
+	c.valSet = backend.peers
+	c.roundChangeSet = newRoundChangeSet(c.valSet)
+
+	// Collect a PREPARE, a ROUND-CHANGE and a future COMMIT from the others
+	val1, val2, val3 := c.valSet.GetByIndex(1), c.valSet.GetByIndex(2), c.valSet.GetByIndex(3)
+	c.current.Prepares.Add(&message{Code: msgPrepare, Address: val1.Address()})
+	c.roundChangeSet.Add(big.NewInt(2), &message{Code: msgRoundChange, Address: val2.Address()})
+
+	subject, _ := Encode(&istanbul.Subject{
+		View:   &istanbul.View{Sequence: big.NewInt(2), Round: big.NewInt(0)},
+		Digest: common.Hash{},
+	})
+	c.storeBacklog(&message{Code: msgCommit, Msg: subject, Address: val3.Address()}, val3)
+
+	status := c.status()
+	want := &Status{
+		Sequence:        1,
+		Round:           0,
+		State:           StateAcceptRequest.String(),
+		Proposer:        c.valSet.GetProposer().Address(),
+		IsProposer:      true,
+		Validators:      4,
+		Quorum:          3,
+		Prepares:        1,
+		RoundChanges:    map[uint64]int{2: 1},
+		Backlog:         1,
+		PendingRequests: 0,
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("status mismatch:\nhave %+v\nwant %+v", status, want)
+	}
+
+	state := c.roundState()
+	if len(state.Validators) != 4 {
+		t.Fatalf("validator count mismatch: have %d, want 4", len(state.Validators))
+	}
+	wantMsgs := []*ValidatorMessages{
+		{Address: backend.address, Proposer: true, RoundChanges: []uint64{}},
+		{Address: val1.Address(), Prepare: true, RoundChanges: []uint64{}},
+		{Address: val2.Address(), RoundChanges: []uint64{2}},
+		{Address: val3.Address(), RoundChanges: []uint64{}, Backlog: 1},
+	}
+	for i, msgs := range state.Validators {
+		if !reflect.DeepEqual(msgs, wantMsgs[i]) {
+			t.Errorf("validator %d messages mismatch: have %+v, want %+v", i, msgs, wantMsgs[i])
+		}
+	}
+}
+
+func TestStatusEventLoop(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	closer := sys.Run(true)
+	defer closer()
+
+	for i, backend := range sys.backends {
+		status, err := backend.engine.Status()
+		if err != nil {
+			t.Fatalf("backend %d: failed to retrieve status: %v", i, err)
+		}
+		if status.Sequence != 1 || status.State != StateAcceptRequest.String() || status.Validators != 4 {
+			t.Errorf("backend %d: status mismatch: have %+v", i, status)
+		}
+		state, err := backend.engine.RoundState()
+		if err != nil {
+			t.Fatalf("backend %d: failed to retrieve round state: %v", i, err)
+		}
+		if state.Sequence != 1 || len(state.Validators) != 4 {
+			t.Errorf("backend %d: round state mismatch: have %+v", i, state)
+		}
+	}
+}
+
+// Tests that inspecting a stopped core fails right away instead of waiting for
+// an event loop that is gone.
+func TestStatusStopped(t *testing.T) {
+	sys := NewTestSystemWithBackend(1, 0)
+	engine := sys.backends[0].engine
+
+	if _, err := engine.Status(); err != errInspectTimeout {
+		t.Errorf("error mismatch before start: have %v, want %v", err, errInspectTimeout)
+	}
+	closer := sys.Run(true)
+	if _, err := engine.Status(); err != nil {
+		t.Fatalf("failed to retrieve status: %v", err)
+	}
+	closer()
+
+	start := time.Now()
+	if _, err := engine.RoundState(); err != errInspectTimeout {
+		t.Errorf("error mismatch after stop: have %v, want %v", err, errInspectTimeout)
+	}
+	if elapsed := time.Since(start); elapsed >= inspectTimeout {
+		t.Errorf("inspecting stopped core took %v", elapsed)
+	}
+}
@@ -42,6 +42,7 @@ type testSystemBackend struct {
 
 	committedMsgs []testCommittedMsgs
 	sentMsgs      [][]byte // store the message when Send is called by core
+	misbehaviours []*istanbul.Misbehaviour
 
 	address common.Address
 	db      bxmdb.Database
@@ -137,6 +138,10 @@ func (self *testSystemBackend) LastProposal() (istanbul.Proposal, common.Address
 	return makeBlock(1), common.Address{}
 }
 
+func (self *testSystemBackend) ReportMisbehaviour(misbehaviour *istanbul.Misbehaviour) {
+	self.misbehaviours = append(self.misbehaviours, misbehaviour)
+}
+
 // ==============================================
 //
 // define the struct that need to be provided for integration tests.
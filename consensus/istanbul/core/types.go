@@ -29,6 +29,11 @@ import (
 type Engine interface {
 	Start(lastSequence *big.Int, lastProposer common.Address, lastProposal istanbul.Proposal) error
 	Stop() error
+
+	// Status returns a summary of the consensus state of the current view.
+	Status() (*Status, error)
+	// RoundState returns the messages collected per validator in the current view.
+	RoundState() (*RoundState, error)
 }
 
 type State uint64
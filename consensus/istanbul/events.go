@@ -33,3 +33,9 @@ type FinalCommittedEvent struct {
 	Proposal Proposal
 	Proposer common.Address
 }
+
+// MisbehaviourEvent is posted when a validator is caught sending conflicting
+// messages
+type MisbehaviourEvent struct {
+	Misbehaviour *Misbehaviour
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/common/hexutil"
 	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/rlp"
 )
@@ -145,3 +146,14 @@ func (b *Subject) DecodeRLP(s *rlp.Stream) error {
 func (b *Subject) String() string {
 	return fmt.Sprintf("{View: %v, Digest: %v}", b.View, b.Digest.String())
 }
+
+// Misbehaviour is the evidence of a validator sending two conflicting messages
+// of the same kind for one view. The evidence holds both messages RLP encoded
+// along with the signature of the validator, so anyone can verify it.
+type Misbehaviour struct {
+	Validator common.Address  `json:"validator"`
+	Code      uint64          `json:"code"` // Kind of the messages, 1 for PREPARE and 2 for COMMIT
+	Sequence  uint64          `json:"sequence"`
+	Round     uint64          `json:"round"`
+	Evidence  []hexutil.Bytes `json:"evidence"` // The conflicting signed messages
+}
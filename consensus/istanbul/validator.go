@@ -71,6 +71,18 @@ type ValidatorSet interface {
 	Copy() ValidatorSet
 	// Get the maximum number of faulty nodes
 	F() int
+	// Get proposer policy
+	Policy() ProposerPolicy
+	// Get the proposer selection weight of the validator, 1 unless changed
+	Weight(address common.Address) uint64
+	// Set the proposer selection weight of the validator
+	SetWeight(address common.Address, weight uint64) bool
+	// Get the accumulated proposer priority of the validator
+	Priority(address common.Address) int64
+	// Set the accumulated proposer priority of the validator
+	SetPriority(address common.Address, priority int64) bool
+	// Account for a block proposed by the given validator in the priorities
+	UpdatePriorities(proposer common.Address)
 }
 
 // ----------------------------------------------------------------------------
@@ -45,11 +45,20 @@ type defaultSet struct {
 	proposer    istanbul.Validator
 	validatorMu sync.RWMutex
 
+	policy   istanbul.ProposerPolicy
 	selector istanbul.ProposalSelector
+
+	// weights and accumulated priorities of the weighted proposer policy,
+	// validators missing from them have a weight of 1 and a priority of 0
+	weights    map[common.Address]uint64
+	priorities map[common.Address]int64
 }
 
 func newDefaultSet(addrs []common.Address, selector istanbul.ProposalSelector) *defaultSet {
-	valSet := &defaultSet{}
+	valSet := &defaultSet{
+		weights:    make(map[common.Address]uint64),
+		priorities: make(map[common.Address]int64),
+	}
 
 	// init validators
 	valSet.validators = make([]istanbul.Validator, len(addrs))
@@ -153,6 +162,38 @@ func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, round
 	return valSet.GetByIndex(pick)
 }
 
+// weightedProposer picks the validator with the highest accumulated priority,
+// once the weight of each validator is added to it. As the priority of every
+// validator grows by its weight with each block and the proposer's drops by the
+// total weight, validators propose in proportion to their weights. Later rounds
+// fall back to the next highest priorities.
+func weightedProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+	if valSet.Size() == 0 {
+		return nil
+	}
+	validators := valSet.List()
+	ranked := make(byPriority, len(validators))
+	for i, val := range validators {
+		ranked[i] = &rankedValidator{
+			validator: val,
+			priority:  valSet.Priority(val.Address()) + int64(valSet.Weight(val.Address())),
+		}
+	}
+	sort.Stable(ranked)
+	return ranked[round%uint64(len(ranked))].validator
+}
+
+type rankedValidator struct {
+	validator istanbul.Validator
+	priority  int64
+}
+
+type byPriority []*rankedValidator
+
+func (r byPriority) Len() int           { return len(r) }
+func (r byPriority) Less(i, j int) bool { return r[i].priority > r[j].priority }
+func (r byPriority) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
 func (valSet *defaultSet) AddValidator(address common.Address) bool {
 	valSet.validatorMu.Lock()
 	defer valSet.validatorMu.Unlock()
@@ -175,6 +216,8 @@ func (valSet *defaultSet) RemoveValidator(address common.Address) bool {
 	for i, v := range valSet.validators {
 		if v.Address() == address {
 			valSet.validators = append(valSet.validators[:i], valSet.validators[i+1:]...)
+			delete(valSet.weights, address)
+			delete(valSet.priorities, address)
 			return true
 		}
 	}
@@ -189,7 +232,86 @@ func (valSet *defaultSet) Copy() istanbul.ValidatorSet {
 	for _, v := range valSet.validators {
 		addresses = append(addresses, v.Address())
 	}
-	return newDefaultSet(addresses, valSet.selector)
+	cpy := newDefaultSet(addresses, valSet.selector)
+	cpy.policy = valSet.policy
+	for addr, weight := range valSet.weights {
+		cpy.weights[addr] = weight
+	}
+	for addr, priority := range valSet.priorities {
+		cpy.priorities[addr] = priority
+	}
+	return cpy
 }
 
 func (valSet *defaultSet) F() int { return int(math.Ceil(float64(valSet.Size())/3)) - 1 }
+
+func (valSet *defaultSet) Policy() istanbul.ProposerPolicy { return valSet.policy }
+
+func (valSet *defaultSet) Weight(address common.Address) uint64 {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	if weight, ok := valSet.weights[address]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (valSet *defaultSet) SetWeight(address common.Address, weight uint64) bool {
+	if weight == 0 || weight > MaxWeight {
+		return false
+	}
+	if _, val := valSet.GetByAddress(address); val == nil {
+		return false
+	}
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+	if weight == 1 {
+		delete(valSet.weights, address)
+	} else {
+		valSet.weights[address] = weight
+	}
+	return true
+}
+
+func (valSet *defaultSet) Priority(address common.Address) int64 {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	return valSet.priorities[address]
+}
+
+func (valSet *defaultSet) SetPriority(address common.Address, priority int64) bool {
+	if _, val := valSet.GetByAddress(address); val == nil {
+		return false
+	}
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+	valSet.setPriority(address, priority)
+	return true
+}
+
+func (valSet *defaultSet) UpdatePriorities(proposer common.Address) {
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+
+	total, found := int64(0), false
+	for _, v := range valSet.validators {
+		weight := int64(1)
+		if w, ok := valSet.weights[v.Address()]; ok {
+			weight = int64(w)
+		}
+		valSet.setPriority(v.Address(), valSet.priorities[v.Address()]+weight)
+		total += weight
+		found = found || v.Address() == proposer
+	}
+	if found {
+		valSet.setPriority(proposer, valSet.priorities[proposer]-total)
+	}
+}
+
+func (valSet *defaultSet) setPriority(address common.Address, priority int64) {
+	if priority == 0 {
+		delete(valSet.priorities, address)
+	} else {
+		valSet.priorities[address] = priority
+	}
+}
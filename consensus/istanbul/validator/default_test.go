@@ -36,6 +36,7 @@ func TestValidatorSet(t *testing.T) {
 	testNormalValSet(t)
 	testEmptyValSet(t)
 	testStickyProposer(t)
+	testWeightedProposer(t)
 	testAddAndRemoveValidator(t)
 }
 
@@ -206,3 +207,59 @@ func testStickyProposer(t *testing.T) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 }
+
+func testWeightedProposer(t *testing.T) {
+	addr1 := common.BytesToAddress(common.Hex2Bytes(testAddress))
+	addr2 := common.BytesToAddress(common.Hex2Bytes(testAddress2))
+	addr3 := common.StringToAddress("weighted")
+
+	valSet := NewSet([]common.Address{addr1, addr2, addr3}, istanbul.Weighted)
+	if valSet.Policy() != istanbul.Weighted {
+		t.Errorf("policy mismatch: have %v, want %v", valSet.Policy(), istanbul.Weighted)
+	}
+	// test weights
+	if weight := valSet.Weight(addr1); weight != 1 {
+		t.Errorf("default weight mismatch: have %d, want 1", weight)
+	}
+	if valSet.SetWeight(addr1, 0) || valSet.SetWeight(addr1, MaxWeight+1) {
+		t.Errorf("out of range weight should be rejected")
+	}
+	if valSet.SetWeight(common.StringToAddress("unknown"), 2) {
+		t.Errorf("weight of a non validator should be rejected")
+	}
+	valSet.SetWeight(addr2, 2)
+	valSet.SetWeight(addr3, 3)
+
+	// test that validators propose in proportion to their weights
+	proposed := make(map[common.Address]int)
+	lastProposer := common.Address{}
+	for i := 0; i < 60; i++ {
+		valSet.CalcProposer(lastProposer, 0)
+		lastProposer = valSet.GetProposer().Address()
+		proposed[lastProposer]++
+		valSet.UpdatePriorities(lastProposer)
+	}
+	want := map[common.Address]int{addr1: 10, addr2: 20, addr3: 30}
+	if !reflect.DeepEqual(proposed, want) {
+		t.Errorf("proposed blocks mismatch: have %v, want %v", proposed, want)
+	}
+	// test that later rounds move on to other validators
+	valSet.CalcProposer(lastProposer, 0)
+	first := valSet.GetProposer()
+	valSet.CalcProposer(lastProposer, 1)
+	if second := valSet.GetProposer(); reflect.DeepEqual(first, second) {
+		t.Errorf("round change should pick another proposer, have %v", second)
+	}
+	// test that copies keep weights and priorities
+	cpy := valSet.Copy()
+	for _, addr := range []common.Address{addr1, addr2, addr3} {
+		if cpy.Weight(addr) != valSet.Weight(addr) || cpy.Priority(addr) != valSet.Priority(addr) {
+			t.Errorf("copy mismatch for %x", addr)
+		}
+	}
+	// test that removed validators lose their weight
+	valSet.RemoveValidator(addr3)
+	if weight := valSet.Weight(addr3); weight != 1 {
+		t.Errorf("weight of removed validator mismatch: have %d, want 1", weight)
+	}
+}
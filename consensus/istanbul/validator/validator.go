@@ -17,10 +17,15 @@
 package validator
 
 import (
+	"math"
+
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/consensus/istanbul"
 )
 
+// MaxWeight is the highest proposer selection weight a validator can be given.
+const MaxWeight = math.MaxUint32
+
 func New(addr common.Address) istanbul.Validator {
 	return &defaultValidator{
 		address: addr,
@@ -28,15 +33,19 @@ func New(addr common.Address) istanbul.Validator {
 }
 
 func NewSet(addrs []common.Address, policy istanbul.ProposerPolicy) istanbul.ValidatorSet {
+	var valSet *defaultSet
 	switch policy {
-	case istanbul.RoundRobin:
-		return newDefaultSet(addrs, roundRobinProposer)
 	case istanbul.Sticky:
-		return newDefaultSet(addrs, stickyProposer)
+		valSet = newDefaultSet(addrs, stickyProposer)
+	case istanbul.Weighted:
+		valSet = newDefaultSet(addrs, weightedProposer)
+	default:
+		// use round-robin policy as default proposal policy
+		valSet = newDefaultSet(addrs, roundRobinProposer)
+		policy = istanbul.RoundRobin
 	}
-
-	// use round-robin policy as default proposal policy
-	return newDefaultSet(addrs, roundRobinProposer)
+	valSet.policy = policy
+	return valSet
 }
 
 func ExtractValidators(extraData []byte) []common.Address {
@@ -0,0 +1,69 @@
+pragma solidity ^0.4.11;
+
+// NodePermissions holds the allow-list of a permissioned network: the IDs of
+// the nodes that may connect to each other, as hex encoded public keys without
+// the enode:// prefix. Only admins may change the list.
+contract NodePermissions {
+    event NodeAdded(string nodeId);
+    event NodeRemoved(string nodeId);
+    event AdminAdded(address admin);
+    event AdminRemoved(address admin);
+
+    mapping(address => bool) public admins;
+
+    string[] nodes;
+    mapping(string => uint) positions; // 1-based position in nodes, 0 if absent
+
+    modifier onlyAdmin() {
+        require(admins[msg.sender]);
+        _;
+    }
+
+    // The creator of the contract is its first admin.
+    function NodePermissions() {
+        admins[msg.sender] = true;
+    }
+
+    function addAdmin(address admin) onlyAdmin {
+        admins[admin] = true;
+        AdminAdded(admin);
+    }
+
+    // Admins can't remove themselves, so that there is always at least one.
+    function removeAdmin(address admin) onlyAdmin {
+        require(admin != msg.sender);
+        delete admins[admin];
+        AdminRemoved(admin);
+    }
+
+    function addNode(string nodeId) onlyAdmin {
+        require(positions[nodeId] == 0);
+        nodes.push(nodeId);
+        positions[nodeId] = nodes.length;
+        NodeAdded(nodeId);
+    }
+
+    // removeNode moves the last node into the slot of the removed one.
+    function removeNode(string nodeId) onlyAdmin {
+        uint pos = positions[nodeId];
+        require(pos != 0);
+        string storage last = nodes[nodes.length - 1];
+        nodes[pos - 1] = last;
+        positions[last] = pos;
+        nodes.length--;
+        delete positions[nodeId];
+        NodeRemoved(nodeId);
+    }
+
+    function nodeCount() constant returns (uint) {
+        return nodes.length;
+    }
+
+    function nodeAt(uint index) constant returns (string) {
+        return nodes[index];
+    }
+
+    function isPermitted(string nodeId) constant returns (bool) {
+        return positions[nodeId] != 0;
+    }
+}
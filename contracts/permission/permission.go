@@ -0,0 +1,257 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package permission contains the node service that takes the allow-list of a
+// permissioned network from an on-chain contract.
+package permission
+
+//go:generate abigen --abi contract/permission.abi --pkg contract --type NodePermissions --out contract/permission.go
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/InsighterInc/bxmp/accounts/abi/bind"
+	"github.com/InsighterInc/bxmp/bxm"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/contracts/permission/contract"
+	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/event"
+	"github.com/InsighterInc/bxmp/internal/bxmapi"
+	"github.com/InsighterInc/bxmp/les"
+	"github.com/InsighterInc/bxmp/log"
+	"github.com/InsighterInc/bxmp/node"
+	"github.com/InsighterInc/bxmp/p2p"
+	"github.com/InsighterInc/bxmp/p2p/discover"
+	"github.com/InsighterInc/bxmp/rpc"
+)
+
+// chainEventer is the part of the BitMED backend the service uses to learn
+// about changes to the contract.
+type chainEventer interface {
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+}
+
+// PermissionService is a node service that keeps a cached view of the node
+// allow-list held by a NodePermissions contract and has the p2p server enforce
+// it. The list is reloaded whenever a block carries logs of the contract.
+//
+// Until the list could be read for the first time, e.g. while the node is still
+// syncing up to the block deploying the contract, the server keeps using the
+// permissioned-nodes.json file.
+type PermissionService struct {
+	address  common.Address
+	contract *contract.NodePermissionsCaller
+	chain    chainEventer
+
+	lock   sync.RWMutex
+	nodes  map[discover.NodeID]bool // nil until the list was loaded
+	server *p2p.Server
+
+	quit chan chan error
+}
+
+// NewPermissionService creates a service enforcing the allow-list of the
+// NodePermissions contract at the given address.
+func NewPermissionService(ctx *node.ServiceContext, address common.Address) (node.Service, error) {
+	// Retrieve the BitMED service dependency to access the blockchain
+	var apiBackend bxmapi.Backend
+	var bitmed *bxm.BitMED
+	if err := ctx.Service(&bitmed); err == nil {
+		apiBackend = bitmed.ApiBackend
+	} else {
+		var bitmed *les.LightBitmed
+		if err := ctx.Service(&bitmed); err == nil {
+			apiBackend = bitmed.ApiBackend
+		} else {
+			return nil, err
+		}
+	}
+	return newPermissionService(address, bxm.NewContractBackend(apiBackend), apiBackend)
+}
+
+func newPermissionService(address common.Address, caller bind.ContractCaller, chain chainEventer) (*PermissionService, error) {
+	contract, err := contract.NewNodePermissionsCaller(address, caller)
+	if err != nil {
+		return nil, err
+	}
+	return &PermissionService{
+		address:  address,
+		contract: contract,
+		chain:    chain,
+		quit:     make(chan chan error),
+	}, nil
+}
+
+// Protocols returns an empty list of P2P protocols as the permission service
+// does not have a networking component.
+func (s *PermissionService) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns an empty list of RPC descriptors, the list is managed through
+// transactions to the contract.
+func (s *PermissionService) APIs() []rpc.API { return nil }
+
+// Start spawns the goroutine following the contract.
+func (s *PermissionService) Start(server *p2p.Server) error {
+	if !server.EnableNodePermission {
+		log.Warn("Node permissioning contract configured without --permissioned, ignoring it", "contract", s.address)
+	}
+	s.lock.Lock()
+	s.server = server
+	s.lock.Unlock()
+
+	go s.loop()
+	return nil
+}
+
+// Stop terminates the goroutine following the contract, blocking until it is
+// terminated.
+func (s *PermissionService) Stop() error {
+	errc := make(chan error)
+	s.quit <- errc
+	return <-errc
+}
+
+// IsPermitted implements p2p.NodePermissions, reporting whether the node is on
+// the last loaded allow-list.
+func (s *PermissionService) IsPermitted(id discover.NodeID) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.nodes[id]
+}
+
+// Nodes returns the IDs on the last loaded allow-list, or nil if it has not
+// been loaded yet.
+func (s *PermissionService) Nodes() []discover.NodeID {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.nodes == nil {
+		return nil
+	}
+	ids := make([]discover.NodeID, 0, len(s.nodes))
+	for id := range s.nodes {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// loop reloads the allow-list at startup and whenever the chain changes in a
+// way that may have changed the contract: blocks with logs of the contract and
+// reorgs.
+func (s *PermissionService) loop() {
+	events := make(chan core.ChainEvent, 16)
+	sub := s.chain.SubscribeChainEvent(events)
+	defer sub.Unsubscribe()
+
+	s.refresh()
+
+	var head common.Hash
+	for {
+		select {
+		case ev := <-events:
+			reorg := head != (common.Hash{}) && ev.Block.ParentHash() != head
+			head = ev.Hash
+			if reorg || !s.loaded() || s.touched(ev.Logs) {
+				s.refresh()
+			}
+		case <-sub.Err():
+			// The chain shut down, wait for our own teardown
+			errc := <-s.quit
+			errc <- nil
+			return
+		case errc := <-s.quit:
+			errc <- nil
+			return
+		}
+	}
+}
+
+func (s *PermissionService) loaded() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.nodes != nil
+}
+
+// touched reports whether any of the logs was emitted by the contract.
+func (s *PermissionService) touched(logs []*types.Log) bool {
+	for _, l := range logs {
+		if l.Address == s.address {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh reloads the allow-list from the contract and has the server enforce
+// it. On failure the previous list, or the permissioned-nodes.json file if there
+// is none yet, stays in use.
+func (s *PermissionService) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nodes, err := s.fetch(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		if err == bind.ErrNoCode {
+			log.Debug("Node permissioning contract not found", "contract", s.address)
+		} else {
+			log.Error("Failed to load permissioned nodes from contract", "contract", s.address, "err", err)
+		}
+		return
+	}
+	s.lock.Lock()
+	first := s.nodes == nil
+	s.nodes = nodes
+	server := s.server
+	s.lock.Unlock()
+
+	log.Info("Loaded permissioned nodes from contract", "contract", s.address, "count", len(nodes))
+	if server == nil {
+		return
+	}
+	if first {
+		server.SetNodePermissions(s)
+	} else {
+		server.EnforceNodePermissions()
+	}
+}
+
+// fetch reads the whole allow-list from the contract.
+func (s *PermissionService) fetch(opts *bind.CallOpts) (map[discover.NodeID]bool, error) {
+	count, err := s.contract.NodeCount(opts)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[discover.NodeID]bool)
+	for i := int64(0); i < count.Int64(); i++ {
+		url, err := s.contract.NodeAt(opts, big.NewInt(i))
+		if err != nil {
+			return nil, err
+		}
+		node, err := discover.ParseNode(url)
+		if err != nil {
+			log.Warn("Invalid node in permissioning contract", "node", url, "err", err)
+			continue
+		}
+		nodes[node.ID] = true
+	}
+	return nodes, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package permission
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp"
+	"github.com/InsighterInc/bxmp/accounts/abi"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/contracts/permission/contract"
+	"github.com/InsighterInc/bxmp/core"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/event"
+	"github.com/InsighterInc/bxmp/p2p"
+	"github.com/InsighterInc/bxmp/p2p/discover"
+)
+
+// testContract emulates a deployed NodePermissions contract by answering the
+// calls of the binding from an in-memory list.
+type testContract struct {
+	abi     abi.ABI
+	returns abi.ABI // packs return values as if they were method arguments
+
+	mu       sync.Mutex
+	deployed bool
+	nodes    []string
+}
+
+func newTestContract(t *testing.T) *testContract {
+	parsed, err := abi.JSON(strings.NewReader(contract.NodePermissionsABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	returns, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"uint","inputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"string","inputs":[{"name":"","type":"string"}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testContract{abi: parsed, returns: returns}
+}
+
+func (c *testContract) set(deployed bool, nodes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deployed, c.nodes = deployed, nodes
+}
+
+func (c *testContract) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.deployed {
+		return nil, nil
+	}
+	return []byte{1}, nil
+}
+
+func (c *testContract) CallContract(ctx context.Context, call bitmed.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.deployed {
+		return nil, nil
+	}
+	var (
+		out []byte
+		err error
+	)
+	switch id := string(call.Data[:4]); id {
+	case string(c.abi.Methods["nodeCount"].Id()):
+		out, err = c.returns.Pack("uint", big.NewInt(int64(len(c.nodes))))
+	case string(c.abi.Methods["nodeAt"].Id()):
+		index := new(big.Int).SetBytes(call.Data[4:36]).Int64()
+		out, err = c.returns.Pack("string", c.nodes[index])
+	default:
+		return nil, fmt.Errorf("unexpected call %x", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out[4:], nil
+}
+
+// testChain feeds chain events to the service.
+type testChain struct {
+	feed event.Feed
+}
+
+func (c *testChain) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func randomNode() (discover.NodeID, string) {
+	key, _ := crypto.GenerateKey()
+	id := discover.PubkeyID(&key.PublicKey)
+	return id, id.String()
+}
+
+func TestPermissionService(t *testing.T) {
+	address := common.HexToAddress("0x0000000000000000000000000000000000000042")
+	backend := newTestContract(t)
+	chain := new(testChain)
+
+	service, err := newPermissionService(address, backend, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Start(&p2p.Server{}); err != nil {
+		t.Fatal(err)
+	}
+	defer service.Stop()
+
+	// Before the contract exists, nothing is loaded and the server keeps the file
+	a, aURL := randomNode()
+	b, bURL := randomNode()
+	if service.loaded() || service.IsPermitted(a) {
+		t.Fatalf("allow-list loaded without a contract")
+	}
+	var parent common.Hash
+	send := func(logs ...*types.Log) {
+		block := types.NewBlockWithHeader(&types.Header{ParentHash: parent, Number: big.NewInt(1)})
+		parent = block.Hash()
+		chain.feed.Send(core.ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
+	}
+	waitFor := func(what string, cond func() bool) {
+		for i := 0; i < 100; i++ {
+			if cond() {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("timeout waiting for %s", what)
+	}
+	// Deploying the contract is picked up with the next block
+	backend.set(true, aURL)
+	send()
+	waitFor("initial load", func() bool { return service.IsPermitted(a) })
+	if service.IsPermitted(b) {
+		t.Errorf("node %x permitted without being listed", b[:8])
+	}
+	// Changes are only picked up by blocks with logs of the contract
+	backend.set(true, bURL, "invalid")
+	send(&types.Log{Address: common.HexToAddress("0x01")})
+	time.Sleep(50 * time.Millisecond)
+	if !service.IsPermitted(a) {
+		t.Fatalf("allow-list reloaded for an unrelated log")
+	}
+	send(&types.Log{Address: address})
+	waitFor("reload", func() bool { return service.IsPermitted(b) && !service.IsPermitted(a) })
+	if nodes := service.Nodes(); len(nodes) != 1 || nodes[0] != b {
+		t.Errorf("nodes mismatch: have %v, want [%x]", nodes, b[:8])
+	}
+}
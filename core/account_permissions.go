@@ -0,0 +1,180 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/core/state"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/core/vm"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/params"
+)
+
+// AccountRole is the kind of transactions an account is permitted to send.
+type AccountRole uint8
+
+const (
+	RoleReadOnly AccountRole = iota // No transactions at all
+	RoleTransact                    // Transactions to existing accounts and contracts
+	RoleDeploy                      // Contract creations as well
+)
+
+var accountRoleNames = []string{"readonly", "transact", "deploy"}
+
+// String implements the fmt.Stringer interface.
+func (r AccountRole) String() string {
+	if int(r) < len(accountRoleNames) {
+		return accountRoleNames[r]
+	}
+	return fmt.Sprintf("AccountRole(%d)", r)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r AccountRole) MarshalText() ([]byte, error) {
+	if int(r) >= len(accountRoleNames) {
+		return nil, fmt.Errorf("invalid account role %d", r)
+	}
+	return []byte(accountRoleNames[r]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *AccountRole) UnmarshalText(input []byte) error {
+	for i, name := range accountRoleNames {
+		if string(input) == name {
+			*r = AccountRole(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown account role %q, want one of %v", input, accountRoleNames)
+}
+
+// AccountPolicy is a local policy assigning the accounts their role. It only
+// keeps the transactions it doesn't permit out of the transaction pool and the
+// blocks of the node, blocks are validated against the account permissions of
+// the chain alone, so that nodes with different policies agree on them.
+type AccountPolicy struct {
+	Default  AccountRole                    `json:"default"`  // Role of the accounts not listed
+	Accounts map[common.Address]AccountRole `json:"accounts"` // Roles of individual accounts
+}
+
+// LoadAccountPolicy reads an account policy from the given JSON file.
+func LoadAccountPolicy(path string) (*AccountPolicy, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(AccountPolicy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, fmt.Errorf("invalid account policy %s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Role returns the role the policy assigns to the given account.
+func (p *AccountPolicy) Role(addr common.Address) AccountRole {
+	if role, ok := p.Accounts[addr]; ok {
+		return role
+	}
+	return p.Default
+}
+
+// Check returns an error if the policy doesn't permit the sender to send the
+// transaction. A nil policy permits all transactions.
+func (p *AccountPolicy) Check(from common.Address, create bool) error {
+	if p == nil {
+		return nil
+	}
+	return checkAccountRole(p.Role(from), create)
+}
+
+// accountPermissionsCallGas is the gas available to the account permissions
+// contract to look up the role of an account.
+const accountPermissionsCallGas = 1000000
+
+// roleOfSelector is the method id of roleOf(address), answered by the account
+// permissions contract with the role of the account as an uint8.
+var roleOfSelector = crypto.Keccak256([]byte("roleOf(address)"))[:4]
+
+// contractAccountRole calls the account permissions contract of the chain on
+// the given state and returns the role it assigns to the account. As long as
+// the contract isn't deployed, accounts aren't restricted. Accounts the contract
+// fails to answer for are read-only.
+func contractAccountRole(config *params.ChainConfig, statedb *state.StateDB, header *types.Header, addr common.Address) AccountRole {
+	contract := config.AccountPermissions.Contract
+	if statedb.GetCodeSize(contract) == 0 {
+		return RoleDeploy
+	}
+	context := vm.Context{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Coinbase:    header.Coinbase,
+		GasLimit:    new(big.Int).Set(header.GasLimit),
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        new(big.Int).Set(header.Time),
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+		GasPrice:    new(big.Int),
+	}
+	// Call the contract on a snapshot, so that the state is left as is
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	input := append(append([]byte{}, roleOfSelector...), common.LeftPadBytes(addr[:], 32)...)
+	evm := vm.NewEVM(context, statedb, statedb, config, vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contract, input, accountPermissionsCallGas)
+	if err != nil || len(ret) != 32 {
+		return RoleReadOnly
+	}
+	if role := new(big.Int).SetBytes(ret); role.Cmp(big.NewInt(int64(RoleDeploy))) <= 0 {
+		return AccountRole(role.Uint64())
+	}
+	return RoleReadOnly
+}
+
+// checkAccountPermission returns an error if the sender isn't permitted to send
+// the transaction, either by the account permissions contract of the chain or
+// by the local policy if there is one. An account gets the most restrictive of
+// the two roles. Blocks are validated without a local policy.
+func checkAccountPermission(config *params.ChainConfig, policy *AccountPolicy, statedb *state.StateDB, header *types.Header, from common.Address, create bool) error {
+	role := RoleDeploy
+	if policy != nil {
+		role = policy.Role(from)
+	}
+	if role > RoleReadOnly && config.IsAccountPermissioned(header.Number) {
+		if contractRole := contractAccountRole(config, statedb, header, from); contractRole < role {
+			role = contractRole
+		}
+	}
+	return checkAccountRole(role, create)
+}
+
+// checkAccountRole returns an error if the role doesn't permit the transaction.
+func checkAccountRole(role AccountRole, create bool) error {
+	switch {
+	case role == RoleReadOnly:
+		return ErrReadOnlyAccount
+	case create && role < RoleDeploy:
+		return ErrDeployNotPermitted
+	}
+	return nil
+}
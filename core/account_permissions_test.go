@@ -0,0 +1,221 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/bxmdb"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/ethash"
+	"github.com/InsighterInc/bxmp/core/state"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/core/vm"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/event"
+	"github.com/InsighterInc/bxmp/params"
+)
+
+// rolesCode is the runtime code of an account permissions contract answering
+// roleOf(address) with the storage slot of the account.
+var rolesCode = common.Hex2Bytes("6004355460005260206000f3")
+
+func TestAccountPolicyJSON(t *testing.T) {
+	var policy AccountPolicy
+	blob := `{"default": "readonly", "accounts": {"0x0000000000000000000000000000000000000001": "deploy"}}`
+	if err := json.Unmarshal([]byte(blob), &policy); err != nil {
+		t.Fatalf("failed to decode policy: %v", err)
+	}
+	if role := policy.Role(common.Address{19: 1}); role != RoleDeploy {
+		t.Errorf("listed account role mismatch: have %v, want %v", role, RoleDeploy)
+	}
+	if role := policy.Role(common.Address{19: 2}); role != RoleReadOnly {
+		t.Errorf("default role mismatch: have %v, want %v", role, RoleReadOnly)
+	}
+	if err := json.Unmarshal([]byte(`{"default": "admin"}`), &policy); err == nil {
+		t.Error("unknown role accepted")
+	}
+}
+
+func TestCheckAccountPermission(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	var (
+		contract = common.Address{0xff}
+		readonly = common.Address{1}
+		transact = common.Address{2}
+		deploy   = common.Address{3}
+		unlisted = common.Address{4}
+		invalid  = common.Address{5}
+		config   = &params.ChainConfig{AccountPermissions: &params.AccountPermissionsConfig{Block: big.NewInt(2), Contract: contract}}
+		setRole  = func(addr common.Address, role int64) {
+			statedb.SetState(contract, common.BytesToHash(addr[:]), common.BigToHash(big.NewInt(role)))
+		}
+	)
+	header := func(number int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), GasLimit: big.NewInt(4700000), Time: new(big.Int), Difficulty: new(big.Int)}
+	}
+	// Without the contract deployed, accounts aren't restricted
+	if err := checkAccountPermission(config, nil, statedb, header(2), unlisted, true); err != nil {
+		t.Fatalf("undeployed contract enforced: %v", err)
+	}
+	statedb.SetCode(contract, rolesCode)
+	setRole(transact, int64(RoleTransact))
+	setRole(deploy, int64(RoleDeploy))
+	setRole(invalid, 7)
+
+	tests := []struct {
+		policy *AccountPolicy
+		number int64
+		from   common.Address
+		create bool
+		err    error
+	}{
+		// Before the activation block, the contract isn't consulted
+		{nil, 1, unlisted, true, nil},
+		{nil, 2, unlisted, false, ErrReadOnlyAccount},
+		{nil, 2, readonly, false, ErrReadOnlyAccount},
+		{nil, 2, invalid, false, ErrReadOnlyAccount},
+		{nil, 2, transact, false, nil},
+		{nil, 2, transact, true, ErrDeployNotPermitted},
+		{nil, 2, deploy, true, nil},
+		// The local policy restricts the accounts further
+		{&AccountPolicy{Default: RoleDeploy}, 2, transact, true, ErrDeployNotPermitted},
+		{&AccountPolicy{Default: RoleTransact}, 2, deploy, true, ErrDeployNotPermitted},
+		{&AccountPolicy{Default: RoleTransact}, 1, unlisted, false, nil},
+		{&AccountPolicy{Accounts: map[common.Address]AccountRole{deploy: RoleDeploy}}, 2, deploy, true, nil},
+		{&AccountPolicy{Accounts: map[common.Address]AccountRole{deploy: RoleDeploy}}, 1, transact, false, ErrReadOnlyAccount},
+	}
+	root := statedb.IntermediateRoot(false)
+	for i, tt := range tests {
+		err := checkAccountPermission(config, tt.policy, statedb, header(tt.number), tt.from, tt.create)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if have := statedb.IntermediateRoot(false); have != root {
+		t.Errorf("state modified by permission checks: have %x, want %x", have, root)
+	}
+}
+
+// Tests that the local policy permits the transactions of the accounts
+// according to their role, and a missing policy permits all of them.
+func TestAccountPolicyCheck(t *testing.T) {
+	var (
+		transact = common.Address{1}
+		unlisted = common.Address{2}
+		policy   = &AccountPolicy{Accounts: map[common.Address]AccountRole{transact: RoleTransact}}
+	)
+	tests := []struct {
+		policy *AccountPolicy
+		from   common.Address
+		create bool
+		err    error
+	}{
+		{nil, unlisted, true, nil},
+		{policy, unlisted, false, ErrReadOnlyAccount},
+		{policy, transact, false, nil},
+		{policy, transact, true, ErrDeployNotPermitted},
+	}
+	for i, tt := range tests {
+		if err := tt.policy.Check(tt.from, tt.create); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// policyBlockChain is a testBlockChain with a local account policy.
+type policyBlockChain struct {
+	*testBlockChain
+	policy *AccountPolicy
+}
+
+func (bc *policyBlockChain) AccountPolicy() *AccountPolicy {
+	return bc.policy
+}
+
+// Tests that the transaction pool rejects the transactions of accounts that
+// aren't permitted to send them.
+func TestTransactionAccountPermissions(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	transacting, _ := crypto.GenerateKey()
+	readonly, _ := crypto.GenerateKey()
+
+	policy := &AccountPolicy{Accounts: map[common.Address]AccountRole{
+		crypto.PubkeyToAddress(transacting.PublicKey): RoleTransact,
+	}}
+	blockchain := &policyBlockChain{&testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}, policy}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	for _, key := range []*ecdsa.PrivateKey{transacting, readonly} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	if err := pool.AddRemote(transaction(0, big.NewInt(100000), readonly)); err != ErrReadOnlyAccount {
+		t.Errorf("read-only account error mismatch: have %v, want %v", err, ErrReadOnlyAccount)
+	}
+	create, _ := types.SignTx(types.NewContractCreation(0, new(big.Int), big.NewInt(100000), big.NewInt(1), nil), types.HomesteadSigner{}, transacting)
+	if err := pool.AddRemote(create); err != ErrDeployNotPermitted {
+		t.Errorf("contract creation error mismatch: have %v, want %v", err, ErrDeployNotPermitted)
+	}
+	if err := pool.AddRemote(transaction(0, big.NewInt(100000), transacting)); err != nil {
+		t.Errorf("failed to add permitted transaction: %v", err)
+	}
+}
+
+// Tests that nodes with different local account policies import the same
+// blocks, as only the account permissions of the chain are enforced on them.
+func TestInsertChainAccountPolicies(t *testing.T) {
+	var (
+		db, _   = bxmdb.NewMemDatabase()
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: big.NewInt(1000000000)}}}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, db, 1, func(i int, block *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{1}, new(big.Int), big.NewInt(21000), new(big.Int), nil), types.HomesteadSigner{}, key)
+		block.AddTx(tx)
+		tx, _ = types.SignTx(types.NewContractCreation(block.TxNonce(address), new(big.Int), big.NewInt(100000), new(big.Int), nil), types.HomesteadSigner{}, key)
+		block.AddTx(tx)
+	})
+	policies := []*AccountPolicy{
+		nil,
+		{Default: RoleReadOnly},
+		{Accounts: map[common.Address]AccountRole{address: RoleTransact}},
+	}
+	for i, policy := range policies {
+		db, _ := bxmdb.NewMemDatabase()
+		gspec.MustCommit(db)
+
+		blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+		blockchain.SetAccountPolicy(policy)
+		if _, err := blockchain.InsertChain(blocks); err != nil {
+			t.Errorf("policy %d: failed to insert block: %v", i, err)
+		} else if head := blockchain.CurrentBlock().Hash(); head != blocks[0].Hash() {
+			t.Errorf("policy %d: head mismatch: have %x, want %x", i, head, blocks[0].Hash())
+		}
+		blockchain.Stop()
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/metrics"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rlp"
 	"github.com/InsighterInc/bxmp/trie"
 	"github.com/hashicorp/golang-lru"
@@ -114,6 +115,8 @@ type BlockChain struct {
 	validator Validator // block and state validator interface
 	vmConfig  vm.Config
 
+	accountPolicy *AccountPolicy // Local policy restricting the transactions of the accounts
+
 	badBlocks *lru.Cache // Bad block cache
 
 	privateStateCache state.Database // Private state database to reuse between imports (contains state cache)
@@ -385,6 +388,21 @@ func (bc *BlockChain) Validator() Validator {
 	return bc.validator
 }
 
+// SetAccountPolicy sets the local policy restricting the transactions the
+// accounts may send, in addition to the account permissions of the chain.
+func (bc *BlockChain) SetAccountPolicy(policy *AccountPolicy) {
+	bc.procmu.Lock()
+	defer bc.procmu.Unlock()
+	bc.accountPolicy = policy
+}
+
+// AccountPolicy returns the local account policy, nil if there is none.
+func (bc *BlockChain) AccountPolicy() *AccountPolicy {
+	bc.procmu.RLock()
+	defer bc.procmu.RUnlock()
+	return bc.accountPolicy
+}
+
 // Processor returns the current processor.
 func (bc *BlockChain) Processor() Processor {
 	bc.procmu.RLock()
@@ -1023,6 +1041,14 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		if err := WritePrivateStateRoot(bc.chainDb, block.Root(), privateStateRoot); err != nil {
 			return i, events, coalescedLogs, err
 		}
+		if err := WritePrivateBlockBloom(bc.chainDb, block.Hash(), block.NumberU64(), privateReceipts); err != nil {
+			return i, events, coalescedLogs, err
+		}
+		if err := WriteReceivedPrivateTxs(bc.chainDb, bc.vmConfig.PrivateTxManager, block.Transactions()); err != nil {
+			// The block is valid and already processed, only the bookkeeping
+			// of the received private transactions is incomplete.
+			log.Error("Failed writing received private transactions", "number", block.Number(), "hash", block.Hash(), "err", err)
+		}
 		// /BitMED
 
 		allReceipts := append(receipts, privateReceipts...)
@@ -1404,6 +1430,9 @@ func (bc *BlockChain) GetHeaderByNumber(number uint64) *types.Header {
 // Config retrieves the blockchain's chain configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.config }
 
+// GetVMConfig returns the block chain VM config.
+func (bc *BlockChain) GetVMConfig() *vm.Config { return &bc.vmConfig }
+
 // Engine retrieves the blockchain's consensus engine.
 func (bc *BlockChain) Engine() consensus.Engine { return bc.engine }
 
@@ -1431,3 +1460,30 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
 }
+
+// WriteReceivedPrivateTxs marks the private transactions whose payload the
+// private transaction manager holds, and whose parties aren't known already, as
+// received by this node.
+func WriteReceivedPrivateTxs(db bxmdb.Database, ptm private.PrivateTransactionManager, txs types.Transactions) error {
+	if ptm == nil {
+		return nil
+	}
+	for _, tx := range txs {
+		if !tx.IsPrivate() || GetPrivateTxParties(db, tx.Hash()) != nil {
+			continue
+		}
+		// The payload was resolved while processing the transaction, so this
+		// is served from the cache of the private transaction manager
+		payload, err := ptm.Receive(tx.Data())
+		if err != nil {
+			return err
+		}
+		if len(payload) == 0 {
+			continue
+		}
+		if err := WritePrivateTxParties(db, tx.Hash(), &PrivateTxParties{Received: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 )
 
 // callHelper makes it easier to do proper calls and use the state transition object.
@@ -25,6 +26,9 @@ type callHelper struct {
 	gp     *GasPool
 
 	PrivateState, PublicState *state.StateDB
+
+	// PrivateTxManager resolves the payloads of private calls
+	PrivateTxManager private.PrivateTransactionManager
 }
 
 // TxNonce returns the pending nonce
@@ -35,6 +39,15 @@ func (cg *callHelper) TxNonce(addr common.Address) uint64 {
 // MakeCall makes does a call to the recipient using the given input. It can switch between private and public
 // by setting the private boolean flag. It returns an error if the call failed.
 func (cg *callHelper) MakeCall(private bool, key *ecdsa.PrivateKey, to common.Address, input []byte) error {
+	return cg.makeTx(private, key, &to, input)
+}
+
+// MakeCreate deploys a contract with the given init code, like MakeCall.
+func (cg *callHelper) MakeCreate(private bool, key *ecdsa.PrivateKey, code []byte) error {
+	return cg.makeTx(private, key, nil, code)
+}
+
+func (cg *callHelper) makeTx(private bool, key *ecdsa.PrivateKey, to *common.Address, input []byte) error {
 	var (
 		from = crypto.PubkeyToAddress(key.PublicKey)
 		err  error
@@ -47,15 +60,15 @@ func (cg *callHelper) MakeCall(private bool, key *ecdsa.PrivateKey, to common.Ad
 	cg.header.GasLimit = new(big.Int).SetUint64(4700000)
 
 	signer := types.MakeSigner(params.BitmedTestChainConfig, cg.header.Number)
-	tx, err := types.SignTx(types.NewTransaction(cg.TxNonce(from), to, new(big.Int), big.NewInt(1000000), new(big.Int), input), signer, key)
-	if err != nil {
-		return err
+	tx := types.NewContractCreation(cg.TxNonce(from), new(big.Int), big.NewInt(1000000), new(big.Int), input)
+	if to != nil {
+		tx = types.NewTransaction(cg.TxNonce(from), *to, new(big.Int), big.NewInt(1000000), new(big.Int), input)
 	}
-	defer func() { cg.nonces[from]++ }()
-	msg, err := tx.AsMessage(signer)
+	tx, err = types.SignTx(tx, signer, key)
 	if err != nil {
 		return err
 	}
+	defer func() { cg.nonces[from]++ }()
 
 	publicState, privateState := cg.PublicState, cg.PrivateState
 	if !private {
@@ -63,11 +76,15 @@ func (cg *callHelper) MakeCall(private bool, key *ecdsa.PrivateKey, to common.Ad
 	} else {
 		tx.SetPrivate()
 	}
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		return err
+	}
 
 	// TODO(joel): can we just pass nil instead of bc?
 	bc, _ := NewBlockChain(cg.db, params.BitmedTestChainConfig, ethash.NewFaker(), vm.Config{})
 	context := NewEVMContext(msg, &cg.header, bc, &from)
-	vmenv := vm.NewEVM(context, publicState, privateState, params.BitmedTestChainConfig, vm.Config{})
+	vmenv := vm.NewEVM(context, publicState, privateState, params.BitmedTestChainConfig, vm.Config{PrivateTxManager: cg.PrivateTxManager})
 	_, _, _, err = ApplyMessage(vmenv, msg, cg.gp)
 	if err != nil {
 		return err
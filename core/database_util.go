@@ -26,11 +26,13 @@ import (
 
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/metrics"
 	"github.com/InsighterInc/bxmp/params"
 	"github.com/InsighterInc/bxmp/rlp"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 // DatabaseReader wraps the Get method of a backing data store.
@@ -43,6 +45,11 @@ type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
 
+// DatabaseIteratee wraps the NewIteratorWithPrefix method of a backing data store.
+type DatabaseIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
 var (
 	headHeaderKey = []byte("LastHeader")
 	headBlockKey  = []byte("LastBlock")
@@ -76,9 +83,22 @@ var (
 	privateRootPrefix          = []byte("P")
 	privateblockReceiptsPrefix = []byte("Pr") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 	privateReceiptPrefix       = []byte("Prs")
-	privateBloomPrefix         = []byte("Pb")
+	privateBloomPrefix         = []byte("Pb") // privateBloomPrefix + num (uint64 big endian) + hash -> private bloom
+	privatePayloadPrefix       = []byte("Pd") // privatePayloadPrefix + payload digest -> private transaction parties
+	privateTxPartiesPrefix     = []byte("Pt") // privateTxPartiesPrefix + hash -> private transaction parties
+	privatePartyTxsPrefix      = []byte("Pk") // privatePartyTxsPrefix + keccak256(key) + hash -> nothing
 )
 
+// PrivateTxParties are the private transaction manager keys a private
+// transaction was exchanged between, as far as the node knows them. The parties
+// of a transaction received from another node aren't disclosed by the private
+// transaction manager, only the fact that it was received.
+type PrivateTxParties struct {
+	PrivateFrom string   `json:"privateFrom"`
+	PrivateFor  []string `json:"privateFor"`
+	Received    bool     `json:"received"`
+}
+
 // txLookupEntry is a positional metadata to help looking up the data content of
 // a transaction or receipt given only its hash.
 type txLookupEntry struct {
@@ -253,9 +273,26 @@ func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.
 	for i, receipt := range storageReceipts {
 		receipts[i] = (*types.Receipt)(receipt)
 	}
+	markPrivateReceipts(receipts)
 	return receipts
 }
 
+// markPrivateReceipts flags the logs of the private receipts stored along with
+// the public ones of a block. Every transaction has a public receipt, and the
+// private receipts follow them, so they are the ones repeating a transaction.
+func markPrivateReceipts(receipts types.Receipts) {
+	seen := make(map[common.Hash]bool, len(receipts))
+	for _, receipt := range receipts {
+		if !seen[receipt.TxHash] {
+			seen[receipt.TxHash] = true
+			continue
+		}
+		for _, l := range receipt.Logs {
+			l.Private = true
+		}
+	}
+}
+
 // GetTxLookupEntry retrieves the positional metadata associated with a transaction
 // hash to allow retrieving the transaction or receipt by hash.
 func GetTxLookupEntry(db DatabaseReader, hash common.Hash) (common.Hash, uint64, uint64) {
@@ -335,6 +372,23 @@ func GetReceipt(db DatabaseReader, hash common.Hash) (*types.Receipt, common.Has
 	return (*types.Receipt)(&receipt), common.Hash{}, 0, 0
 }
 
+// GetPrivateReceipt retrieves the private receipt of a transaction, stored
+// after the public receipts of its block, along with the transaction's
+// positional metadata. It returns nil if the transaction isn't private.
+func GetPrivateReceipt(db DatabaseReader, hash common.Hash) (*types.Receipt, common.Hash, uint64, uint64) {
+	blockHash, blockNumber, receiptIndex := GetTxLookupEntry(db, hash)
+	if blockHash == (common.Hash{}) {
+		return nil, common.Hash{}, 0, 0
+	}
+	receipts := GetBlockReceipts(db, blockHash, blockNumber)
+	for i := int(receiptIndex) + 1; i < len(receipts); i++ {
+		if receipts[i].TxHash == hash {
+			return receipts[i], blockHash, blockNumber, receiptIndex
+		}
+	}
+	return nil, common.Hash{}, 0, 0
+}
+
 // GetBloomBits retrieves the compressed bloom bit vector belonging to the given
 // section and bit index from the.
 func GetBloomBits(db DatabaseReader, bit uint, section uint64, head common.Hash) []byte {
@@ -523,6 +577,7 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteBlockReceipts(db, hash, number)
+	DeletePrivateBlockBloom(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -533,6 +588,11 @@ func DeleteBlockReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	db.Delete(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
 }
 
+// DeletePrivateBlockBloom removes the private bloom associated with a block hash.
+func DeletePrivateBlockBloom(db DatabaseDeleter, hash common.Hash, number uint64) {
+	db.Delete(append(append(privateBloomPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+}
+
 // DeleteTxLookupEntry removes all transaction data associated with a hash.
 func DeleteTxLookupEntry(db DatabaseDeleter, hash common.Hash) {
 	db.Delete(append(lookupPrefix, hash.Bytes()...))
@@ -646,18 +706,107 @@ func WritePrivateStateRoot(db bxmdb.Database, blockRoot, root common.Hash) error
 	return db.Put(append(privateRootPrefix, blockRoot[:]...), root[:])
 }
 
-// WritePrivateBlockBloom creates a bloom filter for the given receipts and saves it to the database
-// with the number given as identifier (i.e. block number).
-func WritePrivateBlockBloom(db bxmdb.Database, number uint64, receipts types.Receipts) error {
+// WritePrivateBlockBloom creates a bloom filter for the given private receipts and saves it to the
+// database with the block hash and number given as identifier, like the receipts themselves.
+func WritePrivateBlockBloom(db bxmdb.Database, hash common.Hash, number uint64, receipts types.Receipts) error {
 	rbloom := types.CreateBloom(receipts)
-	return db.Put(append(privateBloomPrefix, encodeBlockNumber(number)...), rbloom[:])
+	return db.Put(append(append(privateBloomPrefix, encodeBlockNumber(number)...), hash.Bytes()...), rbloom[:])
 }
 
-// GetPrivateBlockBloom retrieves the private bloom associated with the given number.
-func GetPrivateBlockBloom(db bxmdb.Database, number uint64) (bloom types.Bloom) {
-	data, _ := db.Get(append(privateBloomPrefix, encodeBlockNumber(number)...))
+// GetPrivateBlockBloom retrieves the private bloom associated with the given block hash and number.
+func GetPrivateBlockBloom(db DatabaseReader, hash common.Hash, number uint64) (bloom types.Bloom) {
+	data, _ := db.Get(append(append(privateBloomPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
 	if len(data) > 0 {
 		bloom = types.BytesToBloom(data)
 	}
 	return bloom
 }
+
+// WritePrivatePayloadParties stores the parties a private payload was sent to,
+// until the transaction carrying its digest is submitted.
+func WritePrivatePayloadParties(db bxmdb.Putter, digest []byte, parties *PrivateTxParties) error {
+	data, err := rlp.EncodeToBytes(parties)
+	if err != nil {
+		return err
+	}
+	return db.Put(append(privatePayloadPrefix, digest...), data)
+}
+
+// GetPrivatePayloadParties retrieves the parties a private payload was sent to,
+// or nil if the payload wasn't sent by this node.
+func GetPrivatePayloadParties(db DatabaseReader, digest []byte) *PrivateTxParties {
+	return getPrivateTxParties(db, append(privatePayloadPrefix, digest...))
+}
+
+// DeletePrivatePayloadParties removes the parties a private payload was sent to.
+func DeletePrivatePayloadParties(db DatabaseDeleter, digest []byte) {
+	db.Delete(append(privatePayloadPrefix, digest...))
+}
+
+// WritePrivateTxParties stores the parties of a private transaction and an
+// index entry for the transaction under every key involved.
+func WritePrivateTxParties(db bxmdb.Putter, hash common.Hash, parties *PrivateTxParties) error {
+	data, err := rlp.EncodeToBytes(parties)
+	if err != nil {
+		return err
+	}
+	if err := db.Put(append(privateTxPartiesPrefix, hash[:]...), data); err != nil {
+		return err
+	}
+	keys := append([]string{parties.PrivateFrom}, parties.PrivateFor...)
+	for i, key := range keys {
+		if key == "" || containsString(keys[:i], key) {
+			continue
+		}
+		if err := db.Put(append(privatePartyTxsKey(key), hash[:]...), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPrivateTxParties retrieves the parties of a private transaction, or nil if
+// the transaction wasn't sent or received by this node.
+func GetPrivateTxParties(db DatabaseReader, hash common.Hash) *PrivateTxParties {
+	return getPrivateTxParties(db, append(privateTxPartiesPrefix, hash[:]...))
+}
+
+// GetPrivatePartyTxs retrieves the hashes of the private transactions exchanged
+// with the given private transaction manager key, ordered by hash.
+func GetPrivatePartyTxs(db DatabaseIteratee, key string) []common.Hash {
+	prefix := privatePartyTxsKey(key)
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() {
+		hashes = append(hashes, common.BytesToHash(it.Key()[len(prefix):]))
+	}
+	return hashes
+}
+
+func getPrivateTxParties(db DatabaseReader, key []byte) *PrivateTxParties {
+	data, _ := db.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	parties := new(PrivateTxParties)
+	if err := rlp.DecodeBytes(data, parties); err != nil {
+		log.Error("Invalid private transaction parties RLP", "key", common.ToHex(key), "err", err)
+		return nil
+	}
+	return parties
+}
+
+func privatePartyTxsKey(key string) []byte {
+	return append(privatePartyTxsPrefix, crypto.Keccak256([]byte(key))...)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,7 @@ package core
 import (
 	"bytes"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/InsighterInc/bxmp/common"
@@ -331,6 +332,57 @@ func TestLookupStorage(t *testing.T) {
 	}
 }
 
+// Tests that the parties of private transactions can be stored and retrieved,
+// and listed per key.
+func TestPrivateTxPartiesStorage(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+
+	digest := []byte("private payload digest")
+	parties := &PrivateTxParties{PrivateFrom: "A", PrivateFor: []string{"B", "C", "B"}}
+	if entry := GetPrivatePayloadParties(db, digest); entry != nil {
+		t.Fatalf("non existent payload parties returned: %v", entry)
+	}
+	if err := WritePrivatePayloadParties(db, digest, parties); err != nil {
+		t.Fatalf("failed to write payload parties: %v", err)
+	}
+	if entry := GetPrivatePayloadParties(db, digest); !reflect.DeepEqual(entry, parties) {
+		t.Fatalf("payload parties mismatch: have %v, want %v", entry, parties)
+	}
+	hash1, hash2 := common.Hash{1}, common.Hash{2}
+	if entry := GetPrivateTxParties(db, hash1); entry != nil {
+		t.Fatalf("non existent transaction parties returned: %v", entry)
+	}
+	if err := WritePrivateTxParties(db, hash1, parties); err != nil {
+		t.Fatalf("failed to write transaction parties: %v", err)
+	}
+	if err := WritePrivateTxParties(db, hash2, &PrivateTxParties{PrivateFor: []string{"C"}}); err != nil {
+		t.Fatalf("failed to write transaction parties: %v", err)
+	}
+	// Storing a transaction again doesn't list it twice
+	if err := WritePrivateTxParties(db, hash1, parties); err != nil {
+		t.Fatalf("failed to write transaction parties: %v", err)
+	}
+	if entry := GetPrivateTxParties(db, hash1); !reflect.DeepEqual(entry, parties) {
+		t.Fatalf("transaction parties mismatch: have %v, want %v", entry, parties)
+	}
+	DeletePrivatePayloadParties(db, digest)
+	if entry := GetPrivatePayloadParties(db, digest); entry != nil {
+		t.Fatalf("deleted payload parties returned: %v", entry)
+	}
+	tests := map[string][]common.Hash{
+		"A": {hash1},
+		"B": {hash1},
+		"C": {hash1, hash2},
+		"D": nil,
+		"":  nil,
+	}
+	for key, want := range tests {
+		if have := GetPrivatePartyTxs(db, key); !reflect.DeepEqual(have, want) {
+			t.Errorf("key %q: transactions mismatch: have %x, want %x", key, have, want)
+		}
+	}
+}
+
 // Tests that receipts associated with a single block can be stored and retrieved.
 func TestBlockReceiptStorage(t *testing.T) {
 	db, _ := bxmdb.NewMemDatabase()
@@ -386,3 +438,76 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that the private receipts stored after the public ones of a block are
+// retrieved by the hash of their transaction.
+func TestPrivateReceiptStorage(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+
+	tx1 := types.NewTransaction(1, common.BytesToAddress([]byte{0x11}), big.NewInt(111), big.NewInt(1111), big.NewInt(11111), []byte{0x11, 0x11, 0x11})
+	tx2 := types.NewTransaction(2, common.BytesToAddress([]byte{0x22}), big.NewInt(222), big.NewInt(2222), big.NewInt(22222), []byte{0x22, 0x22, 0x22})
+	block := types.NewBlock(&types.Header{Number: big.NewInt(314)}, []*types.Transaction{tx1, tx2}, nil, nil)
+
+	public1 := &types.Receipt{CumulativeGasUsed: big.NewInt(1), TxHash: tx1.Hash(), GasUsed: big.NewInt(1)}
+	public2 := &types.Receipt{CumulativeGasUsed: big.NewInt(2), TxHash: tx2.Hash(), GasUsed: big.NewInt(1)}
+	private2 := &types.Receipt{
+		CumulativeGasUsed: big.NewInt(2),
+		Logs:              []*types.Log{{Address: common.BytesToAddress([]byte{0x02, 0x22})}},
+		TxHash:            tx2.Hash(),
+		GasUsed:           big.NewInt(1),
+	}
+	if err := WriteBlock(db, block); err != nil {
+		t.Fatalf("failed to write block contents: %v", err)
+	}
+	if err := WriteTxLookupEntries(db, block); err != nil {
+		t.Fatalf("failed to write transactions: %v", err)
+	}
+	if err := WriteBlockReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{public1, public2, private2}); err != nil {
+		t.Fatalf("failed to write block receipts: %v", err)
+	}
+	if receipt, _, _, _ := GetPrivateReceipt(db, tx1.Hash()); receipt != nil {
+		t.Fatalf("private receipt returned for public transaction: %v", receipt)
+	}
+	receipt, hash, number, index := GetPrivateReceipt(db, tx2.Hash())
+	if receipt == nil {
+		t.Fatalf("private receipt not found")
+	}
+	if hash != block.Hash() || number != block.NumberU64() || index != 1 {
+		t.Fatalf("positional metadata mismatch: have %x/%d/%d, want %x/%d/%d", hash, number, index, block.Hash(), block.NumberU64(), 1)
+	}
+	if len(receipt.Logs) != 1 || !receipt.Logs[0].Private {
+		t.Fatalf("private receipt logs mismatch: %v", receipt.Logs)
+	}
+	if public, _, _, _ := GetReceipt(db, tx2.Hash()); public == nil || len(public.Logs) != 0 {
+		t.Fatalf("public receipt mismatch: %v", public)
+	}
+}
+
+// Tests that the private blooms of competing blocks at the same height don't
+// overwrite each other.
+func TestPrivateBlockBloomStorage(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+
+	canonical := types.NewBlock(&types.Header{Number: big.NewInt(314), Extra: []byte("canonical")}, nil, nil, nil)
+	side := types.NewBlock(&types.Header{Number: big.NewInt(314), Extra: []byte("side")}, nil, nil, nil)
+
+	receipt := &types.Receipt{Logs: []*types.Log{{Address: common.BytesToAddress([]byte{0x03, 0x33})}}}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	if err := WritePrivateBlockBloom(db, canonical.Hash(), canonical.NumberU64(), types.Receipts{receipt}); err != nil {
+		t.Fatalf("failed to write private bloom: %v", err)
+	}
+	if err := WritePrivateBlockBloom(db, side.Hash(), side.NumberU64(), nil); err != nil {
+		t.Fatalf("failed to write private bloom: %v", err)
+	}
+	if bloom := GetPrivateBlockBloom(db, canonical.Hash(), canonical.NumberU64()); bloom != receipt.Bloom {
+		t.Fatalf("canonical private bloom mismatch: have %x, want %x", bloom, receipt.Bloom)
+	}
+	if bloom := GetPrivateBlockBloom(db, side.Hash(), side.NumberU64()); bloom != (types.Bloom{}) {
+		t.Fatalf("side private bloom mismatch: have %x, want empty", bloom)
+	}
+	DeleteBlock(db, canonical.Hash(), canonical.NumberU64())
+	if bloom := GetPrivateBlockBloom(db, canonical.Hash(), canonical.NumberU64()); bloom != (types.Bloom{}) {
+		t.Fatalf("deleted private bloom returned: %x", bloom)
+	}
+}
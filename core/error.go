@@ -32,4 +32,17 @@ var (
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
+
+	// ErrPrivatePayloadUnavailable is returned if the payload of a private
+	// transaction could not be resolved by the private transaction manager.
+	// Not being a party to the transaction is not reported as this error.
+	ErrPrivatePayloadUnavailable = errors.New("private payload unavailable")
+
+	// ErrReadOnlyAccount is returned if the sender of a transaction isn't
+	// permitted to send transactions.
+	ErrReadOnlyAccount = errors.New("account not permitted to transact")
+
+	// ErrDeployNotPermitted is returned if the sender of a contract creation
+	// isn't permitted to deploy contracts.
+	ErrDeployNotPermitted = errors.New("account not permitted to deploy contracts")
 )
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -12,10 +13,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/private"
-	"github.com/InsighterInc/bxmp/private/constellation"
 )
 
 // callmsg is the message type used for call transactions in the private state test
@@ -84,29 +86,29 @@ var constellationCfgTemplate = template.Must(template.New("t").Parse(`
 	storagePath = "{{.RootDir}}/qdata/constellation1"
 `))
 
-func runConstellation() (*osExec.Cmd, error) {
+func runConstellation() (*osExec.Cmd, private.PrivateTransactionManager, error) {
 	dir, err := ioutil.TempDir("", "TestPrivateTxConstellationData")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer os.RemoveAll(dir)
 	here, err := os.Getwd()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err = os.MkdirAll(path.Join(dir, "qdata"), 0755); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err = os.Symlink(path.Join(here, "constellation-test-keys"), path.Join(dir, "keys")); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	cfgFile, err := os.Create(path.Join(dir, "constellation.cfg"))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	err = constellationCfgTemplate.Execute(cfgFile, map[string]string{"RootDir": dir})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	constellationCmd := osExec.Command("constellation-node", cfgFile.Name())
 	var stdout, stderr bytes.Buffer
@@ -120,10 +122,14 @@ func runConstellation() (*osExec.Cmd, error) {
 	time.Sleep(1 * time.Second)
 	if constellationErr != nil {
 		fmt.Println(stdout.String() + stderr.String())
-		return nil, constellationErr
+		return nil, nil, constellationErr
 	}
-	private.P = constellation.MustNew(cfgFile.Name())
-	return constellationCmd, nil
+	ptm, err := private.New(private.ConstellationBackend, cfgFile.Name())
+	if err != nil {
+		constellationCmd.Process.Kill()
+		return nil, nil, err
+	}
+	return constellationCmd, ptm, nil
 }
 
 // 600a600055600060006001a1
@@ -137,18 +143,145 @@ func runConstellation() (*osExec.Cmd, error) {
 //
 // Store then log
 func TestPrivateTransaction(t *testing.T) {
+	ptm, err := private.New(private.MemoryBackend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPrivateTransaction(t, ptm)
+}
+
+func TestPrivateTransactionConstellation(t *testing.T) {
+	if _, err := osExec.LookPath("constellation-node"); err != nil {
+		t.Skip("constellation-node not installed")
+	}
+	constellationCmd, ptm, err := runConstellation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer constellationCmd.Process.Kill()
+
+	testPrivateTransaction(t, ptm)
+}
+
+// failingPrivateTxManager is a PrivateTransactionManager whose enclave can't
+// be reached.
+type failingPrivateTxManager struct{}
+
+func (failingPrivateTxManager) Send(data []byte, from string, to []string) ([]byte, error) {
+	return nil, errors.New("enclave unreachable")
+}
+
+func (failingPrivateTxManager) Receive(data []byte) ([]byte, error) {
+	return nil, errors.New("enclave unreachable")
+}
+
+// Tests that a private transaction is refused rather than executed without its
+// payload if the private transaction manager fails.
+func TestPrivateTransactionUnavailablePayload(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		helper = MakeCallHelper()
+	)
+	helper.PrivateTxManager = failingPrivateTxManager{}
+
+	prvContractAddr := common.Address{1}
+	helper.PrivateState.SetCode(prvContractAddr, common.Hex2Bytes("600a600055"))
+
+	err := helper.MakeCall(true, key, prvContractAddr, []byte{0x01})
+	if err != ErrPrivatePayloadUnavailable {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrPrivatePayloadUnavailable)
+	}
+	if stateEntry := helper.PrivateState.GetState(prvContractAddr, common.Hash{}).Big(); stateEntry.Sign() != 0 {
+		t.Error("expected private state to be untouched, got", stateEntry)
+	}
+}
+
+// partyPrivateTxManager holds the payloads of the private transactions this
+// node is a party to, and returns no payload for the others.
+type partyPrivateTxManager map[string][]byte
+
+func (m partyPrivateTxManager) Send(data []byte, from string, to []string) ([]byte, error) {
+	digest := crypto.Keccak256(data)
+	m[string(digest)] = data
+	return digest, nil
+}
+
+func (m partyPrivateTxManager) Receive(data []byte) ([]byte, error) {
+	return m[string(data)], nil
+}
+
+// Tests that private transactions are executed on the nodes that are a party to
+// them, while the others only increment the public nonce of the sender.
+func TestPrivateTransactionParties(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		from   = crypto.PubkeyToAddress(key.PublicKey)
+
+		callPayload = common.LeftPadBytes([]byte{0x0a}, 32)
+		// 600a600055600160005360016000f3: store 0x0a at slot 0, deploy 0x01
+		createPayload = common.Hex2Bytes("600a600055600160005360016000f3")
+	)
+	ptm := make(partyPrivateTxManager)
+	callDigest, _ := ptm.Send(callPayload, "A", []string{"B"})
+	createDigest, _ := ptm.Send(createPayload, "A", []string{"B"})
+
+	for _, party := range []bool{true, false} {
+		helper := MakeCallHelper()
+		helper.PrivateTxManager = ptm
+		if !party {
+			helper.PrivateTxManager = make(partyPrivateTxManager)
+		}
+		prvContractAddr := common.Address{1}
+		helper.PrivateState.SetCode(prvContractAddr, common.Hex2Bytes("600035600055")) // CALLDATALOAD(0) at slot 0
+		privateRoot := helper.PrivateState.IntermediateRoot(false)
+
+		if err := helper.MakeCall(true, key, prvContractAddr, callDigest); err != nil {
+			t.Fatalf("party %v: private call failed: %v", party, err)
+		}
+		if err := helper.MakeCreate(true, key, createDigest); err != nil {
+			t.Fatalf("party %v: private creation failed: %v", party, err)
+		}
+		if nonce := helper.PublicState.GetNonce(from); nonce != 2 {
+			t.Errorf("party %v: public nonce mismatch: have %d, want 2", party, nonce)
+		}
+		if nonce := helper.PrivateState.GetNonce(from); nonce != 0 {
+			t.Errorf("party %v: private nonce mismatch: have %d, want 0", party, nonce)
+		}
+
+		// The creation derives the contract address from the public nonce.
+		contractAddr := crypto.CreateAddress(from, 1)
+		if !party {
+			if root := helper.PrivateState.IntermediateRoot(false); root != privateRoot {
+				t.Errorf("non-party private state modified: root %x, want %x", root, privateRoot)
+			}
+			if helper.PrivateState.Exist(contractAddr) {
+				t.Error("didn't expect private contract to exist on non-party private state")
+			}
+			continue
+		}
+		if stateEntry := helper.PrivateState.GetState(prvContractAddr, common.Hash{}).Big(); stateEntry.Cmp(big.NewInt(10)) != 0 {
+			t.Error("expected state to have 10, got", stateEntry)
+		}
+		if code := helper.PrivateState.GetCode(contractAddr); !bytes.Equal(code, []byte{0x01}) {
+			t.Errorf("private contract code mismatch: have %x, want 01", code)
+		}
+		if stateEntry := helper.PrivateState.GetState(contractAddr, common.Hash{}).Big(); stateEntry.Cmp(big.NewInt(10)) != 0 {
+			t.Error("expected private contract state to have 10, got", stateEntry)
+		}
+		if helper.PublicState.Exist(contractAddr) {
+			t.Error("didn't expect private contract to exist on public state")
+		}
+	}
+}
+
+func testPrivateTransaction(t *testing.T, ptm private.PrivateTransactionManager) {
 	var (
 		key, _       = crypto.GenerateKey()
 		helper       = MakeCallHelper()
 		privateState = helper.PrivateState
 		publicState  = helper.PublicState
 	)
-
-	constellationCmd, err := runConstellation()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer constellationCmd.Process.Kill()
+	helper.PrivateTxManager = ptm
 
 	prvContractAddr := common.Address{1}
 	pubContractAddr := common.Address{2}
@@ -162,7 +295,7 @@ func TestPrivateTransaction(t *testing.T) {
 	}
 
 	// Private transaction 1
-	err = helper.MakeCall(true, key, prvContractAddr, nil)
+	err := helper.MakeCall(true, key, prvContractAddr, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -207,3 +340,47 @@ func TestPrivateTransaction(t *testing.T) {
 		t.Error("didn't expect public contract address to exist on private state")
 	}
 }
+
+// Tests that the private transactions whose payload is held by the private
+// transaction manager are marked as received, unless their parties are known.
+func TestWriteReceivedPrivateTxs(t *testing.T) {
+	ptm, err := private.New(private.MemoryBackend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := bxmdb.NewMemDatabase()
+
+	newTx := func(nonce uint64, data []byte, isPrivate bool) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{1}, new(big.Int), big.NewInt(21000), new(big.Int), data)
+		if isPrivate {
+			tx.SetPrivate()
+		}
+		return tx
+	}
+	digest1, _ := ptm.Send([]byte("payload 1"), "", []string{"B"})
+	digest2, _ := ptm.Send([]byte("payload 2"), "", []string{"B"})
+
+	received := newTx(0, digest1, true)
+	sent := newTx(1, digest2, true)
+	unknown := newTx(2, []byte("unknown digest"), true)
+	public := newTx(3, digest1, false)
+
+	sentParties := &PrivateTxParties{PrivateFor: []string{"B"}}
+	if err := WritePrivateTxParties(db, sent.Hash(), sentParties); err != nil {
+		t.Fatalf("failed to write transaction parties: %v", err)
+	}
+	if err := WriteReceivedPrivateTxs(db, ptm, types.Transactions{received, sent, unknown, public}); err != nil {
+		t.Fatalf("failed to write received transactions: %v", err)
+	}
+	if parties := GetPrivateTxParties(db, received.Hash()); parties == nil || !parties.Received {
+		t.Errorf("received transaction not marked: %v", parties)
+	}
+	if parties := GetPrivateTxParties(db, sent.Hash()); parties == nil || parties.Received {
+		t.Errorf("sent transaction parties overwritten: %v", parties)
+	}
+	for _, tx := range []*types.Transaction{unknown, public} {
+		if parties := GetPrivateTxParties(db, tx.Hash()); parties != nil {
+			t.Errorf("tx %x: unexpected parties %v", tx.Hash(), parties)
+		}
+	}
+}
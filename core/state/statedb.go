@@ -18,6 +18,7 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -31,6 +32,8 @@ import (
 	"github.com/InsighterInc/bxmp/trie"
 )
 
+var errNoProofs = errors.New("state trie does not support merkle proofs")
+
 type revision struct {
 	id           int
 	journalIndex int
@@ -247,6 +250,35 @@ func (self *StateDB) StorageTrie(a common.Address) Trie {
 	return cpy.updateTrie(self.db)
 }
 
+// prover is implemented by tries able to construct merkle proofs.
+type prover interface {
+	Prove(key []byte) []rlp.RawValue
+}
+
+// GetProof returns the merkle proof of the account at address a in the state
+// trie. The proof is keyed by the hash of the address.
+func (self *StateDB) GetProof(a common.Address) ([]rlp.RawValue, error) {
+	tr, ok := self.trie.(prover)
+	if !ok {
+		return nil, errNoProofs
+	}
+	return tr.Prove(a.Bytes()), nil
+}
+
+// GetStorageProof returns the merkle proof of the storage slot key of the
+// account at address a. The proof is keyed by the hash of the slot.
+func (self *StateDB) GetStorageProof(a common.Address, key common.Hash) ([]rlp.RawValue, error) {
+	st := self.StorageTrie(a)
+	if st == nil {
+		return nil, fmt.Errorf("account %x doesn't exist", a)
+	}
+	tr, ok := st.(prover)
+	if !ok {
+		return nil, errNoProofs
+	}
+	return tr.Prove(key.Bytes()), nil
+}
+
 func (self *StateDB) HasSuicided(addr common.Address) bool {
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
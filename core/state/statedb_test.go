@@ -32,7 +32,10 @@ import (
 
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/bxmdb"
+	"github.com/InsighterInc/bxmp/rlp"
+	"github.com/InsighterInc/bxmp/trie"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -117,6 +120,50 @@ func TestIntermediateLeaks(t *testing.T) {
 	}
 }
 
+// Tests that account and storage proofs verify against the committed roots.
+func TestStateProofs(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	addr := common.BytesToAddress([]byte{0x01})
+	slot, value := common.BytesToHash([]byte{0x02}), common.BytesToHash([]byte{0x03})
+	state.SetState(addr, slot, value)
+	for i := byte(0); i < 16; i++ {
+		state.SetBalance(common.BytesToAddress([]byte{0x10, i}), big.NewInt(int64(i)+1))
+	}
+	root, err := state.CommitTo(db, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, _ = New(root, NewDatabase(db))
+
+	accountProof, err := state.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	if _, err := trie.VerifyProof(root, crypto.Keccak256(addr.Bytes()), accountProof); err != nil {
+		t.Errorf("account proof failed to verify: %v", err)
+	}
+	storageProof, err := state.GetStorageProof(addr, slot)
+	if err != nil {
+		t.Fatalf("failed to prove storage: %v", err)
+	}
+	enc, err := trie.VerifyProof(state.StorageTrie(addr).Hash(), crypto.Keccak256(slot.Bytes()), storageProof)
+	if err != nil {
+		t.Fatalf("storage proof failed to verify: %v", err)
+	}
+	var have []byte
+	if err := rlp.DecodeBytes(enc, &have); err != nil {
+		t.Fatalf("failed to decode proven value: %v", err)
+	}
+	if common.BytesToHash(have) != value {
+		t.Errorf("proven value mismatch: have %x, want %x", have, value)
+	}
+	if _, err := state.GetStorageProof(common.Address{0xff}, slot); err == nil {
+		t.Error("expected error proving storage of missing account")
+	}
+}
+
 func TestSnapshotRandom(t *testing.T) {
 	config := &quick.Config{MaxCount: 1000}
 	err := quick.Check((*snapshotTest).run, config)
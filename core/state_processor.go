@@ -90,7 +90,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb, privateState *stat
 		}
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts)
+	if _, err := p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts); err != nil {
+		return nil, nil, nil, nil, err
+	}
 
 	return receipts, privateReceipts, allLogs, totalUsedGas, nil
 }
@@ -112,6 +114,11 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	// Only the account permissions of the chain are consensus, the local policy
+	// is enforced by the transaction pool and when filling blocks
+	if err := checkAccountPermission(config, nil, statedb, header, msg.From(), msg.To() == nil); err != nil {
+		return nil, nil, nil, err
+	}
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(msg, header, bc, author)
 	// Create a new environment which holds all relevant information
@@ -162,6 +169,9 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 		}
 
 		privateReceipt.Logs = privateState.GetLogs(tx.Hash())
+		for _, l := range privateReceipt.Logs {
+			l.Private = true
+		}
 		privateReceipt.Bloom = types.CreateBloom(types.Receipts{privateReceipt})
 	}
 
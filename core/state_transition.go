@@ -25,7 +25,6 @@ import (
 	"github.com/InsighterInc/bxmp/core/vm"
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/params"
-	"github.com/InsighterInc/bxmp/private"
 )
 
 var (
@@ -228,20 +227,27 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 	isBitmed := st.evm.ChainConfig().IsBitmed
 
 	var data []byte
-	isPrivate := false
+	isPrivate, notParty := false, false
 	publicState := st.state
 	if msg, ok := msg.(PrivateMessage); ok && isBitmed && msg.IsPrivate() {
 		isPrivate = true
-		data, err = private.P.Receive(st.data)
-		// Increment the public account nonce if:
-		// 1. Tx is private and *not* a participant of the group and either call or create
-		// 2. Tx is private we are part of the group and is a call
-		if err != nil || !contractCreation {
-			publicState.SetNonce(sender.Address(), publicState.GetNonce(sender.Address())+1)
+		if ptm := st.evm.PrivateTxManager(); ptm != nil {
+			if data, err = ptm.Receive(st.data); err != nil {
+				// Executing the transaction without its payload would make
+				// the private state of this node diverge, refuse it instead.
+				log.Error("Failed to resolve private transaction payload", "err", err)
+				return nil, nil, nil, false, ErrPrivatePayloadUnavailable
+			}
 		}
-
-		if err != nil {
-			return nil, new(big.Int), new(big.Int), false, nil
+		// Nodes which are not a party to the transaction can't resolve
+		// its payload.
+		notParty = len(st.data) > 0 && data == nil
+
+		// Increment the public account nonce if the tx is a call or this
+		// node is not a party to it, it is incremented by the creation
+		// otherwise.
+		if !contractCreation || notParty {
+			publicState.SetNonce(sender.Address(), publicState.GetNonce(sender.Address())+1)
 		}
 	} else {
 		data = st.data
@@ -264,9 +270,12 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 		// error.
 		vmerr error
 	)
-	if contractCreation {
+	switch {
+	case notParty:
+		// Leave the private state of this node untouched.
+	case contractCreation:
 		ret, _, st.gas, vmerr = evm.Create(sender, data, st.gas, st.value)
-	} else {
+	default:
 		// Increment the account nonce only if the transaction isn't private.
 		// If the transaction is private it has already been incremented on
 		// the public state.
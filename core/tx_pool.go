@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -80,6 +81,14 @@ var (
 	ErrOversizedData = errors.New("oversized data")
 
 	ErrInvalidGasPrice = errors.New("Gas price not 0")
+
+	// ErrAccountThrottled is returned if the sender of a transaction exceeds
+	// the transaction rate permitted per account.
+	ErrAccountThrottled = errors.New("account transaction rate exceeded")
+
+	// ErrAccountPoolShare is returned if the pool is full and the sender of a
+	// transaction already holds the largest share of it.
+	ErrAccountPoolShare = errors.New("account exceeds its transaction pool share")
 )
 
 var (
@@ -103,6 +112,15 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewCounter("txpool/invalid")
 	underpricedTxCounter = metrics.NewCounter("txpool/underpriced")
+
+	// Metrics for the per account quotas
+	throttledTxCounter    = metrics.NewCounter("txpool/throttled")       // Refused due to the account rate
+	quotaDiscardCounter   = metrics.NewCounter("txpool/quota/discard")   // Dropped or refused by a full pool over the account shares
+	throttledSendersGauge = metrics.NewGauge("txpool/throttled/senders") // Accounts currently held back by a quota
+
+	// Metrics for the pool sizes, updated with every status report
+	pendingGauge = metrics.NewGauge("txpool/pending")
+	queuedGauge  = metrics.NewGauge("txpool/queued")
 )
 
 // blockChain provides the state of blockchain and current gas limit to do
@@ -111,6 +129,7 @@ type blockChain interface {
 	CurrentBlock() *types.Block
 	GetBlock(hash common.Hash, number uint64) *types.Block
 	StateAt(root common.Hash) (*state.StateDB, *state.StateDB, error)
+	AccountPolicy() *AccountPolicy
 
 	SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription
 }
@@ -130,6 +149,10 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	AccountTxRate   uint64 // Maximum number of transactions admitted per second and account (0 = unlimited)
+	AccountGasQuota uint64 // Maximum gas limits of the transactions of an account included per quota window (0 = unlimited)
+	QuotaWindow     uint64 // Number of blocks the gas quota applies to
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -147,6 +170,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	QuotaWindow: 1,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -165,6 +190,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.QuotaWindow < 1 {
+		log.Warn("Sanitizing invalid txpool quota window", "provided", conf.QuotaWindow, "updated", DefaultTxPoolConfig.QuotaWindow)
+		conf.QuotaWindow = DefaultTxPoolConfig.QuotaWindow
+	}
 	return conf
 }
 
@@ -199,6 +228,7 @@ type TxPool struct {
 	beats   map[common.Address]time.Time       // Last heartbeat from each known account
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
+	quota   *txQuota                           // Throughput of the accounts against their quotas
 
 	wg sync.WaitGroup // for shutdown sync
 
@@ -226,6 +256,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
+	pool.quota = newTxQuota(&config)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
@@ -296,6 +327,9 @@ func (pool *TxPool) loop() {
 			stales := pool.priced.stales
 			pool.mu.RUnlock()
 
+			pendingGauge.Update(int64(pending))
+			queuedGauge.Update(int64(queued))
+
 			if pending != prevPending || queued != prevQueued || stales != prevStales {
 				log.Debug("Transaction pool status report", "executable", pending, "queued", queued, "stales", stales)
 				prevPending, prevQueued, prevStales = pending, queued, stales
@@ -403,6 +437,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
 
+	pool.quota.reset(pool.chain, pool.signer, newHead, time.Now())
+	throttledSendersGauge.Update(int64(pool.quota.count(time.Now())))
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	pool.addTxsLocked(reinject, false)
@@ -522,7 +559,22 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 
 	pending := make(map[common.Address]types.Transactions)
 	for addr, list := range pool.pending {
-		pending[addr] = list.Flatten()
+		txs := list.Flatten()
+		if !pool.locals.contains(addr) {
+			// Hold back the transactions exceeding the gas quota of the account
+			remaining := pool.quota.remaining(addr)
+			for i, tx := range txs {
+				gas := tx.Gas().Uint64()
+				if gas > remaining {
+					txs = txs[:i]
+					break
+				}
+				remaining -= gas
+			}
+		}
+		if len(txs) > 0 {
+			pending[addr] = txs
+		}
 	}
 	return pending, nil
 }
@@ -587,7 +639,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !isBitmed && tx.Gas().Cmp(intrGas) < 0 {
 		return ErrIntrinsicGas
 	}
-	return nil
+	// Ensure the sender is permitted to send the transaction in the next block
+	header := types.CopyHeader(pool.chain.CurrentBlock().Header())
+	header.Number.Add(header.Number, common.Big1)
+	return checkAccountPermission(pool.chainconfig, pool.chain.AccountPolicy(), pool.currentState, header, from, tx.To() == nil)
 }
 
 // add validates a transaction and inserts it into the non-executable queue for
@@ -611,24 +666,41 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// Refuse the transactions of accounts exceeding their rate, the rate is
+	// only charged once the transaction is accepted
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	limited := !local && !pool.locals.contains(from)
+	if limited && !pool.quota.allow(from, time.Now()) {
+		log.Trace("Discarding transaction over the account rate", "hash", hash, "from", from)
+		throttledTxCounter.Inc(1)
+		return false, ErrAccountThrottled
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
-		// If the new transaction is underpriced, don't accept it
-		if !pool.chainconfig.IsBitmed && pool.priced.Underpriced(tx, pool.locals) {
-			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
-			underpricedTxCounter.Inc(1)
-			return false, ErrUnderpriced
-		}
-		// New transaction is better than our worse ones, make room for it
-		drop := pool.priced.Discard(len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
-		for _, tx := range drop {
-			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
-			underpricedTxCounter.Inc(1)
-			pool.removeTx(tx.Hash())
+		if pool.chainconfig.IsBitmed {
+			// Prices are all zero, make room at the expense of the largest accounts
+			if err := pool.discardByShare(from, len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1)); err != nil {
+				log.Trace("Discarding transaction over the account pool share", "hash", hash, "from", from)
+				quotaDiscardCounter.Inc(1)
+				return false, err
+			}
+		} else {
+			// If the new transaction is underpriced, don't accept it
+			if pool.priced.Underpriced(tx, pool.locals) {
+				log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
+				underpricedTxCounter.Inc(1)
+				return false, ErrUnderpriced
+			}
+			// New transaction is better than our worse ones, make room for it
+			drop := pool.priced.Discard(len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
+			for _, tx := range drop {
+				log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
+				underpricedTxCounter.Inc(1)
+				pool.removeTx(tx.Hash())
+			}
 		}
 	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -645,6 +717,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		if limited {
+			pool.quota.charge(from, time.Now())
+		}
 
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 		return old != nil, nil
@@ -659,11 +734,84 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.locals.add(from)
 	}
 	pool.journalTx(from, tx)
+	if limited {
+		pool.quota.charge(from, time.Now())
+	}
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replace, nil
 }
 
+// discardByShare makes room for count transactions in the pool by discarding
+// the highest nonce transactions of the remote accounts holding the most of
+// them, starting with the accounts held back by a quota. If the sender of the
+// new transaction holds the largest share itself, nothing is discarded and the
+// new transaction is refused instead.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) discardByShare(from common.Address, count int) error {
+	now := time.Now()
+
+	counts := make(map[common.Address]int)
+	for addr, list := range pool.pending {
+		counts[addr] += list.Len()
+	}
+	for addr, list := range pool.queue {
+		counts[addr] += list.Len()
+	}
+	shares := make(accountShares, 0, len(counts))
+	for addr, txs := range counts {
+		if !pool.locals.contains(addr) {
+			shares = append(shares, &accountShare{addr: addr, txs: txs, throttled: pool.quota.throttled(addr, now)})
+		}
+	}
+	for ; count > 0 && len(shares) > 0; count-- {
+		sort.Sort(shares)
+		share := shares[0]
+		if share.addr == from {
+			return ErrAccountPoolShare
+		}
+		// Drop the highest nonce transaction, queued ones first
+		list := pool.queue[share.addr]
+		if list == nil || list.Empty() {
+			list = pool.pending[share.addr]
+		}
+		txs := list.Flatten()
+		log.Trace("Discarding transaction of the largest account", "hash", txs[len(txs)-1].Hash(), "from", share.addr)
+		pool.removeTx(txs[len(txs)-1].Hash())
+		quotaDiscardCounter.Inc(1)
+
+		if share.txs--; share.txs == 0 {
+			shares = shares[1:]
+		}
+	}
+	return nil
+}
+
+// accountShare is the number of transactions an account holds in the pool.
+type accountShare struct {
+	addr      common.Address
+	txs       int
+	throttled bool
+}
+
+// accountShares sorts the accounts by the order their transactions are
+// discarded in: held back by a quota first, then by the number of
+// transactions they hold.
+type accountShares []*accountShare
+
+func (s accountShares) Len() int { return len(s) }
+func (s accountShares) Less(i, j int) bool {
+	if s[i].throttled != s[j].throttled {
+		return s[i].throttled
+	}
+	if s[i].txs != s[j].txs {
+		return s[i].txs > s[j].txs
+	}
+	return bytes.Compare(s[i].addr[:], s[j].addr[:]) < 0
+}
+func (s accountShares) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
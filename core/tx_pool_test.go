@@ -64,6 +64,10 @@ func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, *state.StateDB,
 	return bc.statedb, bc.statedb, nil
 }
 
+func (bc *testBlockChain) AccountPolicy() *AccountPolicy {
+	return nil
+}
+
 func (bc *testBlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription {
 	return bc.chainHeadFeed.Subscribe(ch)
 }
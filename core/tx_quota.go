@@ -0,0 +1,180 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/core/types"
+)
+
+// rateBucket is a token bucket limiting the transaction rate of an account.
+type rateBucket struct {
+	tokens float64   // Transactions the account may send right away
+	last   time.Time // Time the tokens were last refilled
+}
+
+// txQuota tracks the throughput of every account against the per account
+// quotas of the pool: the rate at which its transactions are admitted and the
+// gas of its transactions included over the last blocks.
+//
+// The gas quota counts the gas limits of the transactions rather than the gas
+// they used: the public receipts of private transactions don't disclose the gas
+// they used, and the pending transactions are held back before they run.
+//
+// Note, txQuota is not thread safe, it relies on the pool lock.
+type txQuota struct {
+	rate   uint64 // Transactions admitted per second and account, 0 if unlimited
+	gas    uint64 // Gas limits per account and window, 0 if unlimited
+	window uint64 // Number of blocks the gas quota applies to
+
+	buckets map[common.Address]*rateBucket
+	used    map[common.Address]uint64   // Gas limits included in the window preceding the next block
+	blocks  map[common.Hash]*blockUsage // Gas limits included per account in the recent blocks
+}
+
+// blockUsage is the gas limits of the transactions of every account in a block.
+type blockUsage struct {
+	parent common.Hash
+	gas    map[common.Address]uint64
+}
+
+// newTxQuota creates the quota tracker for the given pool configuration.
+func newTxQuota(config *TxPoolConfig) *txQuota {
+	return &txQuota{
+		rate:    config.AccountTxRate,
+		gas:     config.AccountGasQuota,
+		window:  config.QuotaWindow,
+		buckets: make(map[common.Address]*rateBucket),
+		used:    make(map[common.Address]uint64),
+		blocks:  make(map[common.Hash]*blockUsage),
+	}
+}
+
+// refill tops up the bucket of the account for the time elapsed since it was
+// last refilled and returns it.
+func (q *txQuota) refill(addr common.Address, now time.Time) *rateBucket {
+	burst := float64(q.rate)
+	bucket := q.buckets[addr]
+	if bucket == nil {
+		bucket = &rateBucket{tokens: burst, last: now}
+		q.buckets[addr] = bucket
+	}
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed.Seconds()*float64(q.rate))
+		bucket.last = now
+	}
+	return bucket
+}
+
+// allow reports whether the account may send another transaction at the given
+// time. The transaction is only counted against its rate once charged.
+func (q *txQuota) allow(addr common.Address, now time.Time) bool {
+	if q.rate == 0 {
+		return true
+	}
+	return q.refill(addr, now).tokens >= 1
+}
+
+// charge counts a transaction admitted at the given time against the rate of
+// the account.
+func (q *txQuota) charge(addr common.Address, now time.Time) {
+	if q.rate == 0 {
+		return
+	}
+	q.refill(addr, now).tokens--
+}
+
+// remaining returns the gas limits the account may still include in the next
+// block.
+func (q *txQuota) remaining(addr common.Address) uint64 {
+	if q.gas == 0 {
+		return math.MaxUint64
+	}
+	if used := q.used[addr]; used < q.gas {
+		return q.gas - used
+	}
+	return 0
+}
+
+// throttled reports whether the account is currently held back by one of its
+// quotas.
+func (q *txQuota) throttled(addr common.Address, now time.Time) bool {
+	if q.gas != 0 && q.used[addr] >= q.gas {
+		return true
+	}
+	return q.rate != 0 && q.buckets[addr] != nil && q.refill(addr, now).tokens < 1
+}
+
+// reset recounts the gas limits of every account in the blocks of the window
+// preceding the one after head, and forgets the rate of idle accounts.
+func (q *txQuota) reset(chain blockChain, signer types.Signer, head *types.Header, now time.Time) {
+	for addr := range q.buckets {
+		if q.refill(addr, now).tokens >= float64(q.rate) {
+			delete(q.buckets, addr)
+		}
+	}
+	if q.gas == 0 {
+		return
+	}
+	blocks := make(map[common.Hash]*blockUsage)
+	used := make(map[common.Address]uint64)
+
+	hash, number := head.Hash(), head.Number.Uint64()
+	for i := uint64(1); i < q.window; i++ {
+		usage := q.blocks[hash]
+		if usage == nil {
+			block := chain.GetBlock(hash, number)
+			if block == nil {
+				break
+			}
+			usage = &blockUsage{parent: block.ParentHash(), gas: make(map[common.Address]uint64)}
+			for _, tx := range block.Transactions() {
+				if from, err := types.Sender(signer, tx); err == nil {
+					usage.gas[from] += tx.Gas().Uint64()
+				}
+			}
+		}
+		blocks[hash] = usage
+		for addr, gas := range usage.gas {
+			used[addr] += gas
+		}
+		if number == 0 {
+			break
+		}
+		hash, number = usage.parent, number-1
+	}
+	q.blocks, q.used = blocks, used
+}
+
+// count returns the number of accounts currently held back by their quotas.
+func (q *txQuota) count(now time.Time) int {
+	throttled := make(map[common.Address]struct{})
+	for addr := range q.used {
+		if q.throttled(addr, now) {
+			throttled[addr] = struct{}{}
+		}
+	}
+	for addr := range q.buckets {
+		if q.throttled(addr, now) {
+			throttled[addr] = struct{}{}
+		}
+	}
+	return len(throttled)
+}
@@ -0,0 +1,233 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/bxmdb"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/consensus/ethash"
+	"github.com/InsighterInc/bxmp/core/state"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/core/vm"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/event"
+	"github.com/InsighterInc/bxmp/params"
+)
+
+// Tests that remote transactions exceeding the rate of their account are
+// refused until the rate allows them again, while local ones are exempt.
+func TestTransactionAccountRate(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountTxRate = 1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	remote, _ := crypto.GenerateKey()
+	local, _ := crypto.GenerateKey()
+	for _, addr := range []common.Address{crypto.PubkeyToAddress(remote.PublicKey), crypto.PubkeyToAddress(local.PublicKey)} {
+		pool.currentState.AddBalance(addr, big.NewInt(1000000000))
+	}
+	if err := pool.AddRemote(transaction(0, big.NewInt(100000), remote)); err != nil {
+		t.Fatalf("failed to add first remote transaction: %v", err)
+	}
+	if err := pool.AddRemote(transaction(1, big.NewInt(100000), remote)); err != ErrAccountThrottled {
+		t.Fatalf("second remote transaction error mismatch: have %v, want %v", err, ErrAccountThrottled)
+	}
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.AddLocal(transaction(i, big.NewInt(100000), local)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", i, err)
+		}
+	}
+	// Rewind the bucket of the remote account and check the rate recovers
+	pool.mu.Lock()
+	pool.quota.buckets[crypto.PubkeyToAddress(remote.PublicKey)].last = time.Now().Add(-time.Second)
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(transaction(1, big.NewInt(100000), remote)); err != nil {
+		t.Fatalf("failed to add remote transaction after refill: %v", err)
+	}
+	// Transactions refused after passing the rate check don't use up the rate
+	pool.mu.Lock()
+	pool.quota.buckets[crypto.PubkeyToAddress(remote.PublicKey)].last = time.Now().Add(-time.Second)
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(pricedTransaction(1, big.NewInt(90000), big.NewInt(1), remote)); err != ErrReplaceUnderpriced {
+		t.Fatalf("underpriced replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := pool.AddRemote(transaction(2, big.NewInt(100000), remote)); err != nil {
+		t.Fatalf("failed to add remote transaction after refused replacement: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 6 {
+		t.Errorf("pending transactions mismatched: have %d, want %d", pending, 6)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the pending transactions of remote accounts are truncated to the
+// gas the accounts have left in their quota.
+func TestTransactionAccountGasQuota(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountGasQuota = 250000
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	remote, _ := crypto.GenerateKey()
+	local, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{remote, local} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.AddRemote(transaction(i, big.NewInt(100000), remote)); err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+		if err := pool.AddLocal(transaction(i, big.NewInt(100000), local)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", i, err)
+		}
+	}
+	pending, _ := pool.Pending()
+	if txs := pending[crypto.PubkeyToAddress(remote.PublicKey)]; len(txs) != 2 {
+		t.Errorf("remote pending transactions mismatch: have %d, want %d", len(txs), 2)
+	}
+	if txs := pending[crypto.PubkeyToAddress(local.PublicKey)]; len(txs) != 3 {
+		t.Errorf("local pending transactions mismatch: have %d, want %d", len(txs), 3)
+	}
+}
+
+// Tests that the gas used by the accounts is counted over the blocks of the
+// quota window.
+func TestTxQuotaWindow(t *testing.T) {
+	var (
+		db, _   = bxmdb.NewMemDatabase()
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: big.NewInt(1000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, db, 3, func(i int, block *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{1}, new(big.Int), big.NewInt(21000), new(big.Int), nil), signer, key)
+		block.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	tests := []struct {
+		window    uint64
+		remaining uint64
+	}{
+		{1, 50000}, // Only the next block is in the window
+		{2, 29000},
+		{3, 8000},
+		{4, 0},
+		{8, 0}, // Windows reaching past genesis
+	}
+	for i, tt := range tests {
+		quota := newTxQuota(&TxPoolConfig{AccountGasQuota: 50000, QuotaWindow: tt.window})
+		quota.reset(blockchain, signer, blockchain.CurrentBlock().Header(), time.Now())
+
+		if remaining := quota.remaining(address); remaining != tt.remaining {
+			t.Errorf("test %d: remaining gas mismatch: have %d, want %d", i, remaining, tt.remaining)
+		}
+		if throttled := quota.throttled(address, time.Now()); throttled != (tt.remaining == 0) {
+			t.Errorf("test %d: throttled mismatch: have %v, want %v", i, throttled, tt.remaining == 0)
+		}
+	}
+}
+
+// Tests that a full pool of a bitmed chain makes room for new transactions at
+// the expense of the accounts holding the most of it, and refuses them from
+// those accounts themselves.
+func TestTransactionPoolShareDiscarding(t *testing.T) {
+	db, _ := bxmdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 6
+	config.GlobalQueue = 1
+	config.AccountGasQuota = 1000000
+
+	pool := NewTxPool(config, params.BitmedTestChainConfig, blockchain)
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Fill the pool with a large and a small account
+	for i := uint64(0); i < 5; i++ {
+		if err := pool.AddRemote(pricedTransaction(i, big.NewInt(100000), common.Big0, keys[0])); err != nil {
+			t.Fatalf("failed to add transaction %d of large account: %v", i, err)
+		}
+	}
+	for i := uint64(0); i < 2; i++ {
+		if err := pool.AddRemote(pricedTransaction(i, big.NewInt(100000), common.Big0, keys[1])); err != nil {
+			t.Fatalf("failed to add transaction %d of small account: %v", i, err)
+		}
+	}
+	// The large account can't grow any further, others take its place
+	if err := pool.AddRemote(pricedTransaction(5, big.NewInt(100000), common.Big0, keys[0])); err != ErrAccountPoolShare {
+		t.Fatalf("large account error mismatch: have %v, want %v", err, ErrAccountPoolShare)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, big.NewInt(100000), common.Big0, keys[2])); err != nil {
+		t.Fatalf("failed to add transaction of new account: %v", err)
+	}
+	if pending, _ := pool.Pending(); len(pending[crypto.PubkeyToAddress(keys[0].PublicKey)]) != 4 {
+		t.Errorf("large account pending mismatch: have %d, want %d", len(pending[crypto.PubkeyToAddress(keys[0].PublicKey)]), 4)
+	}
+	// Accounts over their quota are discarded first, whatever their share
+	pool.mu.Lock()
+	pool.quota.used[crypto.PubkeyToAddress(keys[1].PublicKey)] = config.AccountGasQuota
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(pricedTransaction(0, big.NewInt(100000), common.Big0, keys[3])); err != nil {
+		t.Fatalf("failed to add transaction of second new account: %v", err)
+	}
+	pool.mu.RLock()
+	if list := pool.pending[crypto.PubkeyToAddress(keys[1].PublicKey)]; list == nil || list.Len() != 1 {
+		t.Errorf("throttled account was not discarded from")
+	}
+	pool.mu.RUnlock()
+
+	if pending, queued := pool.Stats(); pending+queued != 7 {
+		t.Errorf("pool size mismatch: have %d, want %d", pending+queued, 7)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
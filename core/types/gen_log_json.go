@@ -21,6 +21,7 @@ func (l Log) MarshalJSON() ([]byte, error) {
 		BlockHash   common.Hash    `json:"blockHash"`
 		Index       hexutil.Uint   `json:"logIndex" gencodec:"required"`
 		Removed     bool           `json:"removed"`
+		Private     bool           `json:"private"`
 	}
 	var enc Log
 	enc.Address = l.Address
@@ -32,6 +33,7 @@ func (l Log) MarshalJSON() ([]byte, error) {
 	enc.BlockHash = l.BlockHash
 	enc.Index = hexutil.Uint(l.Index)
 	enc.Removed = l.Removed
+	enc.Private = l.Private
 	return json.Marshal(&enc)
 }
 
@@ -46,6 +48,7 @@ func (l *Log) UnmarshalJSON(input []byte) error {
 		BlockHash   *common.Hash    `json:"blockHash"`
 		Index       *hexutil.Uint   `json:"logIndex" gencodec:"required"`
 		Removed     *bool           `json:"removed"`
+		Private     *bool           `json:"private"`
 	}
 	var dec Log
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -84,5 +87,8 @@ func (l *Log) UnmarshalJSON(input []byte) error {
 	if dec.Removed != nil {
 		l.Removed = *dec.Removed
 	}
+	if dec.Private != nil {
+		l.Private = *dec.Private
+	}
 	return nil
 }
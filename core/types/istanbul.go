@@ -81,6 +81,24 @@ func ExtractIstanbulExtra(h *Header) (*IstanbulExtra, error) {
 	return istanbulExtra, nil
 }
 
+// EncodeIstanbulExtra builds the extra-data of an Istanbul genesis block: the
+// vanity, zero padded or cut to IstanbulExtraVanity bytes, followed by the RLP
+// encoded validators with an empty seal and no committed seals.
+func EncodeIstanbulExtra(vanity []byte, validators []common.Address) ([]byte, error) {
+	extra := make([]byte, IstanbulExtraVanity)
+	copy(extra, vanity)
+
+	payload, err := rlp.EncodeToBytes(&IstanbulExtra{
+		Validators:    validators,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(extra, payload...), nil
+}
+
 // IstanbulFilteredHeader returns a filtered header which some information (like seal, committed seals)
 // are clean to fulfill the Istanbul hash rules. It returns nil if the extra-data cannot be
 // decoded/encoded by rlp.
@@ -86,3 +86,28 @@ func TestExtractToIstanbul(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeIstanbulExtra(t *testing.T) {
+	validators := []common.Address{
+		common.BytesToAddress(hexutil.MustDecode("0x44add0ec310f115a0e603b2d7db9f067778eaf8a")),
+		common.BytesToAddress(hexutil.MustDecode("0x294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212")),
+		common.BytesToAddress(hexutil.MustDecode("0x6beaaed781d2d2ab6350f5c4566a2c6eaac407a6")),
+		common.BytesToAddress(hexutil.MustDecode("0x8be76812f765c24641ec63dc2852b378aba2b440")),
+	}
+	extra, err := EncodeIstanbulExtra([]byte("vanity"), validators)
+	if err != nil {
+		t.Fatalf("failed to encode extra-data: %v", err)
+	}
+	vanity := append([]byte("vanity"), bytes.Repeat([]byte{0x00}, IstanbulExtraVanity-6)...)
+	want := append(vanity, hexutil.MustDecode("0xf858f8549444add0ec310f115a0e603b2d7db9f067778eaf8a94294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212946beaaed781d2d2ab6350f5c4566a2c6eaac407a6948be76812f765c24641ec63dc2852b378aba2b44080c0")...)
+	if !bytes.Equal(extra, want) {
+		t.Errorf("extra-data mismatch:\nhave %x\nwant %x", extra, want)
+	}
+	istanbulExtra, err := ExtractIstanbulExtra(&Header{Extra: extra})
+	if err != nil {
+		t.Fatalf("failed to decode extra-data: %v", err)
+	}
+	if !reflect.DeepEqual(istanbulExtra.Validators, validators) {
+		t.Errorf("validators mismatch: have %x, want %x", istanbulExtra.Validators, validators)
+	}
+}
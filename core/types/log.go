@@ -54,6 +54,10 @@ type Log struct {
 	// The Removed field is true if this log was reverted due to a chain reorganisation.
 	// You must pay attention to this field if you receive logs through a filter query.
 	Removed bool `json:"removed"`
+
+	// The Private field is true if this log was emitted by a private transaction,
+	// which only the nodes party to the transaction can see.
+	Private bool `json:"private"`
 }
 
 type logMarshaling struct {
@@ -17,11 +17,13 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"sort"
 	"sync/atomic"
 
 	"github.com/InsighterInc/bxmp/common"
@@ -441,6 +443,87 @@ func (t *TransactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
 
+// TransactionSet is a set of transactions to fill a block from, ordered in a
+// nonce-honouring way.
+type TransactionSet interface {
+	// Peek returns the next transaction to include, nil if there is none.
+	Peek() *Transaction
+
+	// Shift replaces the next transaction with the next one from the same account.
+	Shift()
+
+	// Pop removes the next transaction along with the rest of its account.
+	Pop()
+}
+
+// TransactionsByRoundRobin represents a set of transactions that takes turns
+// between the accounts, one transaction at a time, regardless of their price.
+// It is used where prices are all equal, so that no account can monopolise a
+// block by sending more transactions than the others.
+type TransactionsByRoundRobin struct {
+	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	heads  Transactions                    // Next transaction for each unique account, in turn order
+	signer Signer                          // Signer for the set of transactions
+}
+
+// NewTransactionsByRoundRobin creates a transaction set that takes turns between
+// the accounts in a nonce-honouring way. The turns start in the order of the
+// hashes of the head transactions, so that no account is favoured systematically.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByRoundRobin(signer Signer, txs map[common.Address]Transactions) *TransactionsByRoundRobin {
+	heads := make(Transactions, 0, len(txs))
+	for _, accTxs := range txs {
+		heads = append(heads, accTxs[0])
+		// Ensure the sender address is from the signer
+		acc, _ := Sender(signer, accTxs[0])
+		txs[acc] = accTxs[1:]
+	}
+	sort.Sort(txByHash(heads))
+
+	return &TransactionsByRoundRobin{
+		txs:    txs,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction in turn.
+func (t *TransactionsByRoundRobin) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift queues the next transaction of the current account for its next turn
+// and moves on to the next account.
+func (t *TransactionsByRoundRobin) Shift() {
+	acc, _ := Sender(t.signer, t.heads[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads, t.txs[acc] = append(t.heads[1:], txs[0]), txs[1:]
+	} else {
+		t.heads = t.heads[1:]
+	}
+}
+
+// Pop removes the current account from the turns. This should be used when a
+// transaction cannot be executed and hence all subsequent ones should be
+// discarded from the same account.
+func (t *TransactionsByRoundRobin) Pop() {
+	t.heads = t.heads[1:]
+}
+
+type txByHash Transactions
+
+func (s txByHash) Len() int { return len(s) }
+func (s txByHash) Less(i, j int) bool {
+	hi, hj := s[i].Hash(), s[j].Hash()
+	return bytes.Compare(hi[:], hj[:]) < 0
+}
+func (s txByHash) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
 // Message is a fully derived transaction and implements core.Message
 //
 // NOTE: In a future PR this will be removed.
@@ -487,7 +570,12 @@ func (tx *Transaction) IsPrivate() bool {
 	return tx.data.V.Uint64() == 37 || tx.data.V.Uint64() == 38
 }
 
+// SetPrivate marks the transaction as private by moving its V value from
+// 27/28 to 37/38. Calling it on a private transaction is a no-op.
 func (tx *Transaction) SetPrivate() {
+	if tx.IsPrivate() {
+		return
+	}
 	if tx.data.V.Int64() == 28 {
 		tx.data.V.SetUint64(38)
 	} else {
@@ -216,6 +216,85 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 	}
 }
 
+// Tests that transactions are taken in turns between the accounts, one at a
+// time and with increasing nonces, regardless of their price.
+func TestTransactionRoundRobinSort(t *testing.T) {
+	// Generate a batch of accounts with differently sized transaction lists
+	keys := make([]*ecdsa.PrivateKey, 10)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	groups := map[common.Address]Transactions{}
+	for start, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for i := 0; i <= start; i++ {
+			tx, _ := SignTx(NewTransaction(uint64(start+i), common.Address{}, big.NewInt(100), big.NewInt(100), big.NewInt(int64(i)), nil), signer, key)
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	txset := NewTransactionsByRoundRobin(signer, groups)
+
+	// Every round must hold exactly one transaction of each account left
+	nonces := map[common.Address]uint64{}
+	for round := 0; round < len(keys); round++ {
+		seen := map[common.Address]bool{}
+		for left := len(keys) - round; left > 0; left-- {
+			tx := txset.Peek()
+			if tx == nil {
+				t.Fatalf("round %d: transactions exhausted", round)
+			}
+			from, _ := Sender(signer, tx)
+			if seen[from] {
+				t.Fatalf("round %d: account %x served twice", round, from[:4])
+			}
+			seen[from] = true
+			if nonce, ok := nonces[from]; ok && tx.Nonce() != nonce+1 {
+				t.Errorf("round %d: nonce mismatch for %x: have %d, want %d", round, from[:4], tx.Nonce(), nonce+1)
+			}
+			nonces[from] = tx.Nonce()
+			txset.Shift()
+		}
+	}
+	if tx := txset.Peek(); tx != nil {
+		t.Errorf("transactions left over: %v", tx.Hash())
+	}
+}
+
+// Tests that popping an account removes all its remaining transactions from
+// the turns.
+func TestTransactionRoundRobinPop(t *testing.T) {
+	signer := HomesteadSigner{}
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+
+	groups := map[common.Address]Transactions{}
+	for _, key := range []*ecdsa.PrivateKey{key1, key2} {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for i := 0; i < 3; i++ {
+			tx, _ := SignTx(NewTransaction(uint64(i), common.Address{}, big.NewInt(100), big.NewInt(100), big.NewInt(1), nil), signer, key)
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	txset := NewTransactionsByRoundRobin(signer, groups)
+
+	popped, _ := Sender(signer, txset.Peek())
+	txset.Pop()
+
+	count := 0
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		if from, _ := Sender(signer, tx); from == popped {
+			t.Fatalf("transaction of popped account %x returned", popped[:4])
+		}
+		count++
+		txset.Shift()
+	}
+	if count != 3 {
+		t.Errorf("transaction count mismatch: have %d, want %d", count, 3)
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
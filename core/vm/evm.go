@@ -24,6 +24,7 @@ import (
 	"github.com/InsighterInc/bxmp/core/state"
 	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 )
 
 // note: BitMED, States, and Value Transfer
@@ -449,6 +450,12 @@ func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 // Interpreter returns the EVM interpreter
 func (evm *EVM) Interpreter() *Interpreter { return evm.interpreter }
 
+// PrivateTxManager returns the manager resolving private transaction payloads,
+// nil if the node isn't running one.
+func (evm *EVM) PrivateTxManager() private.PrivateTransactionManager {
+	return evm.vmConfig.PrivateTxManager
+}
+
 func getDualState(env *EVM, addr common.Address) StateDB {
 	// priv: (a) -> (b)  (private)
 	// pub:   a  -> [b]  (private -> public)
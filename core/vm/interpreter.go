@@ -24,6 +24,7 @@ import (
 	"github.com/InsighterInc/bxmp/common/math"
 	"github.com/InsighterInc/bxmp/crypto"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 )
 
 // Config are the configuration options for the Interpreter
@@ -43,6 +44,8 @@ type Config struct {
 	DisableGasMetering bool
 	// Enable recording of SHA3/keccak preimages
 	EnablePreimageRecording bool
+	// PrivateTxManager resolves the payloads of private transactions
+	PrivateTxManager private.PrivateTransactionManager
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
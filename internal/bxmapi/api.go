@@ -40,7 +40,6 @@ import (
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/p2p"
 	"github.com/InsighterInc/bxmp/params"
-	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rlp"
 	"github.com/InsighterInc/bxmp/rpc"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -362,7 +361,7 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 	isPrivate := args.PrivateFor != nil
 	if isPrivate {
 		log.Info("sending private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
-		data, err = private.P.Send(data, args.PrivateFrom, args.PrivateFor)
+		data, err = sendPrivatePayload(s.b, data, args.PrivateFrom, args.PrivateFor)
 		log.Info("sent private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
 		if err != nil {
 			return common.Hash{}, err
@@ -981,6 +980,24 @@ func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, has
 	return nil
 }
 
+// GetPrivateTransactionParties returns the private transaction manager keys the
+// private transaction with the given hash was sent from and to, or nil if the
+// node neither sent nor received it. The parties of a received transaction
+// aren't known, it is only marked as received.
+func (s *PublicTransactionPoolAPI) GetPrivateTransactionParties(hash common.Hash) *core.PrivateTxParties {
+	return core.GetPrivateTxParties(s.b.ChainDb(), hash)
+}
+
+// GetPrivateTransactionsByParty returns the hashes of the private transactions
+// the node sent from or to the given private transaction manager key.
+func (s *PublicTransactionPoolAPI) GetPrivateTransactionsByParty(key string) []common.Hash {
+	hashes := core.GetPrivatePartyTxs(s.b.ChainDb(), key)
+	if hashes == nil {
+		return []common.Hash{}
+	}
+	return hashes
+}
+
 // GetRawTransactionByHash returns the bytes of the transaction for the given hash.
 func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
 	var tx *types.Transaction
@@ -1004,6 +1021,27 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(hash common.Hash) (map[
 	}
 	receipt, _, _, _ := core.GetReceipt(s.b.ChainDb(), hash) // Old receipts don't have the lookup data available
 
+	return receiptFields(tx, receipt, blockHash, blockNumber, index), nil
+}
+
+// GetPrivateTransactionReceipt returns the receipt of the private transaction
+// with the given hash, holding the outcome of its execution on the private
+// state and the logs of the private contracts. It returns nil for public and
+// unknown transactions.
+func (s *PublicTransactionPoolAPI) GetPrivateTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	tx, blockHash, blockNumber, index := core.GetTransaction(s.b.ChainDb(), hash)
+	if tx == nil || !tx.IsPrivate() {
+		return nil, nil
+	}
+	receipt, _, _, _ := core.GetPrivateReceipt(s.b.ChainDb(), hash)
+	if receipt == nil {
+		return nil, nil
+	}
+	return receiptFields(tx, receipt, blockHash, blockNumber, index), nil
+}
+
+// receiptFields formats the receipt of a transaction for the RPC.
+func receiptFields(tx *types.Transaction, receipt *types.Receipt, blockHash common.Hash, blockNumber, index uint64) map[string]interface{} {
 	var signer types.Signer = types.HomesteadSigner{}
 	if tx.Protected() {
 		signer = types.NewEIP155Signer(tx.ChainId())
@@ -1013,7 +1051,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(hash common.Hash) (map[
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
+		"transactionHash":   tx.Hash(),
 		"transactionIndex":  hexutil.Uint64(index),
 		"from":              from,
 		"to":                tx.To(),
@@ -1037,7 +1075,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(hash common.Hash) (map[
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -1114,6 +1152,9 @@ func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, is
 	if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
+	if isPrivate {
+		writePrivateTxParties(b, tx)
+	}
 	if tx.To() == nil {
 		signer := types.MakeSigner(b.ChainConfig(), b.CurrentBlock().Number())
 		from, err := types.Sender(signer, tx)
@@ -1153,7 +1194,7 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 
 	if isPrivate {
 		log.Info("sending private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
-		data, err = private.P.Send(data, args.PrivateFrom, args.PrivateFor)
+		data, err = sendPrivatePayload(s.b, data, args.PrivateFrom, args.PrivateFor)
 		log.Info("sent private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
 		if err != nil {
 			return common.Hash{}, err
@@ -1191,6 +1232,17 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	return submitTransaction(ctx, s.b, tx, tx.IsPrivate())
 }
 
+// SendPrivatePayload hands the payload of a private transaction to the private
+// transaction manager and returns the digest to use as the transaction data.
+// Clients signing transactions themselves use it before marking the signed
+// transaction private and submitting it with SendRawTransaction.
+func (s *PublicTransactionPoolAPI) SendPrivatePayload(ctx context.Context, payload hexutil.Bytes, privateFrom string, privateFor []string) (hexutil.Bytes, error) {
+	if len(privateFor) == 0 {
+		return nil, errors.New("private payload has no recipients")
+	}
+	return sendPrivatePayload(s.b, payload, privateFrom, privateFor)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Bitmed Signed Message:\n" + len(message) + message).
 //
@@ -1310,6 +1362,9 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 			if err = s.b.SendTx(ctx, signedTx); err != nil {
 				return common.Hash{}, err
 			}
+			if signedTx.IsPrivate() {
+				writePrivateTxParties(s.b, signedTx)
+			}
 			return signedTx.Hash(), nil
 		}
 	}
@@ -1480,13 +1535,16 @@ func (a *Async) send(ctx context.Context, s *PublicTransactionPoolAPI, asyncArgs
 		res.Error = err.Error()
 		return
 	}
-	b, err := private.P.Send([]byte(args.Data), args.PrivateFrom, args.PrivateFor)
-	if err != nil {
-		log.Info("Error running Private.P.Send", "err", err)
-		res.Error = err.Error()
-		return
+	data := []byte(args.Data)
+	if args.PrivateFor != nil {
+		data, err = sendPrivatePayload(s.b, data, args.PrivateFrom, args.PrivateFor)
+		if err != nil {
+			log.Info("Error sending private payload", "err", err)
+			res.Error = err.Error()
+			return
+		}
 	}
-	res.TxHash, err = a.save(ctx, s, args, b)
+	res.TxHash, err = a.save(ctx, s, args, data)
 	if err != nil {
 		res.Error = err.Error()
 	}
@@ -1545,10 +1603,45 @@ func (s *PublicTransactionPoolAPI) SendTransactionAsync(ctx context.Context, arg
 	}()
 }
 
+var errNoPrivateTxManager = errors.New("PrivateTransactionManager is not enabled")
+
+// sendPrivatePayload hands the payload of a private transaction to the node's
+// private transaction manager and returns the digest to put on chain.
+func sendPrivatePayload(b Backend, data []byte, from string, to []string) ([]byte, error) {
+	ptm := b.PrivateTxManager()
+	if ptm == nil {
+		return nil, errNoPrivateTxManager
+	}
+	digest, err := ptm.Send(data, from, to)
+	if err != nil {
+		return nil, err
+	}
+	parties := &core.PrivateTxParties{PrivateFrom: from, PrivateFor: to}
+	if err := core.WritePrivatePayloadParties(b.ChainDb(), digest, parties); err != nil {
+		log.Error("Failed to store private payload parties", "err", err)
+	}
+	return digest, nil
+}
+
+// writePrivateTxParties stores the parties of a submitted private transaction,
+// if its payload was sent by this node.
+func writePrivateTxParties(b Backend, tx *types.Transaction) {
+	parties := core.GetPrivatePayloadParties(b.ChainDb(), tx.Data())
+	if parties == nil {
+		return
+	}
+	if err := core.WritePrivateTxParties(b.ChainDb(), tx.Hash(), parties); err != nil {
+		log.Error("Failed to store private transaction parties", "hash", tx.Hash(), "err", err)
+		return
+	}
+	core.DeletePrivatePayloadParties(b.ChainDb(), tx.Data())
+}
+
 // GetBitmedPayload returns the contents of a private transaction
 func (s *PublicBlockChainAPI) GetBitmedPayload(digestHex string) (string, error) {
-	if private.P == nil {
-		return "", fmt.Errorf("PrivateTransactionManager is not enabled")
+	ptm := s.b.PrivateTxManager()
+	if ptm == nil {
+		return "", errNoPrivateTxManager
 	}
 	if len(digestHex) < 3 {
 		return "", fmt.Errorf("Invalid digest hex")
@@ -1563,7 +1656,7 @@ func (s *PublicBlockChainAPI) GetBitmedPayload(digestHex string) (string, error)
 	if len(b) != 64 {
 		return "", fmt.Errorf("Expected a BitMED digest of length 64, but got %d", len(b))
 	}
-	data, err := private.P.Receive(b)
+	data, err := ptm.Receive(b)
 	if err != nil {
 		return "", err
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rpc"
 )
 
@@ -43,6 +44,7 @@ type Backend interface {
 	ChainDb() bxmdb.Database
 	EventMux() *event.TypeMux
 	AccountManager() *accounts.Manager
+	PrivateTxManager() private.PrivateTransactionManager
 	// BlockChain API
 	SetHead(number uint64)
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
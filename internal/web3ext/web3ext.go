@@ -125,6 +125,16 @@ web3._extend({
 			call: 'admin_removePeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addPermissionedNode',
+			call: 'admin_addPermissionedNode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removePermissionedNode',
+			call: 'admin_removePermissionedNode',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -175,6 +185,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'permissionedNodes',
+			getter: 'admin_permissionedNodes'
+		}),
 	]
 });
 `
@@ -226,6 +240,12 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'dumpBlock',
 			call: 'debug_dumpBlock',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'dumpPrivateBlock',
+			call: 'debug_dumpPrivateBlock',
 			params: 1
 		}),
 		new web3._extend.Method({
@@ -356,6 +376,12 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'privateStateProof',
+			call: 'debug_privateStateProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: []
 });
@@ -389,6 +415,11 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivatePayload',
+			call: 'bxm_sendPrivatePayload',
+			params: 3
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'bxm_getRawTransactionByHash',
@@ -402,6 +433,27 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'getPrivateStateRootForContract',
+			call: 'bxm_getPrivateStateRootForContract',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getPrivateTransactionReceipt',
+			call: 'bxm_getPrivateTransactionReceipt',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getPrivateTransactionParties',
+			call: 'bxm_getPrivateTransactionParties',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getPrivateTransactionsByParty',
+			call: 'bxm_getPrivateTransactionsByParty',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -609,6 +661,18 @@ web3._extend({
                        name: 'role',
                        getter: 'raft_role'
                }),
+               new web3._extend.Property({
+                       name: 'leader',
+                       getter: 'raft_leader'
+               }),
+               new web3._extend.Property({
+                       name: 'cluster',
+                       getter: 'raft_cluster'
+               }),
+               new web3._extend.Property({
+                       name: 'nodeInfo',
+                       getter: 'raft_nodeInfo'
+               }),
                new web3._extend.Method({
                        name: 'addPeer',
                        call: 'raft_addPeer',
@@ -618,6 +682,21 @@ web3._extend({
                        name: 'removePeer',
                        call: 'raft_removePeer',
                        params: 1
+               }),
+               new web3._extend.Method({
+                       name: 'addLearner',
+                       call: 'raft_addLearner',
+                       params: 1
+               }),
+               new web3._extend.Method({
+                       name: 'promoteToPeer',
+                       call: 'raft_promoteToPeer',
+                       params: 1
+               }),
+               new web3._extend.Method({
+                       name: 'transferLeadership',
+                       call: 'raft_transferLeadership',
+                       params: 1
                })
        ]
 })
@@ -650,6 +729,18 @@ web3._extend({
 			call: 'istanbul_getValidatorsAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getSignerStatus',
+			call: 'istanbul_getSignerStatus',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getMisbehaviour',
+			call: 'istanbul_getMisbehaviour',
+			params: 1,
+			inputFormatter: [null]
+		}),
 		new web3._extend.Method({
 			name: 'propose',
 			call: 'istanbul_propose',
@@ -659,6 +750,11 @@ web3._extend({
 			name: 'discard',
 			call: 'istanbul_discard',
 			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'proposeWeight',
+			call: 'istanbul_proposeWeight',
+			params: 2
 		})
 	],
 	properties:
@@ -667,6 +763,18 @@ web3._extend({
 			name: 'candidates',
 			getter: 'istanbul_candidates'
 		}),
+		new web3._extend.Property({
+			name: 'weightCandidates',
+			getter: 'istanbul_weightCandidates'
+		}),
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'istanbul_status'
+		}),
+		new web3._extend.Property({
+			name: 'roundState',
+			getter: 'istanbul_roundState'
+		}),
 	]
 });
 `
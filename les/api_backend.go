@@ -34,6 +34,7 @@ import (
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/light"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	"github.com/InsighterInc/bxmp/rpc"
 )
 
@@ -170,6 +171,10 @@ func (b *LesApiBackend) EventMux() *event.TypeMux {
 	return b.bxm.eventMux
 }
 
+func (b *LesApiBackend) PrivateTxManager() private.PrivateTransactionManager {
+	return b.bxm.ptm
+}
+
 func (b *LesApiBackend) AccountManager() *accounts.Manager {
 	return b.bxm.accountManager
 }
@@ -41,6 +41,7 @@ import (
 	"github.com/InsighterInc/bxmp/p2p"
 	"github.com/InsighterInc/bxmp/p2p/discv5"
 	"github.com/InsighterInc/bxmp/params"
+	"github.com/InsighterInc/bxmp/private"
 	rpc "github.com/InsighterInc/bxmp/rpc"
 )
 
@@ -66,6 +67,7 @@ type LightBitmed struct {
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
 	accountManager *accounts.Manager
+	ptm            private.PrivateTransactionManager
 
 	networkId     uint64
 	netRPCService *bxmapi.PublicNetAPI
@@ -99,6 +101,12 @@ func New(ctx *node.ServiceContext, config *bxm.Config) (*LightBitmed, error) {
 		networkId:      config.NetworkId,
 	}
 
+	if config.PrivateTxManager != "" {
+		if bxm.ptm, err = private.New(config.PrivateTxManager, config.PrivateConfig); err != nil {
+			return nil, fmt.Errorf("private transaction manager: %v", err)
+		}
+	}
+
 	bxm.relay = NewLesTxRelay(peers, bxm.reqDist)
 	bxm.serverPool = newServerPool(chainDb, quitSync, &bxm.wg)
 	bxm.retriever = newRetrieveManager(peers, bxm.reqDist, bxm.serverPool)
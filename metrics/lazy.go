@@ -0,0 +1,121 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// The lazy metrics are created when packages are initialised, before the
+// command line flags are parsed. They behave as NOP stubs until metrics
+// collection is enabled, and register the real metric on their first use
+// afterwards.
+
+type lazyCounter struct {
+	name    string
+	once    sync.Once
+	counter metrics.Counter
+}
+
+func (c *lazyCounter) get() metrics.Counter {
+	if !Enabled {
+		return metrics.NilCounter{}
+	}
+	c.once.Do(func() { c.counter = metrics.GetOrRegisterCounter(c.name, metrics.DefaultRegistry) })
+	return c.counter
+}
+
+func (c *lazyCounter) Clear()                    { c.get().Clear() }
+func (c *lazyCounter) Count() int64              { return c.get().Count() }
+func (c *lazyCounter) Dec(i int64)               { c.get().Dec(i) }
+func (c *lazyCounter) Inc(i int64)               { c.get().Inc(i) }
+func (c *lazyCounter) Snapshot() metrics.Counter { return c.get().Snapshot() }
+
+type lazyGauge struct {
+	name  string
+	once  sync.Once
+	gauge metrics.Gauge
+}
+
+func (g *lazyGauge) get() metrics.Gauge {
+	if !Enabled {
+		return metrics.NilGauge{}
+	}
+	g.once.Do(func() { g.gauge = metrics.GetOrRegisterGauge(g.name, metrics.DefaultRegistry) })
+	return g.gauge
+}
+
+func (g *lazyGauge) Snapshot() metrics.Gauge { return g.get().Snapshot() }
+func (g *lazyGauge) Update(v int64)          { g.get().Update(v) }
+func (g *lazyGauge) Value() int64            { return g.get().Value() }
+
+type lazyMeter struct {
+	name  string
+	once  sync.Once
+	meter metrics.Meter
+}
+
+func (m *lazyMeter) get() metrics.Meter {
+	if !Enabled {
+		return metrics.NilMeter{}
+	}
+	m.once.Do(func() { m.meter = metrics.GetOrRegisterMeter(m.name, metrics.DefaultRegistry) })
+	return m.meter
+}
+
+func (m *lazyMeter) Count() int64            { return m.get().Count() }
+func (m *lazyMeter) Mark(n int64)            { m.get().Mark(n) }
+func (m *lazyMeter) Rate1() float64          { return m.get().Rate1() }
+func (m *lazyMeter) Rate5() float64          { return m.get().Rate5() }
+func (m *lazyMeter) Rate15() float64         { return m.get().Rate15() }
+func (m *lazyMeter) RateMean() float64       { return m.get().RateMean() }
+func (m *lazyMeter) Snapshot() metrics.Meter { return m.get().Snapshot() }
+
+type lazyTimer struct {
+	name  string
+	once  sync.Once
+	timer metrics.Timer
+}
+
+func (t *lazyTimer) get() metrics.Timer {
+	if !Enabled {
+		return metrics.NilTimer{}
+	}
+	t.once.Do(func() { t.timer = metrics.GetOrRegisterTimer(t.name, metrics.DefaultRegistry) })
+	return t.timer
+}
+
+func (t *lazyTimer) Count() int64                       { return t.get().Count() }
+func (t *lazyTimer) Max() int64                         { return t.get().Max() }
+func (t *lazyTimer) Mean() float64                      { return t.get().Mean() }
+func (t *lazyTimer) Min() int64                         { return t.get().Min() }
+func (t *lazyTimer) Percentile(p float64) float64       { return t.get().Percentile(p) }
+func (t *lazyTimer) Percentiles(ps []float64) []float64 { return t.get().Percentiles(ps) }
+func (t *lazyTimer) Rate1() float64                     { return t.get().Rate1() }
+func (t *lazyTimer) Rate5() float64                     { return t.get().Rate5() }
+func (t *lazyTimer) Rate15() float64                    { return t.get().Rate15() }
+func (t *lazyTimer) RateMean() float64                  { return t.get().RateMean() }
+func (t *lazyTimer) Snapshot() metrics.Timer            { return t.get().Snapshot() }
+func (t *lazyTimer) StdDev() float64                    { return t.get().StdDev() }
+func (t *lazyTimer) Sum() int64                         { return t.get().Sum() }
+func (t *lazyTimer) Time(f func())                      { t.get().Time(f) }
+func (t *lazyTimer) Update(d time.Duration)             { t.get().Update(d) }
+func (t *lazyTimer) UpdateSince(ts time.Time)           { t.get().UpdateSince(ts) }
+func (t *lazyTimer) Variance() float64                  { return t.get().Variance() }
@@ -0,0 +1,47 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// Tests that the metrics created before collection is enabled start collecting
+// once it is.
+func TestEnableLater(t *testing.T) {
+	defer func(enabled bool) { Enabled = enabled }(Enabled)
+	Enabled = false
+
+	gauge := NewGauge("test/lazy/gauge")
+	gauge.Update(3)
+	if gauge.Value() != 0 || metrics.DefaultRegistry.Get("test/lazy/gauge") != nil {
+		t.Fatalf("gauge collecting while metrics are disabled")
+	}
+	Enable()
+	gauge.Update(5)
+	registered, ok := metrics.DefaultRegistry.Get("test/lazy/gauge").(metrics.Gauge)
+	if !ok || registered.Value() != 5 {
+		t.Fatalf("gauge not registered once metrics are enabled: %v", registered)
+	}
+	meter := NewMeter("test/lazy/meter")
+	meter.Mark(2)
+	if registered, ok := metrics.DefaultRegistry.Get("test/lazy/meter").(metrics.Meter); !ok || registered.Count() != 2 {
+		t.Fatalf("meter not registered once metrics are enabled: %v", registered)
+	}
+}
@@ -18,9 +18,7 @@
 package metrics
 
 import (
-	"os"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/InsighterInc/bxmp/log"
@@ -31,47 +29,49 @@ import (
 // MetricsEnabledFlag is the CLI flag name to use to enable metrics collections.
 const MetricsEnabledFlag = "metrics"
 
-// Enabled is the flag specifying if metrics are enable or not.
+// MetricsAddrFlag is the CLI flag name of the address to serve the metrics on
+// for Prometheus. Setting it enables metrics collection as well.
+const MetricsAddrFlag = "metrics.addr"
+
+// Enabled is the flag specifying if metrics are enable or not. The metrics
+// created before it is set only start collecting afterwards, so set it with
+// Enable while starting up.
 var Enabled = false
 
-// Init enables or disables the metrics system. Since we need this to run before
-// any other code gets to create meters and timers, we'll actually do an ugly hack
-// and peek into the command line args for the metrics flag.
 func init() {
-	for _, arg := range os.Args {
-		if strings.TrimLeft(arg, "-") == MetricsEnabledFlag {
-			log.Info("Enabling metrics collection")
-			Enabled = true
-		}
-	}
 	exp.Exp(metrics.DefaultRegistry)
 }
 
-// NewCounter create a new metrics Counter, either a real one of a NOP stub depending
-// on the metrics flag.
-func NewCounter(name string) metrics.Counter {
+// Enable turns on metrics collection.
+func Enable() {
 	if !Enabled {
-		return new(metrics.NilCounter)
+		log.Info("Enabling metrics collection")
+		Enabled = true
 	}
-	return metrics.GetOrRegisterCounter(name, metrics.DefaultRegistry)
 }
 
-// NewMeter create a new metrics Meter, either a real one of a NOP stub depending
-// on the metrics flag.
+// NewCounter create a new metrics Counter, which is a NOP stub until the
+// metrics are enabled.
+func NewCounter(name string) metrics.Counter {
+	return &lazyCounter{name: name}
+}
+
+// NewGauge create a new metrics Gauge, which is a NOP stub until the metrics
+// are enabled.
+func NewGauge(name string) metrics.Gauge {
+	return &lazyGauge{name: name}
+}
+
+// NewMeter create a new metrics Meter, which is a NOP stub until the metrics
+// are enabled.
 func NewMeter(name string) metrics.Meter {
-	if !Enabled {
-		return new(metrics.NilMeter)
-	}
-	return metrics.GetOrRegisterMeter(name, metrics.DefaultRegistry)
+	return &lazyMeter{name: name}
 }
 
-// NewTimer create a new metrics Timer, either a real one of a NOP stub depending
-// on the metrics flag.
+// NewTimer create a new metrics Timer, which is a NOP stub until the metrics
+// are enabled.
 func NewTimer(name string) metrics.Timer {
-	if !Enabled {
-		return new(metrics.NilTimer)
-	}
-	return metrics.GetOrRegisterTimer(name, metrics.DefaultRegistry)
+	return &lazyTimer{name: name}
 }
 
 // CollectProcessMetrics periodically collects various metrics about the running
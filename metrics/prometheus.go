@@ -0,0 +1,148 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/InsighterInc/bxmp/log"
+	"github.com/rcrowley/go-metrics"
+)
+
+// PrometheusPath is the HTTP path the metrics are served on in the Prometheus
+// text exposition format.
+const PrometheusPath = "/metrics"
+
+// prometheusQuantiles are the quantiles reported for timers and histograms.
+var prometheusQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// PrometheusHandler returns an HTTP handler serving the metrics of the given
+// registry in the Prometheus text exposition format. Meters and counters are
+// reported as counters, gauges as gauges, and timers and histograms as
+// summaries. Timers are reported in seconds, with their names suffixed by
+// _seconds.
+func PrometheusHandler(registry metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w, registry)
+	})
+}
+
+// WritePrometheus writes the metrics of the given registry to w in the
+// Prometheus text exposition format, sorted by name.
+func WritePrometheus(w io.Writer, registry metrics.Registry) error {
+	all := make(map[string]interface{})
+	registry.Each(func(name string, metric interface{}) {
+		all[prometheusName(name)] = metric
+	})
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bufio.NewWriter(w)
+	for _, name := range names {
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			writePrometheusValue(buf, name, "counter", float64(metric.Count()))
+		case metrics.Gauge:
+			writePrometheusValue(buf, name, "gauge", float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			writePrometheusValue(buf, name, "gauge", metric.Value())
+		case metrics.Meter:
+			writePrometheusValue(buf, name, "counter", float64(metric.Snapshot().Count()))
+		case metrics.Timer:
+			t := metric.Snapshot()
+			// The sample only holds the recent values, so the total is
+			// estimated from their mean
+			scale := float64(time.Second)
+			writePrometheusSummary(buf, name+"_seconds", t.Count(), t.Mean()*float64(t.Count())/scale, t.Percentiles(prometheusQuantiles), scale)
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			writePrometheusSummary(buf, name, h.Count(), h.Mean()*float64(h.Count()), h.Percentiles(prometheusQuantiles), 1)
+		}
+	}
+	return buf.Flush()
+}
+
+// writePrometheusValue writes a single valued metric of the given type.
+func writePrometheusValue(w io.Writer, name, kind string, value float64) {
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+	fmt.Fprintf(w, "%s %s\n", name, prometheusFloat(value))
+}
+
+// writePrometheusSummary writes a summary with the given quantile values,
+// divided by scale.
+func writePrometheusSummary(w io.Writer, name string, count int64, sum float64, values []float64, scale float64) {
+	fmt.Fprintf(w, "# TYPE %s summary\n", name)
+	for i, quantile := range prometheusQuantiles {
+		fmt.Fprintf(w, "%s{quantile=\"%s\"} %s\n", name, prometheusFloat(quantile), prometheusFloat(values[i]/scale))
+	}
+	fmt.Fprintf(w, "%s_sum %s\n", name, prometheusFloat(sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, count)
+}
+
+// prometheusFloat formats a sample value the way Prometheus parses it.
+func prometheusFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// prometheusName converts a metric name such as "txpool/pending/discard" to a
+// valid Prometheus metric name, "txpool_pending_discard".
+func prometheusName(name string) string {
+	out := []byte(name)
+	for i, c := range out {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
+
+// StartPrometheus enables metrics collection and starts serving the metrics of
+// the default registry on the given address in the Prometheus text exposition
+// format. Listening happens synchronously, so that a failure to bind is
+// returned, while requests are served in the background.
+func StartPrometheus(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	Enable()
+
+	mux := http.NewServeMux()
+	mux.Handle(PrometheusPath, PrometheusHandler(metrics.DefaultRegistry))
+
+	log.Info("Starting metrics server", "url", fmt.Sprintf("http://%s%s", listener.Addr(), PrometheusPath))
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Error("Metrics server failed", "err", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+func TestPrometheusName(t *testing.T) {
+	tests := map[string]string{
+		"txpool/pending/discard":   "txpool_pending_discard",
+		"p2p/InboundTraffic":       "p2p_InboundTraffic",
+		"bxm/db/chaindata/compact": "bxm_db_chaindata_compact",
+		"les/client-req.time":      "les_client_req_time",
+		"1st":                      "_st",
+	}
+	for name, want := range tests {
+		if have := prometheusName(name); have != want {
+			t.Errorf("%q: name mismatch: have %q, want %q", name, have, want)
+		}
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	metrics.GetOrRegisterCounter("txpool/invalid", registry).Inc(3)
+	metrics.GetOrRegisterGauge("raft/term", registry).Update(7)
+	metrics.GetOrRegisterMeter("bxm/prop/txns/in/packets", registry).Mark(5)
+	timer := metrics.GetOrRegisterTimer("private/send", registry)
+	timer.Update(time.Second)
+	timer.Update(3 * time.Second)
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, registry); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	want := `# TYPE bxm_prop_txns_in_packets counter
+bxm_prop_txns_in_packets 5
+# TYPE private_send_seconds summary
+private_send_seconds{quantile="0.5"} 2
+private_send_seconds{quantile="0.75"} 3
+private_send_seconds{quantile="0.95"} 3
+private_send_seconds{quantile="0.99"} 3
+private_send_seconds{quantile="0.999"} 3
+private_send_seconds_sum 4
+private_send_seconds_count 2
+# TYPE raft_term gauge
+raft_term 7
+# TYPE txpool_invalid counter
+txpool_invalid 3
+`
+	if have := buf.String(); have != want {
+		t.Errorf("output mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	metrics.GetOrRegisterGauge("txpool/pending", registry).Update(42)
+
+	recorder := httptest.NewRecorder()
+	PrometheusHandler(registry).ServeHTTP(recorder, httptest.NewRequest("GET", PrometheusPath, nil))
+
+	if ctype := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/plain") {
+		t.Errorf("content type mismatch: have %q, want text/plain", ctype)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, "txpool_pending 42\n") {
+		t.Errorf("gauge missing from response:\n%s", body)
+	}
+}
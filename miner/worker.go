@@ -30,7 +30,6 @@ import (
 	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/state"
 	"github.com/InsighterInc/bxmp/core/types"
-	"github.com/InsighterInc/bxmp/core/vm"
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/log"
@@ -275,7 +274,7 @@ func (self *worker) update() {
 				self.currentMu.Lock()
 				acc, _ := types.Sender(self.current.signer, ev.Tx)
 				txs := map[common.Address]types.Transactions{acc: {ev.Tx}}
-				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
+				txset := self.transactionSet(self.current.signer, txs)
 
 				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
 				self.currentMu.Unlock()
@@ -311,6 +310,11 @@ func (self *worker) wait() {
 					l.BlockHash = block.Hash()
 				}
 			}
+			for _, r := range work.privateReceipts {
+				for _, l := range r.Logs {
+					l.BlockHash = block.Hash()
+				}
+			}
 			for _, log := range work.state.Logs() {
 				log.BlockHash = block.Hash()
 			}
@@ -318,6 +322,10 @@ func (self *worker) wait() {
 			// write private transacions
 			privateStateRoot, _ := work.privateState.CommitTo(self.chainDb, self.config.IsEIP158(block.Number()))
 			core.WritePrivateStateRoot(self.chainDb, block.Root(), privateStateRoot)
+			core.WritePrivateBlockBloom(self.chainDb, block.Hash(), block.NumberU64(), work.privateReceipts)
+			if err := core.WriteReceivedPrivateTxs(self.chainDb, self.chain.GetVMConfig().PrivateTxManager, block.Transactions()); err != nil {
+				log.Error("Failed writing received private transactions", "err", err)
+			}
 			allReceipts := append(work.receipts, work.privateReceipts...)
 
 			stat, err := self.chain.WriteBlockAndState(block, allReceipts, work.state)
@@ -334,7 +342,7 @@ func (self *worker) wait() {
 			self.mux.Post(core.NewMinedBlockEvent{Block: block})
 			var (
 				events []interface{}
-				logs   = work.state.Logs()
+				logs   = append(work.state.Logs(), work.privateState.Logs()...)
 			)
 			events = append(events, core.ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
 			if stat == core.CanonStatTy {
@@ -466,7 +474,7 @@ func (self *worker) commitNewWork() {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+	txs := self.transactionSet(self.current.signer, pending)
 	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
 
 	// compute uncles for the new block.
@@ -519,8 +527,19 @@ func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	return nil
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+// transactionSet orders the pending transactions to fill a block from. Bitmed
+// chains don't price their transactions, so they take turns between accounts
+// instead.
+func (self *worker) transactionSet(signer types.Signer, txs map[common.Address]types.Transactions) types.TransactionSet {
+	if self.config.IsBitmed {
+		return types.NewTransactionsByRoundRobin(signer, txs)
+	}
+	return types.NewTransactionsByPriceAndNonce(signer, txs)
+}
+
+func (env *Work) commitTransactions(mux *event.TypeMux, txs types.TransactionSet, bc *core.BlockChain, coinbase common.Address) {
 	gp := new(core.GasPool).AddGas(env.header.GasLimit)
+	policy := bc.AccountPolicy()
 
 	var coalescedLogs []*types.Log
 
@@ -543,8 +562,16 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			txs.Pop()
 			continue
 		}
+		// Skip the account if the local policy changed since the transaction was pooled
+		if err := policy.Check(from, tx.To() == nil); err != nil {
+			log.Trace("Skipping account not permitted by the account policy", "sender", from, "err", err)
+
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
+		env.privateState.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 
 		err, logs := env.commitTransaction(tx, bc, coinbase, gp)
 		switch err {
@@ -563,6 +590,11 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
 			txs.Pop()
 
+		case core.ErrReadOnlyAccount, core.ErrDeployNotPermitted:
+			// The sender lost its permission after the transaction was pooled, skip account
+			log.Trace("Skipping account not permitted to send transaction", "sender", from, "err", err)
+			txs.Pop()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
@@ -601,7 +633,7 @@ func (env *Work) commitTransaction(tx *types.Transaction, bc *core.BlockChain, c
 	snap := env.state.Snapshot()
 	privateSnap := env.privateState.Snapshot()
 
-	receipt, privateReceipt, _, err := core.ApplyTransaction(env.config, bc, &coinbase, gp, env.state, env.privateState, env.header, tx, env.header.GasUsed, vm.Config{})
+	receipt, privateReceipt, _, err := core.ApplyTransaction(env.config, bc, &coinbase, gp, env.state, env.privateState, env.header, tx, env.header.GasUsed, *bc.GetVMConfig())
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		env.privateState.RevertToSnapshot(privateSnap)
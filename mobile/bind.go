@@ -78,6 +78,13 @@ func (opts *TransactOpts) GetNonce() int64      { return opts.opts.Nonce.Int64()
 func (opts *TransactOpts) GetValue() *BigInt    { return &BigInt{opts.opts.Value} }
 func (opts *TransactOpts) GetGasPrice() *BigInt { return &BigInt{opts.opts.GasPrice} }
 func (opts *TransactOpts) GetGasLimit() int64   { return opts.opts.GasLimit.Int64() }
+func (opts *TransactOpts) GetPrivateFrom() string { return opts.opts.PrivateFrom }
+func (opts *TransactOpts) GetPrivateFor() *Strings {
+	if opts.opts.PrivateFor == nil {
+		return nil
+	}
+	return &Strings{opts.opts.PrivateFor}
+}
 
 // GetSigner cannot be reliably implemented without identity preservation (https://github.com/golang/go/issues/16876)
 // func (opts *TransactOpts) GetSigner() Signer { return &signer{opts.opts.Signer} }
@@ -102,6 +109,21 @@ func (opts *TransactOpts) SetGasPrice(price *BigInt)   { opts.opts.GasPrice = pr
 func (opts *TransactOpts) SetGasLimit(limit int64)     { opts.opts.GasLimit = big.NewInt(limit) }
 func (opts *TransactOpts) SetContext(context *Context) { opts.opts.Context = context.context }
 
+// SetPrivateFrom sets the public key the private transaction manager sends the
+// payload from. An empty key selects its default one.
+func (opts *TransactOpts) SetPrivateFrom(from string) { opts.opts.PrivateFrom = from }
+
+// SetPrivateFor makes the transaction private to the given public keys. Passing
+// nil reverts to a public transaction. Private transactions require a gas limit
+// to be set, as their gas cannot be estimated.
+func (opts *TransactOpts) SetPrivateFor(to *Strings) {
+	if to == nil {
+		opts.opts.PrivateFor = nil
+		return
+	}
+	opts.opts.PrivateFor = to.strs
+}
+
 // BoundContract is the base wrapper object that reflects a contract on the
 // BitMED network. It contains a collection of methods that are used by the
 // higher level contract bindings to operate.
@@ -21,6 +21,7 @@ package geth
 import (
 	"math/big"
 
+	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/core/types"
 	"github.com/InsighterInc/bxmp/bxmclient"
 )
@@ -104,6 +105,14 @@ func (ec *BitmedClient) GetTransactionReceipt(ctx *Context, hash *Hash) (receipt
 	return &Receipt{rawReceipt}, err
 }
 
+// GetPrivateTransactionReceipt returns the receipt of a private transaction
+// against the private state, holding the logs of the private contracts. The
+// receipt is not available for public or pending transactions.
+func (ec *BitmedClient) GetPrivateTransactionReceipt(ctx *Context, hash *Hash) (receipt *Receipt, _ error) {
+	rawReceipt, err := ec.client.PrivateTransactionReceipt(ctx.context, hash.hash)
+	return &Receipt{rawReceipt}, err
+}
+
 // SyncProgress retrieves the current progress of the sync algorithm. If there's
 // no sync currently running, it returns nil.
 func (ec *BitmedClient) SyncProgress(ctx *Context) (progress *SyncProgress, _ error) {
@@ -310,3 +319,103 @@ func (ec *BitmedClient) EstimateGas(ctx *Context, msg *CallMsg) (gas *BigInt, _
 func (ec *BitmedClient) SendTransaction(ctx *Context, tx *Transaction) error {
 	return ec.client.SendTransaction(ctx.context, tx.tx)
 }
+
+// SendTxArgs contains the parameters of a transaction signed by one of the
+// unlocked accounts of the node rather than by the client.
+type SendTxArgs struct {
+	args bxmclient.SendTxArgs
+}
+
+// NewSendTxArgs creates an empty transaction parameter list.
+func NewSendTxArgs() *SendTxArgs {
+	return new(SendTxArgs)
+}
+
+func (args *SendTxArgs) GetFrom() *Address      { return &Address{args.args.From} }
+func (args *SendTxArgs) GetGasPrice() *BigInt   { return &BigInt{args.args.GasPrice} }
+func (args *SendTxArgs) GetValue() *BigInt      { return &BigInt{args.args.Value} }
+func (args *SendTxArgs) GetData() []byte        { return args.args.Data }
+func (args *SendTxArgs) GetPrivateFrom() string { return args.args.PrivateFrom }
+func (args *SendTxArgs) GetTo() *Address {
+	if to := args.args.To; to != nil {
+		return &Address{*to}
+	}
+	return nil
+}
+func (args *SendTxArgs) GetGas() int64 {
+	if args.args.Gas == nil {
+		return 0
+	}
+	return args.args.Gas.Int64()
+}
+func (args *SendTxArgs) GetNonce() int64 {
+	if args.args.Nonce == nil {
+		return -1
+	}
+	return int64(*args.args.Nonce)
+}
+func (args *SendTxArgs) GetPrivateFor() *Strings {
+	if args.args.PrivateFor == nil {
+		return nil
+	}
+	return &Strings{args.args.PrivateFor}
+}
+
+func (args *SendTxArgs) SetFrom(address *Address)  { args.args.From = address.address }
+func (args *SendTxArgs) SetGas(gas int64)          { args.args.Gas = big.NewInt(gas) }
+func (args *SendTxArgs) SetGasPrice(price *BigInt) { args.args.GasPrice = price.bigint }
+func (args *SendTxArgs) SetValue(value *BigInt)    { args.args.Value = value.bigint }
+func (args *SendTxArgs) SetData(data []byte)       { args.args.Data = common.CopyBytes(data) }
+func (args *SendTxArgs) SetTo(address *Address) {
+	if address == nil {
+		args.args.To = nil
+		return
+	}
+	args.args.To = &address.address
+}
+
+// SetNonce sets the nonce of the transaction. A negative nonce has the node use
+// the pending nonce of the sender.
+func (args *SendTxArgs) SetNonce(nonce int64) {
+	if nonce < 0 {
+		args.args.Nonce = nil
+		return
+	}
+	n := uint64(nonce)
+	args.args.Nonce = &n
+}
+
+// SetPrivateFrom sets the public key the private transaction manager of the node
+// sends the payload from. An empty key selects its default one.
+func (args *SendTxArgs) SetPrivateFrom(from string) { args.args.PrivateFrom = from }
+
+// SetPrivateFor makes the transaction private to the given public keys. Passing
+// nil reverts to a public transaction.
+func (args *SendTxArgs) SetPrivateFor(to *Strings) {
+	if to == nil {
+		args.args.PrivateFor = nil
+		return
+	}
+	args.args.PrivateFor = to.strs
+}
+
+// SendTransactionArgs creates a transaction from the given arguments, has the
+// node sign it with the sending account and injects it into the pending pool.
+// Setting PrivateFor sends the payload through the node's private transaction
+// manager and submits a private transaction.
+func (ec *BitmedClient) SendTransactionArgs(ctx *Context, args *SendTxArgs) (hash *Hash, _ error) {
+	rawHash, err := ec.client.SendTransactionArgs(ctx.context, args.args)
+	return &Hash{rawHash}, err
+}
+
+// SendPrivatePayload hands the payload of a private transaction to the private
+// transaction manager of the node, which distributes it to the given recipients.
+// It returns the digest to use as the data of the transaction, which must be
+// marked private with SetPrivate after signing and before sending.
+func (ec *BitmedClient) SendPrivatePayload(ctx *Context, payload []byte, privateFrom string, privateFor *Strings) (digest []byte, _ error) {
+	var recipients []string
+	if privateFor != nil {
+		recipients = privateFor.strs
+	}
+	return ec.client.SendPrivatePayload(ctx.context, payload, privateFrom, recipients)
+}
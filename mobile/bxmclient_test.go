@@ -0,0 +1,212 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package geth
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/bxmclient"
+	"github.com/InsighterInc/bxmp/common"
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/core/types"
+	"github.com/InsighterInc/bxmp/crypto"
+	"github.com/InsighterInc/bxmp/rpc"
+)
+
+// TestBitmedAPI stands in for the private transaction APIs of a node and
+// records the requests it receives.
+type TestBitmedAPI struct {
+	args     map[string]interface{}
+	payload  []byte
+	from     string
+	to       []string
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (api *TestBitmedAPI) SendTransaction(args map[string]interface{}) common.Hash {
+	api.args = args
+	return common.Hash{0x01}
+}
+
+func (api *TestBitmedAPI) SendPrivatePayload(payload hexutil.Bytes, from string, to []string) hexutil.Bytes {
+	api.payload, api.from, api.to = payload, from, to
+	return crypto.Keccak256(payload)
+}
+
+func (api *TestBitmedAPI) GetPrivateTransactionReceipt(hash common.Hash) *types.Receipt {
+	return api.receipts[hash]
+}
+
+func newTestBitmedClient(t *testing.T) (*BitmedClient, *TestBitmedAPI) {
+	api := &TestBitmedAPI{receipts: make(map[common.Hash]*types.Receipt)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("bxm", api); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	return &BitmedClient{bxmclient.NewClient(rpc.DialInProc(server))}, api
+}
+
+func TestSendTxArgs(t *testing.T) {
+	client, api := newTestBitmedClient(t)
+
+	args := NewSendTxArgs()
+	if args.GetTo() != nil || args.GetPrivateFor() != nil || args.GetNonce() != -1 || args.GetGas() != 0 {
+		t.Fatalf("non-empty initial arguments: %+v", args.args)
+	}
+	privateFor := NewStrings(2)
+	privateFor.Set(0, "B")
+	privateFor.Set(1, "C")
+
+	args.SetFrom(&Address{common.Address{0xaa}})
+	args.SetTo(&Address{common.Address{0xbb}})
+	args.SetGas(90000)
+	args.SetGasPrice(NewBigInt(0))
+	args.SetValue(NewBigInt(5))
+	args.SetData([]byte{0x01, 0x02})
+	args.SetNonce(3)
+	args.SetPrivateFrom("A")
+	args.SetPrivateFor(privateFor)
+
+	if args.GetFrom().address != (common.Address{0xaa}) || args.GetTo().address != (common.Address{0xbb}) {
+		t.Errorf("address mismatch: from %x, to %x", args.GetFrom().address, args.GetTo().address)
+	}
+	if args.GetGas() != 90000 || args.GetNonce() != 3 || args.GetValue().GetInt64() != 5 {
+		t.Errorf("number mismatch: gas %d, nonce %d, value %d", args.GetGas(), args.GetNonce(), args.GetValue().GetInt64())
+	}
+	if args.GetPrivateFrom() != "A" || args.GetPrivateFor().String() != privateFor.String() {
+		t.Errorf("parties mismatch: from %q, for %v", args.GetPrivateFrom(), args.GetPrivateFor())
+	}
+	hash, err := client.SendTransactionArgs(NewContext(), args)
+	if err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if hash.hash != (common.Hash{0x01}) {
+		t.Errorf("hash mismatch: have %x, want %x", hash.hash, common.Hash{0x01})
+	}
+	want := map[string]interface{}{
+		"from":        hexutil.Encode(common.Address{0xaa}.Bytes()),
+		"to":          hexutil.Encode(common.Address{0xbb}.Bytes()),
+		"gas":         "0x15f90",
+		"gasPrice":    "0x0",
+		"value":       "0x5",
+		"data":        "0x0102",
+		"nonce":       "0x3",
+		"privateFrom": "A",
+		"privateFor":  []interface{}{"B", "C"},
+	}
+	if !reflect.DeepEqual(api.args, want) {
+		t.Errorf("request mismatch:\nhave %v\nwant %v", api.args, want)
+	}
+	// Clearing the optional arguments leaves them to the node
+	args.SetTo(nil)
+	args.SetNonce(-1)
+	args.SetPrivateFor(nil)
+	if _, err := client.SendTransactionArgs(NewContext(), args); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	for _, key := range []string{"nonce", "privateFor"} {
+		if _, ok := api.args[key]; ok {
+			t.Errorf("cleared argument %q sent: %v", key, api.args[key])
+		}
+	}
+	if to := api.args["to"]; to != nil {
+		t.Errorf("cleared recipient sent: %v", to)
+	}
+}
+
+func TestSendPrivatePayload(t *testing.T) {
+	client, api := newTestBitmedClient(t)
+
+	privateFor := NewStrings(1)
+	privateFor.Set(0, "B")
+
+	payload := []byte("private payload")
+	digest, err := client.SendPrivatePayload(NewContext(), payload, "A", privateFor)
+	if err != nil {
+		t.Fatalf("failed to send private payload: %v", err)
+	}
+	if !bytes.Equal(digest, crypto.Keccak256(payload)) {
+		t.Errorf("digest mismatch: have %x, want %x", digest, crypto.Keccak256(payload))
+	}
+	if !bytes.Equal(api.payload, payload) || api.from != "A" || !reflect.DeepEqual(api.to, []string{"B"}) {
+		t.Errorf("request mismatch: payload %q, from %q, to %v", api.payload, api.from, api.to)
+	}
+	if _, err := client.SendPrivatePayload(NewContext(), payload, "", nil); err != nil {
+		t.Fatalf("failed to send private payload without recipients: %v", err)
+	}
+	if api.to != nil {
+		t.Errorf("recipients mismatch: have %v, want none", api.to)
+	}
+}
+
+func TestGetPrivateTransactionReceipt(t *testing.T) {
+	client, api := newTestBitmedClient(t)
+
+	hash := common.Hash{0x02}
+	receipt := types.NewReceipt(nil, false, big.NewInt(21000))
+	receipt.TxHash = hash
+	receipt.GasUsed = big.NewInt(21000)
+	receipt.Logs = []*types.Log{{Address: common.Address{0xcc}, Topics: []common.Hash{}, Data: []byte{}, TxHash: hash, Private: true}}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	api.receipts[hash] = receipt
+
+	have, err := client.GetPrivateTransactionReceipt(NewContext(), &Hash{hash})
+	if err != nil {
+		t.Fatalf("failed to retrieve private receipt: %v", err)
+	}
+	if have.GetTxHash().hash != hash || have.GetGasUsed().GetInt64() != 21000 {
+		t.Errorf("receipt mismatch: hash %x, gas used %d", have.GetTxHash().hash, have.GetGasUsed().GetInt64())
+	}
+	logs := have.GetLogs()
+	if logs.Size() != 1 {
+		t.Fatalf("log count mismatch: have %d, want 1", logs.Size())
+	}
+	if log, _ := logs.Get(0); !log.IsPrivate() || log.GetAddress().address != (common.Address{0xcc}) {
+		t.Errorf("log mismatch: private %v, address %x", log.IsPrivate(), log.GetAddress().address)
+	}
+	if _, err := client.GetPrivateTransactionReceipt(NewContext(), &Hash{common.Hash{0x03}}); err == nil {
+		t.Errorf("expected error for unknown private receipt")
+	}
+}
+
+func TestTransactionSetPrivate(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	rawTx := types.NewTransaction(0, common.Address{}, new(big.Int), big.NewInt(21000), new(big.Int), nil)
+
+	signed, _ := types.SignTx(rawTx, types.HomesteadSigner{}, key)
+	tx := &Transaction{signed}
+	if err := tx.SetPrivate(); err != nil {
+		t.Fatalf("failed to mark transaction private: %v", err)
+	}
+	if !tx.IsPrivate() {
+		t.Errorf("transaction not marked private")
+	}
+	protected, _ := types.SignTx(rawTx, types.NewEIP155Signer(big.NewInt(10)), key)
+	tx = &Transaction{protected}
+	if err := tx.SetPrivate(); err == nil {
+		t.Fatalf("replay protected transaction marked private")
+	}
+	if tx.IsPrivate() {
+		t.Errorf("replay protected transaction marked private")
+	}
+	if from, err := types.Sender(types.NewEIP155Signer(big.NewInt(10)), tx.tx); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("sender mismatch: have %x (err %v), want %x", from, err, crypto.PubkeyToAddress(key.PublicKey))
+	}
+}
@@ -26,6 +26,18 @@ import (
 // Strings represents s slice of strs.
 type Strings struct{ strs []string }
 
+// NewStrings creates a slice of empty strings.
+func NewStrings(size int) *Strings {
+	return &Strings{
+		strs: make([]string, size),
+	}
+}
+
+// NewStringsEmpty creates an empty slice of strings.
+func NewStringsEmpty() *Strings {
+	return NewStrings(0)
+}
+
 // Size returns the number of strs in the slice.
 func (s *Strings) Size() int {
 	return len(s.strs)
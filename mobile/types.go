@@ -293,6 +293,21 @@ func (tx *Transaction) WithSignature(sig []byte, chainID *BigInt) (signedTx *Tra
 	return &Transaction{rawTx}, err
 }
 
+// IsPrivate reports whether the transaction is marked private.
+func (tx *Transaction) IsPrivate() bool { return tx.tx.IsPrivate() }
+
+// SetPrivate marks a signed transaction as private. The transaction's data must
+// be the digest returned by BitmedClient.SendPrivatePayload, and it must have
+// been signed without a chain ID, as private transactions aren't replay
+// protected.
+func (tx *Transaction) SetPrivate() error {
+	if tx.tx.Protected() {
+		return errors.New("transaction signed with a chain ID can't be made private")
+	}
+	tx.tx.SetPrivate()
+	return nil
+}
+
 // Transactions represents a slice of transactions.
 type Transactions struct{ txs types.Transactions }
 
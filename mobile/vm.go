@@ -39,6 +39,9 @@ func (l *Log) GetTxIndex() int       { return int(l.log.TxIndex) }
 func (l *Log) GetBlockHash() *Hash   { return &Hash{l.log.BlockHash} }
 func (l *Log) GetIndex() int         { return int(l.log.Index) }
 
+// IsPrivate reports whether the log was emitted by a private contract.
+func (l *Log) IsPrivate() bool { return l.log.Private }
+
 // Logs represents a slice of VM logs.
 type Logs struct{ logs []*types.Log }
 
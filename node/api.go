@@ -75,6 +75,53 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddPermissionedNode adds a node to the permissioned-nodes.json file of the
+// node, allowing it to connect.
+func (api *PrivateAdminAPI) AddPermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := discover.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := server.AddPermissionedNode(node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemovePermissionedNode removes a node from the permissioned-nodes.json file
+// of the node, disconnecting it if it is connected.
+func (api *PrivateAdminAPI) RemovePermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := discover.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := server.RemovePermissionedNode(node.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PermissionedNodes returns the enode URLs in the permissioned-nodes.json file
+// of the node.
+func (api *PrivateAdminAPI) PermissionedNodes() ([]string, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PermissionedNodes()
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
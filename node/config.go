@@ -136,6 +136,28 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCTLSCert and RPCTLSKey are the paths of the PEM encoded certificate and
+	// private key to serve the HTTP and websocket RPC endpoints over TLS with.
+	// If they are empty, the endpoints are served in plain text.
+	RPCTLSCert string `toml:",omitempty"`
+	RPCTLSKey  string `toml:",omitempty"`
+
+	// RPCTLSClientCA is the path of the PEM encoded certificates of the
+	// authorities signing client certificates. If set, the HTTP and websocket
+	// RPC endpoints only accept clients presenting a certificate signed by one
+	// of them. It requires TLS to be enabled.
+	RPCTLSClientCA string `toml:",omitempty"`
+
+	// RPCJWTSecret is the path of a file holding the hex encoded secret the
+	// HS256 bearer tokens of the clients are signed with. If set, the HTTP and
+	// websocket RPC endpoints only accept requests carrying a valid token.
+	RPCJWTSecret string `toml:",omitempty"`
+
+	// RPCPermissions is the path of a JSON file listing the RPC methods the
+	// clients of the HTTP and websocket RPC endpoints may call. If it is empty,
+	// authenticated clients may call all the exposed methods.
+	RPCPermissions string `toml:",omitempty"`
+
 	EnableNodePermission bool `toml:",omitempty"`
 }
 
@@ -17,9 +17,11 @@
 package node
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -381,15 +383,12 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 		}
 	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, scheme, auth, err := n.listenRPC(endpoint, "http")
+	if err != nil {
 		return err
 	}
-	go rpc.NewHTTPServer(cors, handler).Serve(listener)
-	log.Info(fmt.Sprintf("HTTP endpoint opened: http://%s", endpoint))
+	go (&http.Server{Handler: rpc.NewCorsHandler(auth.handler(handler), cors)}).Serve(listener)
+	log.Info(fmt.Sprintf("HTTP endpoint opened: %s://%s", scheme, endpoint))
 
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
@@ -399,6 +398,28 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	return nil
 }
 
+// listenRPC opens the listener of an HTTP or websocket RPC endpoint, over TLS
+// if configured, and returns it along with its URL scheme and the
+// authenticator of its clients.
+func (n *Node) listenRPC(endpoint string, scheme string) (net.Listener, string, *rpcAuth, error) {
+	tlsConfig, err := n.config.rpcTLSConfig()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	auth, err := newRPCAuth(n.config)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if tlsConfig != nil {
+		listener, scheme = tls.NewListener(listener, tlsConfig), scheme+"s"
+	}
+	return listener, scheme, auth, nil
+}
+
 // stopHTTP terminates the HTTP RPC endpoint.
 func (n *Node) stopHTTP() {
 	if n.httpListener != nil {
@@ -435,15 +456,12 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 		}
 	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, scheme, auth, err := n.listenRPC(endpoint, "ws")
+	if err != nil {
 		return err
 	}
-	go rpc.NewWSServer(wsOrigins, handler).Serve(listener)
-	log.Info(fmt.Sprintf("WebSocket endpoint opened: ws://%s", listener.Addr()))
+	go (&http.Server{Handler: auth.handler(handler.WebsocketHandler(wsOrigins))}).Serve(listener)
+	log.Info(fmt.Sprintf("WebSocket endpoint opened: %s://%s", scheme, listener.Addr()))
 
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
@@ -0,0 +1,251 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/InsighterInc/bxmp/common/hexutil"
+	"github.com/InsighterInc/bxmp/log"
+	"github.com/InsighterInc/bxmp/rpc"
+)
+
+// minJWTSecretLength is the minimum length of the secret bearer tokens are
+// signed with, the output size of the HS256 hash.
+const minJWTSecretLength = 32
+
+var (
+	errMissingToken  = errors.New("missing bearer token")
+	errInvalidToken  = errors.New("malformed bearer token")
+	errTokenAlgo     = errors.New("unsupported token algorithm, want HS256")
+	errTokenSig      = errors.New("invalid token signature")
+	errTokenExpired  = errors.New("token expired")
+	errTokenNotValid = errors.New("token not valid yet")
+)
+
+// rpcPermissionsConfig assigns the RPC methods clients may call by subject,
+// the subject of their bearer token or the common name of their certificate.
+type rpcPermissionsConfig struct {
+	Default  rpc.Permissions            `json:"default"`  // Permissions of the clients not listed
+	Subjects map[string]rpc.Permissions `json:"subjects"` // Permissions of individual clients
+}
+
+// lookup returns the permissions of the given subject.
+func (c *rpcPermissionsConfig) lookup(subject string) rpc.Permissions {
+	if permissions, ok := c.Subjects[subject]; ok && subject != "" {
+		return permissions
+	}
+	return c.Default
+}
+
+// jwtClaims are the claims of the bearer tokens the RPC endpoints check.
+type jwtClaims struct {
+	Subject     string          `json:"sub"`
+	Expiry      *int64          `json:"exp"`
+	NotBefore   *int64          `json:"nbf"`
+	Permissions rpc.Permissions `json:"permissions"` // Overrides the permissions of the subject if set
+}
+
+// rpcAuth authenticates the clients of the HTTP and websocket RPC endpoints
+// and attaches their permissions to their requests.
+type rpcAuth struct {
+	jwtSecret   []byte                // Secret bearer tokens are signed with, nil if not required
+	permissions *rpcPermissionsConfig // Permissions of the clients, nil if unrestricted
+}
+
+// newRPCAuth creates the authenticator of the RPC endpoints configured in c.
+// It returns nil if neither bearer tokens nor permissions are configured.
+func newRPCAuth(c *Config) (*rpcAuth, error) {
+	if c.RPCJWTSecret == "" && c.RPCPermissions == "" {
+		return nil, nil
+	}
+	auth := new(rpcAuth)
+	if c.RPCJWTSecret != "" {
+		blob, err := ioutil.ReadFile(c.RPCJWTSecret)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := hexutil.Decode(ensureHexPrefix(strings.TrimSpace(string(blob))))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT secret %s: %v", c.RPCJWTSecret, err)
+		}
+		if len(secret) < minJWTSecretLength {
+			return nil, fmt.Errorf("JWT secret %s too short: %d bytes, at least %d required", c.RPCJWTSecret, len(secret), minJWTSecretLength)
+		}
+		auth.jwtSecret = secret
+	}
+	if c.RPCPermissions != "" {
+		blob, err := ioutil.ReadFile(c.RPCPermissions)
+		if err != nil {
+			return nil, err
+		}
+		auth.permissions = new(rpcPermissionsConfig)
+		if err := json.Unmarshal(blob, auth.permissions); err != nil {
+			return nil, fmt.Errorf("invalid RPC permissions %s: %v", c.RPCPermissions, err)
+		}
+	}
+	return auth, nil
+}
+
+func ensureHexPrefix(s string) string {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return "0x" + s
+	}
+	return s
+}
+
+// handler wraps h, refusing the requests of clients failing to authenticate
+// and attaching the permissions of the others to their requests. A nil
+// authenticator returns h itself.
+func (a *rpcAuth) handler(h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Identify the client by its certificate, verified during the handshake
+		var subject string
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			subject = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		// Require a valid bearer token if configured, possibly naming the client
+		var permissions rpc.Permissions
+		if a.jwtSecret != nil {
+			claims, err := a.authenticate(r.Header.Get("Authorization"), time.Now())
+			if err != nil {
+				log.Debug("Refused unauthenticated RPC request", "remote", r.RemoteAddr, "err", err)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if claims.Subject != "" {
+				subject = claims.Subject
+			}
+			permissions = claims.Permissions
+		}
+		if permissions == nil {
+			if a.permissions == nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+			permissions = a.permissions.lookup(subject)
+		}
+		h.ServeHTTP(w, r.WithContext(rpc.WithPermissions(r.Context(), permissions)))
+	})
+}
+
+// authenticate verifies the bearer token in the given Authorization header and
+// returns its claims.
+func (a *rpcAuth) authenticate(header string, now time.Time) (*jwtClaims, error) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return nil, errMissingToken
+	}
+	return verifyJWT(strings.TrimSpace(header[len(prefix):]), a.jwtSecret, now)
+}
+
+// verifyJWT checks the signature and validity period of an HS256 JSON web
+// token and returns its claims.
+func verifyJWT(token string, secret []byte, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "HS256" {
+		return nil, errTokenAlgo
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errTokenSig
+	}
+	claims := new(jwtClaims)
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return nil, err
+	}
+	if claims.Expiry != nil && now.Unix() >= *claims.Expiry {
+		return nil, errTokenExpired
+	}
+	if claims.NotBefore != nil && now.Unix() < *claims.NotBefore {
+		return nil, errTokenNotValid
+	}
+	return claims, nil
+}
+
+// decodeJWTPart decodes a base64url encoded JSON part of a token into v.
+func decodeJWTPart(part string, v interface{}) error {
+	blob, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errInvalidToken
+	}
+	if err := json.Unmarshal(blob, v); err != nil {
+		return errInvalidToken
+	}
+	return nil
+}
+
+// rpcTLSConfig returns the TLS configuration of the HTTP and websocket RPC
+// endpoints, nil if they are served in plain text.
+func (c *Config) rpcTLSConfig() (*tls.Config, error) {
+	if c.RPCTLSCert == "" && c.RPCTLSKey == "" {
+		if c.RPCTLSClientCA != "" {
+			return nil, errors.New("RPC client certificates require a TLS certificate and key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.RPCTLSCert, c.RPCTLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RPC TLS certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.RPCTLSClientCA != "" {
+		blob, err := ioutil.ReadFile(c.RPCTLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(blob) {
+			return nil, fmt.Errorf("no certificates found in %s", c.RPCTLSClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
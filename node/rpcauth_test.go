@@ -0,0 +1,229 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/rpc"
+)
+
+// signJWT creates an HS256 token over the given claims.
+func signJWT(secret []byte, alg string, claims interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	token := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(token))
+	return token + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	now := time.Unix(1500000000, 0)
+
+	tests := []struct {
+		token string
+		err   error
+	}{
+		{signJWT(secret, "HS256", map[string]interface{}{"sub": "monitor"}), nil},
+		{signJWT(secret, "HS256", map[string]interface{}{"exp": now.Unix() + 1}), nil},
+		{signJWT(secret, "HS256", map[string]interface{}{"exp": now.Unix()}), errTokenExpired},
+		{signJWT(secret, "HS256", map[string]interface{}{"nbf": now.Unix() + 1}), errTokenNotValid},
+		{signJWT(secret[1:], "HS256", map[string]interface{}{}), errTokenSig},
+		{signJWT(secret, "none", map[string]interface{}{}), errTokenAlgo},
+		{"not.a.token", errInvalidToken},
+		{"token", errInvalidToken},
+	}
+	for i, tt := range tests {
+		if _, err := verifyJWT(tt.token, secret, now); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the clients are refused without a valid token and otherwise get
+// the permissions of their token or subject.
+func TestRPCAuthHandler(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	auth := &rpcAuth{
+		jwtSecret: secret,
+		permissions: &rpcPermissionsConfig{
+			Default:  rpc.Permissions{"eth"},
+			Subjects: map[string]rpc.Permissions{"operator": {"*"}},
+		},
+	}
+	var seen rpc.Permissions
+	handler := auth.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = rpc.PermissionsFromContext(r.Context())
+	}))
+	tests := []struct {
+		header      string
+		status      int
+		permissions rpc.Permissions
+	}{
+		{"", http.StatusUnauthorized, nil},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, nil},
+		{"Bearer " + signJWT(secret[1:], "HS256", map[string]interface{}{"sub": "operator"}), http.StatusUnauthorized, nil},
+		{"Bearer " + signJWT(secret, "HS256", map[string]interface{}{"sub": "operator"}), http.StatusOK, rpc.Permissions{"*"}},
+		{"Bearer " + signJWT(secret, "HS256", map[string]interface{}{"sub": "reader"}), http.StatusOK, rpc.Permissions{"eth"}},
+		{"bearer " + signJWT(secret, "HS256", map[string]interface{}{"permissions": []string{"net"}}), http.StatusOK, rpc.Permissions{"net"}},
+	}
+	for i, tt := range tests {
+		seen = nil
+
+		req := httptest.NewRequest("POST", "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, recorder.Code, tt.status)
+		}
+		if !reflect.DeepEqual(seen, tt.permissions) {
+			t.Errorf("test %d: permissions mismatch: have %v, want %v", i, seen, tt.permissions)
+		}
+	}
+}
+
+// RPCAuthTestService is the API served in the RPC endpoint tests.
+type RPCAuthTestService struct{}
+
+func (s *RPCAuthTestService) Echo(str string) string { return str }
+
+// testCertificate creates a certificate for the given common name, signed by
+// parent or self-signed if parent is nil, and writes it into dir.
+func testCertificate(t *testing.T, dir, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600)
+	ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.Leaf, _ = x509.ParseCertificate(der)
+	return cert
+}
+
+// Tests that the HTTP RPC endpoint is served over TLS, only to clients with a
+// certificate, and restricted to the permissions of their certificate.
+func TestHTTPMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcauth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := testCertificate(t, dir, "ca", nil)
+	testCertificate(t, dir, "server", &ca)
+	monitor := testCertificate(t, dir, "monitor", &ca)
+	other := testCertificate(t, dir, "other", &ca)
+
+	permissions := filepath.Join(dir, "permissions.json")
+	ioutil.WriteFile(permissions, []byte(`{"default": [], "subjects": {"monitor": ["test_echo"]}}`), 0600)
+
+	node := &Node{config: &Config{
+		RPCTLSCert:     filepath.Join(dir, "server.crt"),
+		RPCTLSKey:      filepath.Join(dir, "server.key"),
+		RPCTLSClientCA: filepath.Join(dir, "ca.crt"),
+		RPCPermissions: permissions,
+	}}
+	apis := []rpc.API{{Namespace: "test", Version: "1.0", Service: new(RPCAuthTestService), Public: true}}
+	if err := node.startHTTP("127.0.0.1:0", apis, nil, nil); err != nil {
+		t.Fatalf("failed to start HTTP endpoint: %v", err)
+	}
+	defer node.stopHTTP()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	call := func(certs []tls.Certificate) (map[string]interface{}, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello"]}`
+		resp, err := client.Post("https://"+node.httpListener.Addr().String(), "application/json", strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return result, nil
+	}
+	if _, err := call(nil); err == nil {
+		t.Errorf("client without certificate accepted")
+	}
+	if result, err := call([]tls.Certificate{monitor}); err != nil || result["result"] != "hello" {
+		t.Errorf("permitted client call failed: %v %v", result, err)
+	}
+	if result, err := call([]tls.Certificate{other}); err != nil || result["error"] == nil {
+		t.Errorf("restricted client call not refused: %v %v", result, err)
+	}
+}
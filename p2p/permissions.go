@@ -2,9 +2,11 @@ package p2p
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/p2p/discover"
@@ -15,64 +17,279 @@ const (
 	PERMISSIONED_CONFIG = "permissioned-nodes.json"
 )
 
-// check if a given node is permissioned to connect to the change
-func isNodePermissioned(nodename string, currentNode string, datadir string, direction string) bool {
+var (
+	errPermissioningDisabled = errors.New("node permissioning is disabled")
+	errAlreadyPermissioned   = errors.New("node already permissioned")
+	errNotPermissioned       = errors.New("node not permissioned")
+)
 
-	var permissionedList []string
-	nodes := parsePermissionedNodes(datadir)
-	for _, v := range nodes {
-		permissionedList = append(permissionedList, v.ID.String())
-	}
+// NodePermissions is a source of the node allow-list of a permissioned network,
+// used in place of the permissioned-nodes.json file when set on the server.
+type NodePermissions interface {
+	// IsPermitted reports whether the node with the given ID may connect.
+	IsPermitted(id discover.NodeID) bool
+}
+
+// SetNodePermissions makes the server take the allow-list from p instead of
+// the permissioned-nodes.json file, and disconnects the connected peers that
+// are not on it. Passing nil reverts to the file.
+func (srv *Server) SetNodePermissions(p NodePermissions) {
+	srv.permLock.Lock()
+	srv.permissions = p
+	srv.permLock.Unlock()
+
+	srv.EnforceNodePermissions()
+}
 
-	log.Debug("isNodePermissioned", "permissionedList", permissionedList)
-	for _, v := range permissionedList {
-		if v == nodename {
-			log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
-			return true
+// EnforceNodePermissions disconnects the connected peers that are no longer on
+// the allow-list. Sources of the list call it whenever the list changed.
+func (srv *Server) EnforceNodePermissions() {
+	if !srv.EnableNodePermission {
+		return
+	}
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return
+	}
+	currentNode := srv.Self().ID.String()
+	for _, p := range srv.Peers() {
+		if !srv.isPermitted(p.ID(), currentNode, "CONNECTED") {
+			log.Info("Disconnecting peer no longer permissioned", "id", p.ID())
+			p.Disconnect(DiscRequested)
 		}
-		log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
 	}
-	log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
-	return false
 }
 
-//this is a shameless copy from the config.go. It is a duplication of the code
-//for the timebeing to allow reload of the permissioned nodes while the server is running
+// isPermitted checks the node against the configured allow-list.
+func (srv *Server) isPermitted(id discover.NodeID, currentNode string, direction string) bool {
+	srv.permLock.RLock()
+	p := srv.permissions
+	if p == nil && srv.permFile != nil {
+		p = srv.permFile
+	}
+	srv.permLock.RUnlock()
 
-func parsePermissionedNodes(DataDir string) []*discover.Node {
+	permitted := p != nil && p.IsPermitted(id)
+	log.Debug("isNodePermissioned", "connection", direction, "nodename", id.String()[:NODE_NAME_LENGTH], "permitted", permitted, "by", currentNode[:NODE_NAME_LENGTH])
+	return permitted
+}
 
-	log.Debug("parsePermissionedNodes", "DataDir", DataDir, "file", PERMISSIONED_CONFIG)
+// startNodePermissions loads permissioned-nodes.json and starts watching it.
+func (srv *Server) startNodePermissions() {
+	file := newPermissionsFile(srv.DataDir, srv.EnforceNodePermissions)
+	if srv.DataDir != "" {
+		file.watcher.start()
+	}
+	srv.permLock.Lock()
+	srv.permFile = file
+	srv.permLock.Unlock()
+}
+
+// stopNodePermissions stops watching permissioned-nodes.json.
+func (srv *Server) stopNodePermissions() {
+	srv.permLock.RLock()
+	file := srv.permFile
+	srv.permLock.RUnlock()
 
-	path := filepath.Join(DataDir, PERMISSIONED_CONFIG)
-	if _, err := os.Stat(path); err != nil {
-		log.Error("Read Error for permissioned-nodes.json file. This is because 'permissioned' flag is specified but no permissioned-nodes.json file is present.", "err", err)
-		return nil
+	if file != nil {
+		file.watcher.close()
 	}
-	// Load the nodes from the config file
-	blob, err := ioutil.ReadFile(path)
+}
+
+// PermissionedNodes returns the enode URLs in permissioned-nodes.json.
+func (srv *Server) PermissionedNodes() ([]string, error) {
+	file, err := srv.permissionsFile()
 	if err != nil {
-		log.Error("parsePermissionedNodes: Failed to access nodes", "err", err)
-		return nil
+		return nil, err
 	}
+	return file.urls(), nil
+}
 
-	nodelist := []string{}
-	if err := json.Unmarshal(blob, &nodelist); err != nil {
-		log.Error("parsePermissionedNodes: Failed to load nodes", "err", err)
-		return nil
+// AddPermissionedNode adds the node to permissioned-nodes.json.
+func (srv *Server) AddPermissionedNode(node *discover.Node) error {
+	file, err := srv.permissionsFile()
+	if err != nil {
+		return err
 	}
-	// Interpret the list as a discovery node array
-	var nodes []*discover.Node
-	for _, url := range nodelist {
-		if url == "" {
-			log.Error("parsePermissionedNodes: Node URL blank")
-			continue
+	return file.add(node)
+}
+
+// RemovePermissionedNode removes the node from permissioned-nodes.json and
+// disconnects it, unless the allow-list is taken from another source.
+func (srv *Server) RemovePermissionedNode(id discover.NodeID) error {
+	file, err := srv.permissionsFile()
+	if err != nil {
+		return err
+	}
+	return file.remove(id)
+}
+
+func (srv *Server) permissionsFile() (*permissionsFile, error) {
+	if !srv.EnableNodePermission {
+		return nil, errPermissioningDisabled
+	}
+	srv.permLock.RLock()
+	defer srv.permLock.RUnlock()
+
+	if srv.permFile == nil {
+		return nil, errServerStopped
+	}
+	return srv.permFile, nil
+}
+
+// permissionsFile is the in-memory view of permissioned-nodes.json. It is
+// reloaded whenever the file changes on disk, so that the file isn't read and
+// parsed for every connection.
+type permissionsFile struct {
+	path     string
+	onChange func() // called after the allow-list changed
+	watcher  *permissionsWatcher
+
+	writeLock sync.Mutex // serializes modifications of the file
+	lock      sync.RWMutex
+	list      []string // enode URLs as listed in the file
+	nodes     map[discover.NodeID]bool
+}
+
+func newPermissionsFile(datadir string, onChange func()) *permissionsFile {
+	f := &permissionsFile{path: filepath.Join(datadir, PERMISSIONED_CONFIG)}
+	f.watcher = newPermissionsWatcher(f)
+	f.reload()
+	f.onChange = onChange // there are no peers to enforce the initial list on
+	return f
+}
+
+// IsPermitted implements NodePermissions.
+func (f *permissionsFile) IsPermitted(id discover.NodeID) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.nodes[id]
+}
+
+func (f *permissionsFile) urls() []string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return append([]string{}, f.list...)
+}
+
+// reload reads the file again and enforces the new list. A missing or broken
+// file empties the list, as it did when the file was read on every connection.
+func (f *permissionsFile) reload() {
+	list, err := readPermissionedNodes(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Error("Read Error for permissioned-nodes.json file. This is because 'permissioned' flag is specified but no permissioned-nodes.json file is present.", "err", err)
+		} else {
+			log.Error("Failed to load permissioned nodes", "file", f.path, "err", err)
 		}
+	}
+	nodes := make(map[discover.NodeID]bool)
+	for _, url := range list {
 		node, err := discover.ParseNode(url)
 		if err != nil {
-			log.Error("parsePermissionedNodes: Node URL", "url", url, "err", err)
+			log.Error("Invalid permissioned node URL", "url", url, "err", err)
+			continue
+		}
+		nodes[node.ID] = true
+	}
+	f.lock.Lock()
+	f.list, f.nodes = list, nodes
+	f.lock.Unlock()
+
+	log.Debug("Loaded permissioned nodes", "file", f.path, "count", len(nodes))
+	if f.onChange != nil {
+		f.onChange()
+	}
+}
+
+func (f *permissionsFile) add(node *discover.Node) error {
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	list, err := readPermissionedNodes(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, url := range list {
+		if n, err := discover.ParseNode(url); err == nil && n.ID == node.ID {
+			return errAlreadyPermissioned
+		}
+	}
+	return f.write(append(list, node.String()))
+}
+
+func (f *permissionsFile) remove(id discover.NodeID) error {
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	list, err := readPermissionedNodes(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errNotPermissioned
+		}
+		return err
+	}
+	kept := list[:0]
+	for _, url := range list {
+		if n, err := discover.ParseNode(url); err == nil && n.ID == id {
+			continue
+		}
+		kept = append(kept, url)
+	}
+	if len(kept) == len(list) {
+		return errNotPermissioned
+	}
+	return f.write(kept)
+}
+
+// write atomically replaces the file with the given list and reloads it, not
+// waiting for the watcher to notice.
+func (f *permissionsFile) write(list []string) error {
+	blob, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Atomic write: create a temporary hidden file first
+	// then move it into place.
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(blob); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	f.reload()
+	return nil
+}
+
+// readPermissionedNodes reads the enode URLs listed in the file.
+func readPermissionedNodes(path string) ([]string, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	if err := json.Unmarshal(blob, &list); err != nil {
+		return nil, err
+	}
+	kept := list[:0]
+	for _, url := range list {
+		if url == "" {
+			log.Error("parsePermissionedNodes: Node URL blank")
 			continue
 		}
-		nodes = append(nodes, node)
+		kept = append(kept, url)
 	}
-	return nodes
+	return kept, nil
 }
@@ -0,0 +1,221 @@
+package p2p
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/p2p/discover"
+)
+
+// testPermissions is an in-memory allow-list.
+type testPermissions struct {
+	mu    sync.Mutex
+	nodes map[discover.NodeID]bool
+}
+
+func newTestPermissions(ids ...discover.NodeID) *testPermissions {
+	p := &testPermissions{nodes: make(map[discover.NodeID]bool)}
+	for _, id := range ids {
+		p.nodes[id] = true
+	}
+	return p
+}
+
+func (p *testPermissions) IsPermitted(id discover.NodeID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nodes[id]
+}
+
+func (p *testPermissions) remove(id discover.NodeID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.nodes, id)
+}
+
+func TestPermissionsSetupConn(t *testing.T) {
+	allowed, denied := randomID(), randomID()
+	tests := []struct {
+		id        discover.NodeID
+		wantCalls string
+		wantErr   error
+	}{
+		{id: allowed, wantCalls: "doEncHandshake,doProtoHandshake,close,", wantErr: DiscUselessPeer},
+		{id: denied, wantCalls: "doEncHandshake,close,", wantErr: DiscUselessPeer},
+	}
+	// Permitted peers get past the permission check and are only
+	// rejected for lacking a matching protocol.
+	for i, test := range tests {
+		tt := &setupTransport{id: test.id, phs: &protoHandshake{ID: test.id}}
+		srv := &Server{
+			Config: Config{
+				PrivateKey:           newkey(),
+				MaxPeers:             10,
+				NoDial:               true,
+				Protocols:            []Protocol{discard},
+				EnableNodePermission: true,
+			},
+			newTransport: func(fd net.Conn) transport { return tt },
+		}
+		srv.SetNodePermissions(newTestPermissions(allowed))
+		if err := srv.Start(); err != nil {
+			t.Fatalf("couldn't start server: %v", err)
+		}
+		p1, _ := net.Pipe()
+		srv.SetupConn(p1, inboundConn, nil)
+		if tt.calls != test.wantCalls {
+			t.Errorf("test %d: calls mismatch: got %q, want %q", i, tt.calls, test.wantCalls)
+		}
+		if tt.closeErr != test.wantErr {
+			t.Errorf("test %d: close error mismatch: got %q, want %q", i, tt.closeErr, test.wantErr)
+		}
+		srv.Stop()
+	}
+}
+
+func TestEnforceNodePermissions(t *testing.T) {
+	remid := randomID()
+	perms := newTestPermissions(remid)
+
+	connected := make(chan *Peer, 1)
+	srv := &Server{
+		Config: Config{
+			Name:                 "test",
+			MaxPeers:             10,
+			ListenAddr:           "127.0.0.1:0",
+			PrivateKey:           newkey(),
+			EnableNodePermission: true,
+		},
+		newPeerHook:  func(p *Peer) { connected <- p },
+		newTransport: func(fd net.Conn) transport { return newTestTransport(remid, fd) },
+	}
+	srv.SetNodePermissions(perms)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	events := make(chan *PeerEvent, 10)
+	sub := srv.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	conn, err := net.DialTimeout("tcp", srv.ListenAddr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	select {
+	case <-connected:
+	case <-time.After(time.Second):
+		t.Fatal("server did not accept permitted peer")
+	}
+	// Enforcing an unchanged list must keep the peer
+	srv.EnforceNodePermissions()
+	if n := srv.PeerCount(); n != 1 {
+		t.Fatalf("peer count mismatch: have %d, want 1", n)
+	}
+	// Dropping it from the list must disconnect it
+	perms.remove(remid)
+	srv.EnforceNodePermissions()
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == PeerEventTypeDrop && ev.Peer == remid {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("removed peer was not disconnected")
+		}
+	}
+}
+
+func writePermissionedNodes(t *testing.T, dir string, urls ...string) {
+	blob, _ := json.Marshal(urls)
+	tmp := filepath.Join(dir, "tmp.json")
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, PERMISSIONED_CONFIG)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPermissionsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2p-permissions-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := &discover.Node{ID: randomID(), IP: net.IP{127, 0, 0, 1}, TCP: 30303}
+	b := &discover.Node{ID: randomID(), IP: net.IP{127, 0, 0, 1}, TCP: 30304}
+	writePermissionedNodes(t, dir, a.String(), "", "invalid")
+
+	changes := 0
+	file := newPermissionsFile(dir, func() { changes++ })
+	if !file.IsPermitted(a.ID) || file.IsPermitted(b.ID) {
+		t.Fatalf("initial list mismatch: have %v", file.urls())
+	}
+	if err := file.add(b); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	if err := file.add(b); err != errAlreadyPermissioned {
+		t.Errorf("duplicate add error mismatch: have %v, want %v", err, errAlreadyPermissioned)
+	}
+	if err := file.remove(a.ID); err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	if err := file.remove(a.ID); err != errNotPermissioned {
+		t.Errorf("missing remove error mismatch: have %v, want %v", err, errNotPermissioned)
+	}
+	if file.IsPermitted(a.ID) || !file.IsPermitted(b.ID) {
+		t.Errorf("list mismatch: have %v", file.urls())
+	}
+	if changes != 2 {
+		t.Errorf("change notifications mismatch: have %d, want 2", changes)
+	}
+	// The file itself must have been updated
+	want := []string{"invalid", b.String()}
+	if have, err := readPermissionedNodes(filepath.Join(dir, PERMISSIONED_CONFIG)); err != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("file content mismatch: have %v (err %v), want %v", have, err, want)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("temporary files left behind: %d files", len(files))
+	}
+}
+
+func TestPermissionsFileWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2p-permissions-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Start without a file, it must be picked up once created
+	id := randomID()
+	changed := make(chan struct{}, 10)
+	file := newPermissionsFile(dir, func() { changed <- struct{}{} })
+	file.watcher.start()
+	defer file.watcher.close()
+	time.Sleep(100 * time.Millisecond)
+
+	for i, urls := range [][]string{{"enode://" + id.String() + "@127.0.0.1:30303"}, {}} {
+		writePermissionedNodes(t, dir, urls...)
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("step %d: file change not noticed", i)
+		}
+		if have, want := file.IsPermitted(id), len(urls) > 0; have != want {
+			t.Errorf("step %d: permitted mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// +build darwin,!ios freebsd linux,!arm64 netbsd solaris
+
+package p2p
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/InsighterInc/bxmp/log"
+	"github.com/rjeczalik/notify"
+)
+
+// permissionsWatcher reloads permissioned-nodes.json when it changes.
+type permissionsWatcher struct {
+	file *permissionsFile
+	ev   chan notify.EventInfo
+	quit chan struct{}
+}
+
+func newPermissionsWatcher(file *permissionsFile) *permissionsWatcher {
+	return &permissionsWatcher{
+		file: file,
+		ev:   make(chan notify.EventInfo, 10),
+		quit: make(chan struct{}),
+	}
+}
+
+func (w *permissionsWatcher) start() {
+	go w.loop()
+}
+
+func (w *permissionsWatcher) close() {
+	close(w.quit)
+}
+
+func (w *permissionsWatcher) loop() {
+	// The directory is watched rather than the file, so that the file may be
+	// created later on and replaced by renaming another file over it.
+	dir, name := filepath.Split(w.file.path)
+	logger := log.New("path", w.file.path)
+
+	if err := notify.Watch(dir, w.ev, notify.All); err != nil {
+		logger.Warn("Failed to watch permissioned nodes", "err", err)
+		return
+	}
+	defer notify.Stop(w.ev)
+	logger.Trace("Started watching permissioned nodes")
+	defer logger.Trace("Stopped watching permissioned nodes")
+
+	// Wait for file system events and reload.
+	// When an event occurs, the reload call is delayed a bit so that
+	// multiple events arriving quickly only cause a single reload.
+	var (
+		debounce         = time.NewTimer(0)
+		debounceDuration = 500 * time.Millisecond
+		reloadTriggered  = false
+	)
+	<-debounce.C
+	defer debounce.Stop()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case ev := <-w.ev:
+			if filepath.Base(ev.Path()) != name {
+				continue
+			}
+			// Trigger the reload (with delay), if not already triggered
+			if !reloadTriggered {
+				debounce.Reset(debounceDuration)
+				reloadTriggered = true
+			}
+		case <-debounce.C:
+			w.file.reload()
+			reloadTriggered = false
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// +build ios linux,arm64 windows !darwin,!freebsd,!linux,!netbsd,!solaris
+
+// This is the fallback implementation of watching permissioned-nodes.json.
+// It is used on unsupported platforms, where the file is only reloaded when
+// changed through the admin API.
+
+package p2p
+
+type permissionsWatcher struct{}
+
+func newPermissionsWatcher(*permissionsFile) *permissionsWatcher { return new(permissionsWatcher) }
+func (*permissionsWatcher) start()                               {}
+func (*permissionsWatcher) close()                               {}
@@ -158,6 +158,10 @@ type Server struct {
 	lock    sync.Mutex // protects running
 	running bool
 
+	permLock    sync.RWMutex     // protects permissions and permFile
+	permissions NodePermissions  // allow-list source, nil for permissioned-nodes.json
+	permFile    *permissionsFile // cached permissioned-nodes.json
+
 	ntab         discoverTable
 	listener     net.Listener
 	ourHandshake *protoHandshake
@@ -352,6 +356,9 @@ func (srv *Server) Stop() {
 	}
 	close(srv.quit)
 	srv.loopWG.Wait()
+	if srv.EnableNodePermission {
+		srv.stopNodePermissions()
+	}
 }
 
 // Start starts running the server.
@@ -413,6 +420,10 @@ func (srv *Server) Start() (err error) {
 	}
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
 
+	if srv.EnableNodePermission {
+		srv.startNodePermissions()
+	}
+
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
 	for _, p := range srv.Protocols {
@@ -734,15 +745,16 @@ func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *discover.Nod
 
 	if srv.EnableNodePermission {
 		log.Trace("Node Permissioning is Enabled.")
-		node := c.id.String()
+		node := c.id
 		direction := "INCOMING"
 		if dialDest != nil {
-			node = dialDest.ID.String()
+			node = dialDest.ID
 			direction = "OUTGOING"
 			log.Trace("Node Permissioning", "Connection Direction", direction)
 		}
 
-		if !isNodePermissioned(node, currentNode, srv.DataDir, direction) {
+		if !srv.isPermitted(node, currentNode, direction) {
+			c.close(DiscUselessPeer)
 			return
 		}
 	} else {
@@ -101,19 +101,19 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil, false}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil, false, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the BitMED core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil, false}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil, false, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
-	BitmedTestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, new(EthashConfig), nil, nil, true}
+	BitmedTestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, new(EthashConfig), nil, nil, true, nil}
 )
 
 // ChainConfig is the core config which determines the blockchain settings.
@@ -144,6 +144,8 @@ type ChainConfig struct {
 	Istanbul *IstanbulConfig `json:"istanbul,omitempty"`
 
 	IsBitmed bool `json:"isBitmed"`
+
+	AccountPermissions *AccountPermissionsConfig `json:"accountPermissions,omitempty"` // Contract restricting the transactions of the accounts
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -167,8 +169,23 @@ func (c *CliqueConfig) String() string {
 
 // IstanbulConfig is the consensus engine configs for Istanbul based sealing.
 type IstanbulConfig struct {
-	Epoch          uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
-	ProposerPolicy uint64 `json:"policy"` // The policy for proposer selection
+	Epoch          uint64          `json:"epoch"`                // Epoch length to reset votes and checkpoint
+	ProposerPolicy uint64          `json:"policy"`               // The policy for proposer selection: 0 round-robin, 1 sticky, 2 weighted
+	BlockPeriod    uint64          `json:"period,omitempty"`     // Minimum number of seconds between blocks, overriding the node's setting
+	Governance     *common.Address `json:"governance,omitempty"` // Contract the validators are read from at each checkpoint, instead of voted on
+
+	RequestTimeout uint64               `json:"requesttimeout,omitempty"` // Round timeout in milliseconds, overriding the node's setting
+	BlockPauseTime uint64               `json:"pausetime,omitempty"`      // Seconds to wait before sealing an empty block, overriding the node's setting
+	Transitions    []IstanbulTransition `json:"transitions,omitempty"`    // Changes of the timing parameters, in ascending block order
+}
+
+// IstanbulTransition changes the Istanbul timing parameters from a block on.
+// Parameters left at zero keep their previous value.
+type IstanbulTransition struct {
+	Block          *big.Int `json:"block"`
+	RequestTimeout uint64   `json:"requesttimeout,omitempty"` // Round timeout in milliseconds
+	BlockPeriod    uint64   `json:"period,omitempty"`         // Minimum number of seconds between blocks
+	BlockPauseTime uint64   `json:"pausetime,omitempty"`      // Seconds to wait before sealing an empty block
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -176,6 +193,13 @@ func (c *IstanbulConfig) String() string {
 	return "istanbul"
 }
 
+// AccountPermissionsConfig is the contract assigning the accounts the kind of
+// transactions they are permitted to send.
+type AccountPermissionsConfig struct {
+	Block    *big.Int       `json:"block,omitempty"` // Block from which the permissions are enforced (nil = genesis)
+	Contract common.Address `json:"contract"`        // Contract answering roleOf(address) with the role of an account
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -229,6 +253,25 @@ func (c *ChainConfig) IsByzantium(num *big.Int) bool {
 	return isForked(c.ByzantiumBlock, num)
 }
 
+// IsAccountPermissioned returns whether the account permissions contract is
+// enforced at block num.
+func (c *ChainConfig) IsAccountPermissioned(num *big.Int) bool {
+	return isForked(c.accountPermissionsBlock(), num)
+}
+
+// accountPermissionsBlock returns the block from which the account permissions
+// are enforced, or nil if they are disabled.
+func (c *ChainConfig) accountPermissionsBlock() *big.Int {
+	switch {
+	case c.AccountPermissions == nil:
+		return nil
+	case c.AccountPermissions.Block == nil:
+		return common.Big0
+	default:
+		return c.AccountPermissions.Block
+	}
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -289,9 +332,44 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ByzantiumBlock, newcfg.ByzantiumBlock, head) {
 		return newCompatError("Byzantium fork block", c.ByzantiumBlock, newcfg.ByzantiumBlock)
 	}
+	if isForkIncompatible(c.accountPermissionsBlock(), newcfg.accountPermissionsBlock(), head) {
+		return newCompatError("Account permissions block", c.accountPermissionsBlock(), newcfg.accountPermissionsBlock())
+	}
+	if c.IsAccountPermissioned(head) && newcfg.AccountPermissions.Contract != c.AccountPermissions.Contract {
+		return newCompatError("Account permissions contract", c.accountPermissionsBlock(), newcfg.accountPermissionsBlock())
+	}
+	if c.Istanbul != nil && newcfg.Istanbul != nil {
+		if stored, updated, ok := istanbulTransitionIncompatible(c.Istanbul.Transitions, newcfg.Istanbul.Transitions, head); !ok {
+			return newCompatError("Istanbul transition block", stored, updated)
+		}
+	}
 	return nil
 }
 
+// istanbulTransitionIncompatible reports the blocks of the first transitions
+// that differ between s1 and s2 although one of them already took effect at
+// head. It returns ok if there is none.
+func istanbulTransitionIncompatible(s1, s2 []IstanbulTransition, head *big.Int) (stored, updated *big.Int, ok bool) {
+	for i := 0; i < len(s1) || i < len(s2); i++ {
+		var t1, t2 *IstanbulTransition
+		stored, updated = nil, nil
+		if i < len(s1) {
+			t1, stored = &s1[i], s1[i].Block
+		}
+		if i < len(s2) {
+			t2, updated = &s2[i], s2[i].Block
+		}
+		if !isForked(stored, head) && !isForked(updated, head) {
+			continue
+		}
+		if t1 == nil || t2 == nil || !configNumEqual(t1.Block, t2.Block) || t1.RequestTimeout != t2.RequestTimeout ||
+			t1.BlockPeriod != t2.BlockPeriod || t1.BlockPauseTime != t2.BlockPauseTime {
+			return stored, updated, false
+		}
+	}
+	return nil, nil, true
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/InsighterInc/bxmp/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,65 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			// Transitions still ahead can be rescheduled
+			stored:  &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 5}}}},
+			new:     &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(20), BlockPeriod: 3}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 5}}}},
+			new:    &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 3}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Istanbul transition block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 5}}}},
+			new: &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{
+				{Block: big.NewInt(10), BlockPeriod: 5},
+				{Block: big.NewInt(12), RequestTimeout: 20000},
+			}}},
+			head: 15,
+			wantErr: &ConfigCompatError{
+				What:         "Istanbul transition block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(12),
+				RewindTo:     11,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{AccountPermissions: &AccountPermissionsConfig{Block: big.NewInt(20)}},
+			head:   15,
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{AccountPermissions: &AccountPermissionsConfig{}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Account permissions block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(0),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored: &ChainConfig{AccountPermissions: &AccountPermissionsConfig{Block: big.NewInt(10), Contract: common.Address{1}}},
+			new:    &ChainConfig{AccountPermissions: &AccountPermissionsConfig{Block: big.NewInt(10), Contract: common.Address{2}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Account permissions contract",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -8,6 +8,9 @@ type Config struct {
 	Socket         string   `toml:"socket"`
 	PublicKeys     []string `toml:"publickeys"`
 
+	// Endpoint of the Node API when it is served over HTTP instead of Socket
+	Endpoint       string   `toml:"endpoint"`
+
 	// Deprecated
 	SocketPath     string   `toml:"socketPath"`
 	PublicKeyPath  string   `toml:"publicKeyPath"`
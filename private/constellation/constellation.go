@@ -2,8 +2,10 @@ package constellation
 
 import (
 	"fmt"
-	"github.com/patrickmn/go-cache"
 	"time"
+
+	"github.com/InsighterInc/bxmp/log"
+	"github.com/patrickmn/go-cache"
 )
 
 type Constellation struct {
@@ -20,34 +22,63 @@ func (g *Constellation) Send(data []byte, from string, to []string) (out []byte,
 	return out, nil
 }
 
+// Receive returns the payload stored under data, or nil if this node is not a
+// recipient of it. Transport errors are retried with backoff before giving up;
+// failures are never cached.
 func (g *Constellation) Receive(data []byte) ([]byte, error) {
 	if len(data) == 0 {
 		return data, nil
 	}
-	// Ignore this error since not being a recipient of
-	// a payload isn't an error.
-	// TODO: Return an error if it's anything OTHER than
-	// 'you are not a recipient.'
 	dataStr := string(data)
 	x, found := g.c.Get(dataStr)
 	if found {
 		return x.([]byte), nil
 	}
-	pl, _ := g.node.ReceivePayload(data)
+	pl, err := g.receive(data)
+	if err == ErrNotARecipient {
+		// Not being a recipient of a payload isn't an error.
+		pl, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 	g.c.Set(dataStr, pl, cache.DefaultExpiration)
 	return pl, nil
 }
 
+// Retry schedule of receive, the delay doubles after every failed attempt.
+var (
+	receiveAttempts   = 5
+	receiveRetryDelay = 100 * time.Millisecond
+)
+
+func (g *Constellation) receive(key []byte) ([]byte, error) {
+	delay := receiveRetryDelay
+	for attempt := 1; ; attempt++ {
+		pl, err := g.node.ReceivePayload(key)
+		if !IsTransportError(err) || attempt == receiveAttempts {
+			return pl, err
+		}
+		log.Warn("Retrying private payload receive", "attempt", attempt, "delay", delay, "err", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
 func New(configPath string) (*Constellation, error) {
 	cfg, err := LoadConfig(configPath)
 	if err != nil {
 		return nil, err
 	}
+	publicKey, err := ownPublicKey(cfg, configPath)
+	if err != nil {
+		return nil, err
+	}
 	err = RunNode(configPath, cfg.Socket)
 	if err != nil {
 		return nil, err
 	}
-	n, err := NewClient(cfg.PublicKeys[0], cfg.Socket)
+	n, err := NewClient(publicKey, cfg.Socket)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +88,42 @@ func New(configPath string) (*Constellation, error) {
 	}, nil
 }
 
+// NewHTTP creates a Constellation backed by a Node API served over plain
+// HTTP at the endpoint set in the config file.
+func NewHTTP(configPath string) (*Constellation, error) {
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("no endpoint configured in %s", configPath)
+	}
+	publicKey, err := ownPublicKey(cfg, configPath)
+	if err != nil {
+		return nil, err
+	}
+	n, err := NewHTTPClient(publicKey, cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := upcheck(n.httpClient, n.baseURL); err != nil {
+		return nil, err
+	}
+	return &Constellation{
+		node: n,
+		c:    cache.New(5*time.Minute, 5*time.Minute),
+	}, nil
+}
+
+// ownPublicKey returns the path of the public key payloads are sent from, the
+// first one in the config file.
+func ownPublicKey(cfg *Config, configPath string) (string, error) {
+	if len(cfg.PublicKeys) == 0 || cfg.PublicKeys[0] == "" {
+		return "", fmt.Errorf("no public keys configured in %s", configPath)
+	}
+	return cfg.PublicKeys[0], nil
+}
+
 func MustNew(configPath string) *Constellation {
 	g, err := New(configPath)
 	if err != nil {
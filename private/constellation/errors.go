@@ -0,0 +1,36 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotARecipient is returned by ReceivePayload if the node isn't a party to
+// the requested payload.
+var ErrNotARecipient = errors.New("not a recipient of the payload")
+
+// TransportError is returned if the Node API could not be reached or failed to
+// serve the request. Retrying the request may succeed.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("constellation transport error: %v", e.Err)
+}
+
+// MalformedResponseError is returned if the Node API answered with a body that
+// could not be decoded.
+type MalformedResponseError struct {
+	Err error
+}
+
+func (e *MalformedResponseError) Error() string {
+	return fmt.Sprintf("malformed constellation response: %v", e.Err)
+}
+
+// IsTransportError reports whether err is a TransportError.
+func IsTransportError(err error) bool {
+	_, ok := err.(*TransportError)
+	return ok
+}
@@ -9,9 +9,11 @@ import (
 	"github.com/tv42/httpunix"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 )
 
@@ -29,6 +31,9 @@ func launchNode(cfgPath string) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// unixBaseURL is the URL prefix routed to the socket registered by unixTransport.
+const unixBaseURL = "http+unix://c/"
+
 func unixTransport(socketPath string) *httpunix.Transport {
 	t := &httpunix.Transport{
 		DialTimeout:           1 * time.Second,
@@ -45,13 +50,27 @@ func unixClient(socketPath string) *http.Client {
 	}
 }
 
+func httpClient() *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           (&net.Dialer{Timeout: 1 * time.Second}).DialContext,
+			ResponseHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
 func RunNode(cfgPath, nodeSocketPath string) error {
 	// launchNode(cfgPath)
-	c := unixClient(nodeSocketPath)
-	res, err := c.Get("http+unix://c/upcheck")
+	return upcheck(unixClient(nodeSocketPath), unixBaseURL)
+}
+
+func upcheck(c *http.Client, baseURL string) error {
+	res, err := c.Get(baseURL + "upcheck")
 	if err != nil {
 		return err
 	}
+	res.Body.Close()
 	if res.StatusCode == 200 {
 		return nil
 	}
@@ -79,6 +98,7 @@ type ReceiveResponse struct {
 
 type Client struct {
 	httpClient   *http.Client
+	baseURL      string
 	publicKey    [32]byte
 	b64PublicKey string
 }
@@ -89,16 +109,27 @@ func (c *Client) do(path string, apiReq interface{}) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", "http+unix://c/"+path, buf)
+	req, err := http.NewRequest("POST", c.baseURL+path, buf)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	res, err := c.httpClient.Do(req)
-	if err == nil && res.StatusCode != 200 {
-		return nil, fmt.Errorf("Non-200 status code: %+v", res)
-	}
-	return res, err
+	if err != nil {
+		return nil, &TransportError{err}
+	}
+	if res.StatusCode != 200 {
+		res.Body.Close()
+		switch {
+		case path == "receive" && res.StatusCode == http.StatusNotFound:
+			return nil, ErrNotARecipient
+		case res.StatusCode >= 500:
+			return nil, &TransportError{fmt.Errorf("Non-200 status code: %s", res.Status)}
+		default:
+			return nil, fmt.Errorf("Non-200 status code: %s", res.Status)
+		}
+	}
+	return res, nil
 }
 
 func (c *Client) SendPayload(pl []byte, b64From string, b64To []string) ([]byte, error) {
@@ -121,15 +152,19 @@ func (c *Client) SendPayload(pl []byte, b64From string, b64To []string) ([]byte,
 	sres := new(SendResponse)
 	err = json.NewDecoder(res.Body).Decode(sres)
 	if err != nil {
-		return nil, err
+		return nil, &MalformedResponseError{err}
 	}
 	key, err := base64.StdEncoding.DecodeString(sres.Key)
 	if err != nil {
-		return nil, err
+		return nil, &MalformedResponseError{err}
 	}
 	return key, nil
 }
 
+// ReceivePayload fetches the payload stored under key. It returns
+// ErrNotARecipient if the node isn't a party to it, a *TransportError if the
+// Node API could not be reached and a *MalformedResponseError if its answer
+// could not be decoded.
 func (c *Client) ReceivePayload(key []byte) ([]byte, error) {
 	b64Key := base64.StdEncoding.EncodeToString(key)
 	req := &ReceiveRequest{
@@ -144,11 +179,11 @@ func (c *Client) ReceivePayload(key []byte) ([]byte, error) {
 	rres := new(ReceiveResponse)
 	err = json.NewDecoder(res.Body).Decode(rres)
 	if err != nil {
-		return nil, err
+		return nil, &MalformedResponseError{err}
 	}
 	pl, err := base64.StdEncoding.DecodeString(rres.Payload)
 	if err != nil {
-		return nil, err
+		return nil, &MalformedResponseError{err}
 	}
 	return pl, nil
 }
@@ -160,6 +195,21 @@ func NewClient(publicKeyPath string, nodeSocketPath string) (*Client, error) {
 	}
 	return &Client{
 		httpClient:   unixClient(nodeSocketPath),
+		baseURL:      unixBaseURL,
+		b64PublicKey: string(b64PublicKey),
+	}, nil
+}
+
+// NewHTTPClient creates a client talking to the Constellation Node API
+// served on a regular HTTP endpoint instead of a unix socket.
+func NewHTTPClient(publicKeyPath string, endpoint string) (*Client, error) {
+	b64PublicKey, err := ioutil.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		httpClient:   httpClient(),
+		baseURL:      strings.TrimSuffix(endpoint, "/") + "/",
 		b64PublicKey: string(b64PublicKey),
 	}, nil
 }
@@ -0,0 +1,128 @@
+package constellation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+func newTestConstellation(t *testing.T, handler http.HandlerFunc) (*Constellation, func()) {
+	server := httptest.NewServer(handler)
+
+	keyFile, err := ioutil.TempFile("", "constellation-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile.WriteString("dGVzdGtleQ==")
+	keyFile.Close()
+
+	client, err := NewHTTPClient(keyFile.Name(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Constellation{node: client, c: cache.New(time.Minute, time.Minute)}
+	return g, func() {
+		server.Close()
+		os.Remove(keyFile.Name())
+	}
+}
+
+func TestReceivePayloadErrors(t *testing.T) {
+	payload := []byte("payload")
+	tests := []struct {
+		status int
+		body   string
+		check  func(error) bool
+	}{
+		{200, fmt.Sprintf(`{"payload":"%s"}`, base64.StdEncoding.EncodeToString(payload)), func(err error) bool { return err == nil }},
+		{404, "", func(err error) bool { return err == ErrNotARecipient }},
+		{500, "", IsTransportError},
+		{200, "garbage", func(err error) bool { _, ok := err.(*MalformedResponseError); return ok }},
+		{200, `{"payload":"!!"}`, func(err error) bool { _, ok := err.(*MalformedResponseError); return ok }},
+	}
+	for i, tt := range tests {
+		g, closer := newTestConstellation(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		})
+		_, err := g.node.ReceivePayload([]byte("key"))
+		if !tt.check(err) {
+			t.Errorf("test %d: unexpected error %v", i, err)
+		}
+		closer()
+	}
+}
+
+func TestReceiveRetry(t *testing.T) {
+	defer func(delay time.Duration) { receiveRetryDelay = delay }(receiveRetryDelay)
+	receiveRetryDelay = time.Millisecond
+
+	payload := []byte("payload")
+	calls := 0
+	g, closer := newTestConstellation(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls < receiveAttempts {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprintf(w, `{"payload":"%s"}`, base64.StdEncoding.EncodeToString(payload))
+	})
+	defer closer()
+
+	have, err := g.Receive([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, payload) {
+		t.Errorf("payload mismatch: have %x, want %x", have, payload)
+	}
+	if calls != receiveAttempts {
+		t.Errorf("call count mismatch: have %d, want %d", calls, receiveAttempts)
+	}
+}
+
+func TestReceiveFailureNotCached(t *testing.T) {
+	defer func(delay time.Duration) { receiveRetryDelay = delay }(receiveRetryDelay)
+	receiveRetryDelay = time.Millisecond
+
+	up := false
+	g, closer := newTestConstellation(t, func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(404)
+	})
+	defer closer()
+
+	if _, err := g.Receive([]byte("key")); !IsTransportError(err) {
+		t.Fatalf("expected transport error, got %v", err)
+	}
+	up = true
+	have, err := g.Receive([]byte("key"))
+	if err != nil || have != nil {
+		t.Fatalf("expected nil payload for non-recipient, got %x (err %v)", have, err)
+	}
+}
+
+func TestNewHTTPWithoutPublicKeys(t *testing.T) {
+	cfg, err := ioutil.TempFile("", "constellation-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(cfg.Name())
+	cfg.WriteString("endpoint = \"http://127.0.0.1:9001\"\n")
+	cfg.Close()
+
+	want := fmt.Sprintf("no public keys configured in %s", cfg.Name())
+	if _, err := NewHTTP(cfg.Name()); err == nil || err.Error() != want {
+		t.Fatalf("error mismatch: have %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Package memory implements a private transaction manager that keeps payloads
+// in process, optionally backed by a directory on the local file system. It is
+// meant for dev and test networks where running constellation-node is not
+// practical; nodes sharing the same storage directory see each other's payloads.
+package memory
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/InsighterInc/bxmp/common"
+)
+
+type Config struct {
+	// Directory payloads are persisted to. Payloads are only kept in
+	// memory if empty.
+	StoragePath string `toml:"storagePath"`
+
+	// Public keys owned by this node. A node without keys is party to
+	// every payload.
+	PublicKeys []string `toml:"publickeys"`
+}
+
+func LoadConfig(configPath string) (*Config, error) {
+	cfg := new(Config)
+	if configPath == "" {
+		return cfg, nil
+	}
+	if _, err := toml.DecodeFile(configPath, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// entry is a stored payload together with its parties.
+type entry struct {
+	Payload []byte   `json:"payload"`
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+}
+
+type Manager struct {
+	path string
+	keys []string
+
+	mu       sync.RWMutex
+	payloads map[string]*entry
+}
+
+// New creates an in-memory manager from the given config file. An empty
+// path yields a manager that keeps everything in memory and is party to
+// every payload.
+func New(configPath string) (*Manager, error) {
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StoragePath != "" {
+		if err := os.MkdirAll(cfg.StoragePath, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &Manager{
+		path:     cfg.StoragePath,
+		keys:     cfg.PublicKeys,
+		payloads: make(map[string]*entry),
+	}, nil
+}
+
+// Send stores the payload and returns its 64 byte digest. The digest covers
+// the parties as well, so that sending the same payload to another party set
+// doesn't change who can receive the earlier one.
+func (m *Manager) Send(data []byte, from string, to []string) ([]byte, error) {
+	if from == "" && len(m.keys) > 0 {
+		from = m.keys[0]
+	}
+	digest := payloadDigest(data, from, to)
+	key := hex.EncodeToString(digest)
+	e := &entry{Payload: common.CopyBytes(data), From: from, To: to}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.path != "" {
+		blob, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(filepath.Join(m.path, key), blob); err != nil {
+			return nil, err
+		}
+	}
+	m.payloads[key] = e
+	return digest, nil
+}
+
+// payloadDigest hashes the payload together with its parties.
+func payloadDigest(data []byte, from string, to []string) []byte {
+	parties := append([]string{from}, to...)
+	sort.Strings(parties[1:])
+
+	hasher := sha512.New()
+	hasher.Write(data)
+	for _, party := range parties {
+		// Length prefix the keys so that the encoding is unambiguous
+		fmt.Fprintf(hasher, "%d:%s", len(party), party)
+	}
+	return hasher.Sum(nil)
+}
+
+// Receive returns the payload stored under the given digest, or nil if it is
+// unknown or this node is not one of its parties.
+func (m *Manager) Receive(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	key := hex.EncodeToString(data)
+
+	e, err := m.lookup(key)
+	if err != nil || e == nil || !m.isParty(e) {
+		return nil, err
+	}
+	return common.CopyBytes(e.Payload), nil
+}
+
+func (m *Manager) lookup(key string) (*entry, error) {
+	m.mu.RLock()
+	e := m.payloads[key]
+	m.mu.RUnlock()
+	if e != nil || m.path == "" {
+		return e, nil
+	}
+	blob, err := ioutil.ReadFile(filepath.Join(m.path, key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	e = new(entry)
+	if err := json.Unmarshal(blob, e); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.payloads[key] = e
+	m.mu.Unlock()
+	return e, nil
+}
+
+func (m *Manager) isParty(e *entry) bool {
+	if len(m.keys) == 0 {
+		return true
+	}
+	for _, key := range m.keys {
+		if key == e.From {
+			return true
+		}
+		for _, to := range e.To {
+			if key == to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), path)
+}
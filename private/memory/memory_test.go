@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSendReceive(t *testing.T) {
+	m, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("private payload")
+	key, err := m.Send(payload, "", []string{"B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 64 {
+		t.Fatalf("key length mismatch: have %d, want 64", len(key))
+	}
+	have, err := m.Receive(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, payload) {
+		t.Errorf("payload mismatch: have %x, want %x", have, payload)
+	}
+	if have, _ := m.Receive(make([]byte, 64)); have != nil {
+		t.Errorf("expected nil payload for unknown key, got %x", have)
+	}
+}
+
+func TestSharedStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "private-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newManager := func(key string) *Manager {
+		cfg := filepath.Join(dir, key+".toml")
+		content := "storagePath = \"" + filepath.Join(dir, "store") + "\"\npublickeys = [\"" + key + "\"]\n"
+		if err := ioutil.WriteFile(cfg, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		m, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	a, b, c := newManager("A"), newManager("B"), newManager("C")
+
+	payload := []byte("shared payload")
+	key, err := a.Send(payload, "", []string{"B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, err := b.Receive(key); err != nil || !bytes.Equal(have, payload) {
+		t.Errorf("recipient: have %x (err %v), want %x", have, err, payload)
+	}
+	if have, err := c.Receive(key); err != nil || have != nil {
+		t.Errorf("non-recipient: have %x (err %v), want nil", have, err)
+	}
+}
+
+func TestSendToOtherParties(t *testing.T) {
+	newManager := func(key string) *Manager {
+		return &Manager{keys: []string{key}, payloads: make(map[string]*entry)}
+	}
+	a, b := newManager("A"), newManager("B")
+	// Both managers share the in-memory store, as nodes sharing a directory would
+	b.payloads = a.payloads
+
+	payload := []byte("shared payload")
+	first, err := a.Send(payload, "", []string{"B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := a.Send(payload, "", []string{"C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Fatalf("same digest for different parties: %x", first)
+	}
+	if have, err := b.Receive(first); err != nil || !bytes.Equal(have, payload) {
+		t.Errorf("first recipient: have %x (err %v), want %x", have, err, payload)
+	}
+	if have, err := b.Receive(second); err != nil || have != nil {
+		t.Errorf("non-recipient: have %x (err %v), want nil", have, err)
+	}
+}
@@ -1,9 +1,23 @@
 package private
 
 import (
-	"os"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/InsighterInc/bxmp/metrics"
 	"github.com/InsighterInc/bxmp/private/constellation"
+	"github.com/InsighterInc/bxmp/private/memory"
+)
+
+// Names of the private transaction manager backends shipped with the node.
+const (
+	ConstellationBackend = "constellation" // constellation-node over its unix socket
+	HTTPBackend          = "http"          // constellation-node over a plain HTTP endpoint
+	MemoryBackend        = "memory"        // in-process store for dev and test networks
+
+	DefaultBackend = ConstellationBackend
 )
 
 type PrivateTransactionManager interface {
@@ -11,12 +25,109 @@ type PrivateTransactionManager interface {
 	Receive(data []byte) ([]byte, error)
 }
 
-func FromEnvironmentOrNil(name string) PrivateTransactionManager {
-	cfgPath := os.Getenv(name)
-	if cfgPath == "" {
-		return nil
+// Factory creates a PrivateTransactionManager from a backend specific
+// configuration file.
+type Factory func(configPath string) (PrivateTransactionManager, error)
+
+var (
+	sendTimer    = metrics.NewTimer("private/send")    // Latency of handing payloads to the manager
+	receiveTimer = metrics.NewTimer("private/receive") // Latency of retrieving payloads from the manager
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+func init() {
+	Register(ConstellationBackend, func(configPath string) (PrivateTransactionManager, error) {
+		m, err := constellation.New(configPath)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	Register(HTTPBackend, func(configPath string) (PrivateTransactionManager, error) {
+		m, err := constellation.NewHTTP(configPath)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	Register(MemoryBackend, func(configPath string) (PrivateTransactionManager, error) {
+		m, err := memory.New(configPath)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+}
+
+// Register makes a private transaction manager backend available under the
+// given name. It panics if a backend is registered twice under the same name.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("private: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("private: Register called twice for backend " + name)
 	}
-	return constellation.MustNew(cfgPath)
+	factories[name] = factory
 }
 
-var P = FromEnvironmentOrNil("PRIVATE_CONFIG")
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a private transaction manager using the named backend.
+func New(name, configPath string) (PrivateTransactionManager, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown private transaction manager %q (available: %v)", name, Backends())
+	}
+	ptm, err := factory(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newMeteredManager(ptm), nil
+}
+
+// meteredManager is a wrapper around a PrivateTransactionManager, timing the
+// calls to it.
+type meteredManager struct {
+	PrivateTransactionManager
+}
+
+// newMeteredManager wraps a PrivateTransactionManager with latency metrics. If
+// the metrics system is disabled, this function returns the original object.
+func newMeteredManager(ptm PrivateTransactionManager) PrivateTransactionManager {
+	if !metrics.Enabled {
+		return ptm
+	}
+	return &meteredManager{ptm}
+}
+
+func (m *meteredManager) Send(data []byte, from string, to []string) ([]byte, error) {
+	defer sendTimer.UpdateSince(time.Now())
+	return m.PrivateTransactionManager.Send(data, from, to)
+}
+
+func (m *meteredManager) Receive(data []byte) ([]byte, error) {
+	defer receiveTimer.UpdateSince(time.Now())
+	return m.PrivateTransactionManager.Receive(data)
+}
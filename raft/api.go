@@ -1,5 +1,11 @@
 package raft
 
+import (
+	"net"
+
+	"github.com/InsighterInc/bxmp/p2p/discover"
+)
+
 type RaftNodeInfo struct {
 	ClusterSize    int        `json:"clusterSize"`
 	Role           string     `json:"role"`
@@ -8,6 +14,31 @@ type RaftNodeInfo struct {
 	RemovedPeerIds []uint16   `json:"removedPeerIds"`
 	AppliedIndex   uint64     `json:"appliedIndex"`
 	SnapshotIndex  uint64     `json:"snapshotIndex"`
+	RaftId         uint16     `json:"raftId"`
+	Leader         uint16     `json:"leader"` // Raft ID of the minter, 0 if none is known
+	Term           uint64     `json:"term"`
+	CommitIndex    uint64     `json:"commitIndex"`
+}
+
+// ClusterInfo describes a member of the Raft cluster as seen by this node.
+type ClusterInfo struct {
+	RaftId   uint16          `json:"raftId"`
+	NodeId   discover.NodeID `json:"nodeId"`
+	Ip       net.IP          `json:"ip"`
+	P2pPort  uint16          `json:"p2pPort"`
+	RaftPort uint16          `json:"raftPort"`
+	Role     string          `json:"role"`               // minter, verifier or learner
+	Progress *PeerProgress   `json:"progress,omitempty"` // only known by the minter
+}
+
+// PeerProgress is the replication progress of a cluster member, as tracked by
+// the minter.
+type PeerProgress struct {
+	Match  uint64 `json:"match"`  // Highest raft index known to be replicated on the member
+	Next   uint64 `json:"next"`   // Next raft index to send to the member
+	Lag    uint64 `json:"lag"`    // Number of committed raft entries the member is missing
+	State  string `json:"state"`  // Replication state: probe, replicate or snapshot
+	Active bool   `json:"active"` // Whether the minter recently heard from the member
 }
 
 type PublicRaftAPI struct {
@@ -29,3 +60,47 @@ func (s *PublicRaftAPI) AddPeer(enodeId string) (uint16, error) {
 func (s *PublicRaftAPI) RemovePeer(raftId uint16) {
 	s.raftService.raftProtocolManager.ProposePeerRemoval(raftId)
 }
+
+// AddLearner proposes adding the node as a learner, which replicates the chain
+// without voting or counting towards the quorum, and returns its raft ID.
+func (s *PublicRaftAPI) AddLearner(enodeId string) (uint16, error) {
+	return s.raftService.raftProtocolManager.ProposeNewLearner(enodeId)
+}
+
+// PromoteToPeer proposes turning the learner into a voting member.
+func (s *PublicRaftAPI) PromoteToPeer(raftId uint16) (bool, error) {
+	if err := s.raftService.raftProtocolManager.ProposePeerPromotion(raftId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TransferLeadership hands the minter role over to the voting member with the
+// given raft ID, after the blocks minted by this node have been applied. Only
+// the current minter can transfer its leadership.
+func (s *PublicRaftAPI) TransferLeadership(raftId uint16) (bool, error) {
+	if err := s.raftService.raftProtocolManager.TransferLeadership(raftId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NodeInfo returns the state of this node in the Raft cluster.
+func (s *PublicRaftAPI) NodeInfo() *RaftNodeInfo {
+	return s.raftService.raftProtocolManager.NodeInfo()
+}
+
+// Cluster returns the members of the Raft cluster, including this node. On the
+// minter, each member carries its replication progress.
+func (s *PublicRaftAPI) Cluster() []*ClusterInfo {
+	return s.raftService.raftProtocolManager.ClusterInfo()
+}
+
+// Leader returns the node ID of the current minter.
+func (s *PublicRaftAPI) Leader() (string, error) {
+	address, err := s.raftService.raftProtocolManager.LeaderAddress()
+	if err != nil {
+		return "", err
+	}
+	return address.nodeId.String(), nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package raft_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/bxmclient"
+	"github.com/InsighterInc/bxmp/raft"
+	"github.com/InsighterInc/bxmp/rpc"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// newTestRaftClient serves the Raft API of raft ID 1 in a cluster with voter
+// 2, learner 3 and the removed member 4, which sees lead as the minter.
+func newTestRaftClient(t *testing.T, lead uint64) (*rpc.Client, <-chan raftpb.ConfChange) {
+	service, confChanges := raft.NewTestRaftService(lead)
+	server := rpc.NewServer()
+	if err := server.RegisterName("raft", raft.NewPublicRaftAPI(service)); err != nil {
+		t.Fatalf("failed to register Raft API: %v", err)
+	}
+	return rpc.DialInProc(server), confChanges
+}
+
+func testAddress(raftId int) string {
+	return fmt.Sprintf(`"raftId":%d,"nodeId":"%02x%s","ip":"127.0.0.1","p2pPort":%d,"raftPort":%d`,
+		raftId, raftId, strings.Repeat("00", 63), 30300+raftId, 50400+raftId)
+}
+
+// assertJSON compares JSON values, ignoring the order of the peer addresses,
+// which the API returns unsorted.
+func assertJSON(t *testing.T, what string, have []byte, want string) {
+	var haveVal, wantVal interface{}
+	if err := json.Unmarshal(have, &haveVal); err != nil {
+		t.Fatalf("%s: invalid JSON %s: %v", what, have, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantVal); err != nil {
+		t.Fatalf("%s: invalid expected JSON %s: %v", what, want, err)
+	}
+	if info, ok := haveVal.(map[string]interface{}); ok {
+		if peers, ok := info["peerAddresses"].([]interface{}); ok {
+			sort.Slice(peers, func(i, j int) bool {
+				return peers[i].(map[string]interface{})["raftId"].(float64) < peers[j].(map[string]interface{})["raftId"].(float64)
+			})
+		}
+	}
+	if !reflect.DeepEqual(haveVal, wantVal) {
+		t.Errorf("%s mismatch:\nhave %s\nwant %s", what, have, want)
+	}
+}
+
+var (
+	testMinterCluster = `[
+		{` + testAddress(1) + `,"role":"minter","progress":{"match":12,"next":13,"lag":0,"state":"replicate","active":true}},
+		{` + testAddress(2) + `,"role":"verifier","progress":{"match":9,"next":10,"lag":3,"state":"probe","active":false}},
+		{` + testAddress(3) + `,"role":"learner","progress":{"match":12,"next":13,"lag":0,"state":"snapshot","active":true}}
+	]`
+	testMinterNodeInfo = `{
+		"clusterSize":3,"role":"minter","address":{` + testAddress(1) + `},
+		"peerAddresses":[{` + testAddress(2) + `},{` + testAddress(3) + `}],"removedPeerIds":[4],
+		"appliedIndex":0,"snapshotIndex":0,"raftId":1,"leader":1,"term":3,"commitIndex":12
+	}`
+)
+
+func TestRaftAPIJSON(t *testing.T) {
+	tests := []struct {
+		lead   uint64
+		method string
+		want   string
+	}{
+		{1, "raft_role", `"minter"`},
+		{2, "raft_role", `"verifier"`},
+		{1, "raft_leader", `"01` + strings.Repeat("00", 63) + `"`},
+		{2, "raft_leader", `"02` + strings.Repeat("00", 63) + `"`},
+		{1, "raft_cluster", testMinterCluster},
+		{2, "raft_cluster", `[
+			{` + testAddress(1) + `,"role":"verifier"},
+			{` + testAddress(2) + `,"role":"minter"},
+			{` + testAddress(3) + `,"role":"learner"}
+		]`},
+		{1, "raft_nodeInfo", testMinterNodeInfo},
+	}
+	for _, tt := range tests {
+		client, _ := newTestRaftClient(t, tt.lead)
+		var result json.RawMessage
+		if err := client.CallContext(context.Background(), &result, tt.method); err != nil {
+			t.Errorf("%s (lead %d) failed: %v", tt.method, tt.lead, err)
+			continue
+		}
+		assertJSON(t, fmt.Sprintf("%s (lead %d)", tt.method, tt.lead), result, tt.want)
+	}
+}
+
+// TestRaftClient checks that the bxmclient raft methods decode what the API
+// returns.
+func TestRaftClient(t *testing.T) {
+	rpcClient, confChanges := newTestRaftClient(t, 1)
+	client := bxmclient.NewClient(rpcClient)
+	ctx := context.Background()
+
+	if role, err := client.RaftRole(ctx); err != nil || role != "minter" {
+		t.Errorf("role mismatch: have %q (err %v), want %q", role, err, "minter")
+	}
+	if leader, err := client.RaftLeader(ctx); err != nil || leader != "01"+strings.Repeat("00", 63) {
+		t.Errorf("leader mismatch: have %q (err %v), want raft ID 1", leader, err)
+	}
+	cluster, err := client.RaftCluster(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve cluster: %v", err)
+	}
+	encoded, _ := json.Marshal(cluster)
+	assertJSON(t, "cluster", encoded, testMinterCluster)
+
+	info, err := client.RaftNodeInfo(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve node info: %v", err)
+	}
+	encoded, _ = json.Marshal(info)
+	assertJSON(t, "node info", encoded, testMinterNodeInfo)
+
+	enode := "enode://05" + strings.Repeat("00", 63) + "@127.0.0.1:30305?raftport=50405"
+	if raftId, err := client.RaftAddPeer(ctx, enode); err != nil || raftId != 5 {
+		t.Errorf("raft ID mismatch: have %d (err %v), want 5", raftId, err)
+	} else if cc := <-confChanges; cc.Type != raftpb.ConfChangeAddNode || cc.NodeID != 5 {
+		t.Errorf("conf change mismatch: have %v %d, want %v 5", cc.Type, cc.NodeID, raftpb.ConfChangeAddNode)
+	}
+	if err := client.RaftRemovePeer(ctx, 2); err != nil {
+		t.Errorf("failed to remove peer: %v", err)
+	} else if cc := <-confChanges; cc.Type != raftpb.ConfChangeRemoveNode || cc.NodeID != 2 {
+		t.Errorf("conf change mismatch: have %v %d, want %v 2", cc.Type, cc.NodeID, raftpb.ConfChangeRemoveNode)
+	}
+	want := "raft ID 3 is a learner and cannot become the minter"
+	if err := client.RaftTransferLeadership(ctx, 3); err == nil || err.Error() != want {
+		t.Errorf("transfer error mismatch: have %v, want %q", err, want)
+	}
+}
@@ -1,7 +1,8 @@
 package raft
 
 import (
-	etcdRaft "github.com/coreos/etcd/raft"
+	"errors"
+	"time"
 )
 
 const (
@@ -10,8 +11,9 @@ const (
 
 	raftMsg = 0x00
 
-	minterRole   = etcdRaft.LEADER
-	verifierRole = etcdRaft.NOT_LEADER
+	// Roles of this node, depending on whether it is the raft leader
+	minterRole   = 1
+	verifierRole = 2
 
 	// Raft's ticker interval
 	tickerMS = 100
@@ -28,8 +30,18 @@ const (
 	peerUrlKeyPrefix = "peerUrl-"
 
 	chainExtensionMessage = "Successfully extended chain"
+
+	// How long a leadership transfer may take to drain the minter and to have
+	// the transferee take over, each. Well above the election timeout, after
+	// which raft gives up on a transfer by itself.
+	leadershipTransferTimeout = 5 * time.Second
 )
 
 var (
 	appliedDbKey = []byte("applied")
+
+	errNoLeaderElected    = errors.New("no raft leader is currently elected")
+	errNotMinter          = errors.New("only the minter can transfer raft leadership")
+	errTransferInProgress = errors.New("a raft leadership transfer is already in progress")
+	errRaftStopped        = errors.New("raft stopped")
 )
@@ -0,0 +1,30 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package raft
+
+import "go.etcd.io/etcd/raft/raftpb"
+
+// NewTestRaftService returns a Raft service around the cluster of
+// newTestCluster, along with the conf changes it proposes.
+func NewTestRaftService(lead uint64) (*RaftService, <-chan raftpb.ConfChange) {
+	pm := newTestCluster(lead)
+	if lead == 1 {
+		pm.role = minterRole
+	}
+	pm.confChangeProposalC = make(chan raftpb.ConfChange, 1)
+	return &RaftService{raftProtocolManager: pm}, pm.confChangeProposalC
+}
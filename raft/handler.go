@@ -1,34 +1,38 @@
 package raft
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/net/context"
-
-	"github.com/coreos/etcd/pkg/fileutil"
-	"github.com/coreos/etcd/snap"
-	"github.com/coreos/etcd/wal"
+	"github.com/InsighterInc/bxmp/bxm/downloader"
 	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/types"
-	"github.com/InsighterInc/bxmp/bxm/downloader"
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/p2p"
 	"github.com/InsighterInc/bxmp/p2p/discover"
 	"github.com/InsighterInc/bxmp/rlp"
 
-	"github.com/coreos/etcd/etcdserver/stats"
-	raftTypes "github.com/coreos/etcd/pkg/types"
-	etcdRaft "github.com/coreos/etcd/raft"
-	"github.com/coreos/etcd/raft/raftpb"
-	"github.com/coreos/etcd/rafthttp"
+	"github.com/eapache/channels"
 	"github.com/syndtr/goleveldb/leveldb"
+	"go.etcd.io/etcd/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/etcdserver/api/snap"
+	stats "go.etcd.io/etcd/etcdserver/api/v2stats"
+	"go.etcd.io/etcd/pkg/fileutil"
+	raftTypes "go.etcd.io/etcd/pkg/types"
+	etcdRaft "go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.etcd.io/etcd/raft/tracker"
+	"go.etcd.io/etcd/wal"
 	"gopkg.in/fatih/set.v0"
 )
 
@@ -37,6 +41,8 @@ type ProtocolManager struct {
 	quitSync chan struct{}
 	stopped  bool
 
+	transferring int32 // Atomic flag set while handing over leadership
+
 	// Static configuration
 	joinExisting   bool // Whether to join an existing cluster when a WAL doesn't already exist
 	bootstrapNodes []*discover.Node
@@ -52,6 +58,7 @@ type ProtocolManager struct {
 	// Remote peer state (protected by mu vs concurrent access via JS)
 	peers        map[uint16]*Peer
 	removedPeers *set.Set // *Permanently removed* peers
+	learners     *set.Set // Raft IDs of the non-voting members, possibly including our own
 
 	// P2P transport
 	p2pServer *p2p.Server // Initialized in start()
@@ -69,6 +76,9 @@ type ProtocolManager struct {
 	blockProposalC      chan *types.Block      // for mined blocks to raft
 	confChangeProposalC chan raftpb.ConfChange // for config changes from js console to raft
 
+	// Role changes of this node, reported by the event loop
+	roleC *channels.RingChannel
+
 	// Raft transport
 	unsafeRawNode etcdRaft.Node
 	transport     *rafthttp.Transport
@@ -102,16 +112,18 @@ func NewProtocolManager(raftId uint16, raftPort uint16, blockchain *core.BlockCh
 		bootstrapNodes:      bootstrapNodes,
 		peers:               make(map[uint16]*Peer),
 		removedPeers:        set.New(),
+		learners:            set.New(),
 		joinExisting:        joinExisting,
 		blockchain:          blockchain,
 		eventMux:            mux,
 		blockProposalC:      make(chan *types.Block),
 		confChangeProposalC: make(chan raftpb.ConfChange),
+		roleC:               channels.NewRingChannel(1),
 		httpstopc:           make(chan struct{}),
 		httpdonec:           make(chan struct{}),
 		waldir:              waldir,
 		snapdir:             snapdir,
-		snapshotter:         snap.New(snapdir),
+		snapshotter:         snap.New(nil, snapdir),
 		raftId:              raftId,
 		raftPort:            raftPort,
 		quitSync:            make(chan struct{}),
@@ -178,16 +190,11 @@ func (pm *ProtocolManager) Stop() {
 }
 
 func (pm *ProtocolManager) NodeInfo() *RaftNodeInfo {
+	status := pm.rawNode().Status() // before locking, raft doesn't need pm.mu to answer
+
 	pm.mu.RLock() // as we read role and peers
 	defer pm.mu.RUnlock()
 
-	var roleDescription string
-	if pm.role == minterRole {
-		roleDescription = "minter"
-	} else {
-		roleDescription = "verifier"
-	}
-
 	peerAddresses := make([]*Address, len(pm.peers))
 	peerIdx := 0
 	for _, peer := range pm.peers {
@@ -207,12 +214,105 @@ func (pm *ProtocolManager) NodeInfo() *RaftNodeInfo {
 	//
 	return &RaftNodeInfo{
 		ClusterSize:    len(pm.peers) + 1,
-		Role:           roleDescription,
+		Role:           pm.roleDescription(pm.raftId, pm.role),
 		Address:        pm.address,
 		PeerAddresses:  peerAddresses,
 		RemovedPeerIds: removedPeerIds,
 		AppliedIndex:   pm.appliedIndex,
 		SnapshotIndex:  pm.snapshotIndex,
+		RaftId:         pm.raftId,
+		Leader:         uint16(status.Lead),
+		Term:           status.Term,
+		CommitIndex:    status.Commit,
+	}
+}
+
+// ClusterInfo returns the members of the cluster, including this node. The
+// replication progress of the members is only known if this node is the minter.
+func (pm *ProtocolManager) ClusterInfo() []*ClusterInfo {
+	status := pm.rawNode().Status()
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	addresses := make([]*Address, 0, len(pm.peers)+1)
+	if pm.address != nil {
+		addresses = append(addresses, pm.address)
+	}
+	for _, peer := range pm.peers {
+		addresses = append(addresses, peer.address)
+	}
+	members := make([]*ClusterInfo, len(addresses))
+	for i, address := range addresses {
+		member := &ClusterInfo{
+			RaftId:   address.raftId,
+			NodeId:   address.nodeId,
+			Ip:       address.ip,
+			P2pPort:  address.p2pPort,
+			RaftPort: address.raftPort,
+		}
+		if uint64(address.raftId) == status.Lead {
+			member.Role = pm.roleDescription(address.raftId, minterRole)
+		} else {
+			member.Role = pm.roleDescription(address.raftId, verifierRole)
+		}
+		if progress, ok := status.Progress[uint64(address.raftId)]; ok {
+			member.Progress = newPeerProgress(progress, status.Commit)
+		}
+		members[i] = member
+	}
+	sort.Sort(membersByRaftId(members))
+	return members
+}
+
+type membersByRaftId []*ClusterInfo
+
+func (m membersByRaftId) Len() int           { return len(m) }
+func (m membersByRaftId) Less(i, j int) bool { return m[i].RaftId < m[j].RaftId }
+func (m membersByRaftId) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// LeaderAddress returns the address of the current minter.
+func (pm *ProtocolManager) LeaderAddress() (*Address, error) {
+	lead := uint16(pm.rawNode().Status().Lead)
+	if lead == 0 {
+		return nil, errNoLeaderElected
+	}
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if lead == pm.raftId && pm.address != nil {
+		return pm.address, nil
+	}
+	if peer := pm.peers[lead]; peer != nil {
+		return peer.address, nil
+	}
+	return nil, fmt.Errorf("unknown raft leader %d", lead)
+}
+
+// roleDescription describes the role of a member. The caller must hold pm.mu.
+func (pm *ProtocolManager) roleDescription(raftId uint16, role int) string {
+	switch {
+	case pm.learners.Has(raftId):
+		return "learner"
+	case role == minterRole:
+		return "minter"
+	default:
+		return "verifier"
+	}
+}
+
+func newPeerProgress(progress tracker.Progress, commit uint64) *PeerProgress {
+	var lag uint64
+	if commit > progress.Match {
+		lag = commit - progress.Match
+	}
+	return &PeerProgress{
+		Match:  progress.Match,
+		Next:   progress.Next,
+		Lag:    lag,
+		State:  strings.ToLower(strings.TrimPrefix(progress.State.String(), "State")),
+		Active: progress.RecentActive,
 	}
 }
 
@@ -284,7 +384,35 @@ func (pm *ProtocolManager) isP2pNodeInCluster(node *discover.Node) bool {
 	return false
 }
 
+// updateLearners records which members are learners according to raft.
+func (pm *ProtocolManager) updateLearners(confState raftpb.ConfState) {
+	learners := set.New()
+	for _, rawRaftId := range confState.Learners {
+		learners.Add(uint16(rawRaftId))
+	}
+	pm.mu.Lock()
+	pm.learners = learners
+	pm.mu.Unlock()
+}
+
+func (pm *ProtocolManager) isLearner(raftId uint16) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return pm.learners.Has(raftId)
+}
+
 func (pm *ProtocolManager) ProposeNewPeer(enodeId string) (uint16, error) {
+	return pm.proposeNewMember(enodeId, false)
+}
+
+// ProposeNewLearner proposes adding the node as a learner, which replicates the
+// chain but does not vote and does not count towards the quorum.
+func (pm *ProtocolManager) ProposeNewLearner(enodeId string) (uint16, error) {
+	return pm.proposeNewMember(enodeId, true)
+}
+
+func (pm *ProtocolManager) proposeNewMember(enodeId string, learner bool) (uint16, error) {
 	node, err := discover.ParseNode(enodeId)
 	if err != nil {
 		return 0, err
@@ -305,8 +433,12 @@ func (pm *ProtocolManager) ProposeNewPeer(enodeId string) (uint16, error) {
 	raftId := pm.nextRaftId()
 	address := newAddress(raftId, node.RaftPort, node)
 
+	confChangeType := raftpb.ConfChangeAddNode
+	if learner {
+		confChangeType = raftpb.ConfChangeAddLearnerNode
+	}
 	pm.confChangeProposalC <- raftpb.ConfChange{
-		Type:    raftpb.ConfChangeAddNode,
+		Type:    confChangeType,
 		NodeID:  uint64(raftId),
 		Context: address.toBytes(),
 	}
@@ -314,6 +446,95 @@ func (pm *ProtocolManager) ProposeNewPeer(enodeId string) (uint16, error) {
 	return raftId, nil
 }
 
+// ProposePeerPromotion proposes turning the learner into a voting member.
+func (pm *ProtocolManager) ProposePeerPromotion(raftId uint16) error {
+	pm.mu.RLock()
+	var address *Address
+	if raftId == pm.raftId {
+		address = pm.address
+	} else if peer := pm.peers[raftId]; peer != nil {
+		address = peer.address
+	}
+	learner := pm.learners.Has(raftId)
+	pm.mu.RUnlock()
+
+	if address == nil {
+		return fmt.Errorf("raft ID %d is not a member of the cluster", raftId)
+	}
+	if !learner {
+		return fmt.Errorf("raft ID %d is not a learner", raftId)
+	}
+	pm.confChangeProposalC <- raftpb.ConfChange{
+		Type:    raftpb.ConfChangeAddNode,
+		NodeID:  uint64(raftId),
+		Context: address.toBytes(),
+	}
+	return nil
+}
+
+// TransferLeadership hands the minter role over to the given voting member.
+// Minting is stopped first and the blocks already minted are given the chance
+// to be applied, so the transferee carries on from the same head without a gap
+// in block production. If the transfer does not go through, this node resumes
+// minting.
+func (pm *ProtocolManager) TransferLeadership(raftId uint16) error {
+	pm.mu.RLock()
+	_, isPeer := pm.peers[raftId]
+	learner := pm.learners.Has(raftId)
+	pm.mu.RUnlock()
+
+	switch {
+	case raftId == pm.raftId:
+		return fmt.Errorf("raft ID %d is this node", raftId)
+	case !isPeer:
+		return fmt.Errorf("raft ID %d is not a member of the cluster", raftId)
+	case learner:
+		return fmt.Errorf("raft ID %d is a learner and cannot become the minter", raftId)
+	}
+	if !atomic.CompareAndSwapInt32(&pm.transferring, 0, 1) {
+		return errTransferInProgress
+	}
+	defer atomic.StoreInt32(&pm.transferring, 0)
+
+	if pm.rawNode().Status().Lead != uint64(pm.raftId) {
+		return errNotMinter
+	}
+	log.Info("transferring raft leadership", "to", raftId)
+
+	// Raft drops proposals while a transfer is pending, so make sure all our
+	// minted blocks are through before starting it.
+	if err := pm.minter.drain(leadershipTransferTimeout); err != nil {
+		pm.resumeMinting()
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leadershipTransferTimeout)
+	defer cancel()
+
+	pm.rawNode().TransferLeadership(ctx, uint64(pm.raftId), uint64(raftId))
+	for {
+		if pm.rawNode().Status().Lead == uint64(raftId) {
+			log.Info("transferred raft leadership", "to", raftId)
+			return nil
+		}
+		select {
+		case <-time.After(tickerMS * time.Millisecond):
+		case <-ctx.Done():
+			pm.resumeMinting()
+			return fmt.Errorf("raft ID %d did not take over leadership within %v", raftId, leadershipTransferTimeout)
+		case <-pm.quitSync:
+			return errRaftStopped
+		}
+	}
+}
+
+// resumeMinting restarts the minter after a failed leadership transfer, unless
+// the leadership moved elsewhere in the meantime.
+func (pm *ProtocolManager) resumeMinting() {
+	if pm.rawNode().Status().Lead == uint64(pm.raftId) {
+		pm.minter.start()
+	}
+}
+
 func (pm *ProtocolManager) ProposePeerRemoval(raftId uint16) {
 	pm.confChangeProposalC <- raftpb.ConfChange{
 		Type:   raftpb.ConfChangeRemoveNode,
@@ -374,17 +595,17 @@ func (pm *ProtocolManager) startRaft() {
 	walExisted := wal.Exist(pm.waldir)
 	lastAppliedIndex := pm.loadAppliedIndex()
 
-	ss := &stats.ServerStats{}
-	ss.Initialize()
 	pm.transport = &rafthttp.Transport{
 		ID:          raftTypes.ID(pm.raftId),
 		ClusterID:   0x1000,
 		Raft:        pm,
-		ServerStats: ss,
+		ServerStats: stats.NewServerStats("", ""),
 		LeaderStats: stats.NewLeaderStats(strconv.Itoa(int(pm.raftId))),
 		ErrorC:      make(chan error),
 	}
-	pm.transport.Start()
+	if err := pm.transport.Start(); err != nil {
+		fatalf("failed to start raft transport (%v)", err)
+	}
 
 	// We load the snapshot to connect to prev peers before replaying the WAL,
 	// which typically goes further into the future than the snapshot.
@@ -423,13 +644,13 @@ func (pm *ProtocolManager) startRaft() {
 		Storage:       pm.raftStorage,
 
 		// NOTE, from cockroach:
-		// "PreVote and CheckBitmed are two ways of achieving the same thing.
+		// "PreVote and CheckQuorum are two ways of achieving the same thing.
 		// PreVote is more compatible with quiesced ranges, so we want to switch
 		// to it once we've worked out the bugs."
 		//
 		// TODO: vendor again?
 		// PreVote:     enablePreVote,
-		CheckBitmed: !enablePreVote,
+		CheckQuorum: !enablePreVote,
 
 		// MaxSizePerMsg controls how many Raft log entries the leader will send to
 		// followers in a single MsgApp.
@@ -453,8 +674,10 @@ func (pm *ProtocolManager) startRaft() {
 		log.Info("remounting an existing raft log; connecting to peers.")
 		pm.unsafeRawNode = etcdRaft.RestartNode(raftConfig)
 	} else if pm.joinExisting {
+		// Raft only bootstraps a log from a list of peers, so a newcomer starts
+		// out empty and learns the cluster from the snapshot sent by the leader.
 		log.Info("newly joining an existing cluster; waiting for connections.")
-		pm.unsafeRawNode = etcdRaft.StartNode(raftConfig, nil)
+		pm.unsafeRawNode = etcdRaft.RestartNode(raftConfig)
 	} else {
 		if numPeers := len(pm.bootstrapNodes); numPeers == 0 {
 			panic("exiting due to empty raft peers list")
@@ -480,7 +703,7 @@ func (pm *ProtocolManager) startRaft() {
 	go pm.serveRaft()
 	go pm.serveLocalProposals()
 	go pm.eventLoop()
-	go pm.handleRoleChange(pm.rawNode().RoleChan().Out())
+	go pm.handleRoleChange(pm.roleC.Out())
 }
 
 func (pm *ProtocolManager) setLocalAddress(addr *Address) {
@@ -540,6 +763,8 @@ func (pm *ProtocolManager) handleRoleChange(roleC <-chan interface{}) {
 			pm.role = intRole
 			pm.mu.Unlock()
 
+			roleGauge.Update(roleGaugeValue(intRole))
+
 		case <-pm.quitSync:
 			return
 		}
@@ -669,6 +894,7 @@ func (pm *ProtocolManager) eventLoop() {
 	defer pm.wal.Close()
 
 	exitAfterApplying := false
+	lead := etcdRaft.None
 
 	for {
 		select {
@@ -678,7 +904,19 @@ func (pm *ProtocolManager) eventLoop() {
 		// when the node is first ready it gives us entries to commit and messages
 		// to immediately publish
 		case rd := <-pm.rawNode().Ready():
+			if rd.SoftState != nil && rd.SoftState.Lead != lead {
+				lead = rd.SoftState.Lead
+				if lead == uint64(pm.raftId) {
+					pm.roleC.In() <- minterRole
+				} else {
+					pm.roleC.In() <- verifierRole
+				}
+			}
+
 			pm.wal.Save(rd.HardState, rd.Entries)
+			if !etcdRaft.IsEmptyHardState(rd.HardState) {
+				termGauge.Update(int64(rd.HardState.Term))
+			}
 
 			if snap := rd.Snapshot; !etcdRaft.IsEmptySnap(snap) {
 				pm.saveRaftSnapshot(snap)
@@ -728,14 +966,20 @@ func (pm *ProtocolManager) eventLoop() {
 					cc.Unmarshal(entry.Data)
 					raftId := uint16(cc.NodeID)
 
+					wasLearner := pm.isLearner(raftId)
 					pm.confState = *pm.rawNode().ApplyConfChange(cc)
+					pm.updateLearners(pm.confState)
 
 					forceSnapshot := false
 
 					switch cc.Type {
-					case raftpb.ConfChangeAddNode:
+					case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
 						if pm.isRaftIdRemoved(raftId) {
 							log.Info("ignoring ConfChangeAddNode for permanently-removed peer", "raft id", raftId)
+						} else if cc.Type == raftpb.ConfChangeAddNode && wasLearner {
+							log.Info("promoted learner to peer due to ConfChangeAddNode", "raft id", raftId)
+
+							forceSnapshot = true
 						} else if raftId <= uint16(len(pm.bootstrapNodes)) {
 							// See initial cluster logic in startRaft() for more information.
 							log.Info("ignoring expected ConfChangeAddNode for initial peer", "raft id", raftId)
@@ -745,7 +989,7 @@ func (pm *ProtocolManager) eventLoop() {
 						} else if pm.isRaftIdUsed(raftId) {
 							log.Info("ignoring ConfChangeAddNode for already-used raft ID", "raft id", raftId)
 						} else {
-							log.Info("adding peer due to ConfChangeAddNode", "raft id", raftId)
+							log.Info("adding peer due to ConfChangeAddNode", "raft id", raftId, "learner", cc.Type == raftpb.ConfChangeAddLearnerNode)
 
 							forceSnapshot = true
 							pm.addPeer(bytesToAddress(cc.Context))
@@ -880,4 +1124,6 @@ func (pm *ProtocolManager) advanceAppliedIndex(index uint64) {
 	pm.mu.Lock()
 	pm.appliedIndex = index
 	pm.mu.Unlock()
+
+	appliedGauge.Update(int64(index))
 }
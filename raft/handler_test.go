@@ -0,0 +1,315 @@
+package raft
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/InsighterInc/bxmp/p2p/discover"
+	etcdRaft "go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.etcd.io/etcd/raft/tracker"
+	"gopkg.in/fatih/set.v0"
+)
+
+// testRaftNode drives a raft node synchronously, applying the committed
+// entries the way the event loop of the protocol manager does.
+type testRaftNode struct {
+	*etcdRaft.RawNode
+	storage   *etcdRaft.MemoryStorage
+	confState raftpb.ConfState
+	committed [][]byte
+	messages  []raftpb.Message
+}
+
+func newTestRaftConfig(id uint64, storage *etcdRaft.MemoryStorage) *etcdRaft.Config {
+	return &etcdRaft.Config{
+		ID:              id,
+		ElectionTick:    10,
+		HeartbeatTick:   1,
+		Storage:         storage,
+		MaxSizePerMsg:   4096,
+		MaxInflightMsgs: 256,
+	}
+}
+
+// newTestMinterNode starts a single member cluster and elects its only node.
+func newTestMinterNode(t *testing.T) *testRaftNode {
+	storage := etcdRaft.NewMemoryStorage()
+	rawNode, err := etcdRaft.NewRawNode(newTestRaftConfig(1, storage))
+	if err != nil {
+		t.Fatalf("failed to create raft node: %v", err)
+	}
+	if err := rawNode.Bootstrap([]etcdRaft.Peer{{ID: 1}}); err != nil {
+		t.Fatalf("failed to bootstrap raft node: %v", err)
+	}
+	node := &testRaftNode{RawNode: rawNode, storage: storage}
+	node.process()
+	if err := node.Campaign(); err != nil {
+		t.Fatalf("failed to campaign: %v", err)
+	}
+	node.process()
+	if lead := node.Status().Lead; lead != 1 {
+		t.Fatalf("leader mismatch: have %d, want 1", lead)
+	}
+	return node
+}
+
+func (node *testRaftNode) process() {
+	for node.HasReady() {
+		rd := node.Ready()
+		node.storage.Append(rd.Entries)
+		node.messages = append(node.messages, rd.Messages...)
+		for _, entry := range rd.CommittedEntries {
+			switch entry.Type {
+			case raftpb.EntryNormal:
+				if len(entry.Data) > 0 {
+					node.committed = append(node.committed, entry.Data)
+				}
+			case raftpb.EntryConfChange:
+				var cc raftpb.ConfChange
+				cc.Unmarshal(entry.Data)
+				node.confState = *node.ApplyConfChange(cc)
+			}
+		}
+		node.Advance(rd)
+	}
+}
+
+func newTestProtocolManager(raftId uint16) *ProtocolManager {
+	return &ProtocolManager{
+		raftId:       raftId,
+		peers:        make(map[uint16]*Peer),
+		removedPeers: set.New(),
+		learners:     set.New(),
+		quitSync:     make(chan struct{}),
+	}
+}
+
+// testStatusNode is a raft node that only reports a fixed status.
+type testStatusNode struct {
+	etcdRaft.Node
+	status etcdRaft.Status
+}
+
+func (node *testStatusNode) Status() etcdRaft.Status { return node.status }
+
+func newTestAddress(raftId uint16) *Address {
+	return &Address{
+		raftId:   raftId,
+		nodeId:   discover.NodeID{byte(raftId)},
+		ip:       net.IPv4(127, 0, 0, 1).To4(),
+		p2pPort:  30300 + raftId,
+		raftPort: 50400 + raftId,
+	}
+}
+
+// newTestCluster returns the protocol manager of raft ID 1 in a cluster with
+// voter 2, learner 3 and the removed member 4, which sees lead as the minter.
+func newTestCluster(lead uint64) *ProtocolManager {
+	pm := newTestProtocolManager(1)
+	pm.address = newTestAddress(1)
+	for _, raftId := range []uint16{2, 3} {
+		address := newTestAddress(raftId)
+		pm.peers[raftId] = &Peer{address, discover.NewNode(address.nodeId, address.ip, 0, address.p2pPort)}
+	}
+	pm.learners.Add(uint16(3))
+	pm.removedPeers.Add(uint16(4))
+
+	status := etcdRaft.Status{}
+	status.ID, status.Lead, status.Term, status.Commit = 1, lead, 3, 12
+	if lead == 1 {
+		status.Progress = map[uint64]tracker.Progress{
+			1: {Match: 12, Next: 13, State: tracker.StateReplicate, RecentActive: true},
+			2: {Match: 9, Next: 10, State: tracker.StateProbe},
+			3: {Match: 12, Next: 13, State: tracker.StateSnapshot, RecentActive: true, IsLearner: true},
+		}
+	}
+	pm.unsafeRawNode = &testStatusNode{status: status}
+	return pm
+}
+
+func TestClusterInfo(t *testing.T) {
+	members := func(roles ...string) []*ClusterInfo {
+		members := make([]*ClusterInfo, len(roles))
+		for i, role := range roles {
+			address := newTestAddress(uint16(i + 1))
+			members[i] = &ClusterInfo{address.raftId, address.nodeId, address.ip, address.p2pPort, address.raftPort, role, nil}
+		}
+		return members
+	}
+	// The minter knows the replication progress of every member
+	want := members("minter", "verifier", "learner")
+	want[0].Progress = &PeerProgress{Match: 12, Next: 13, Lag: 0, State: "replicate", Active: true}
+	want[1].Progress = &PeerProgress{Match: 9, Next: 10, Lag: 3, State: "probe", Active: false}
+	want[2].Progress = &PeerProgress{Match: 12, Next: 13, Lag: 0, State: "snapshot", Active: true}
+	if have := newTestCluster(1).ClusterInfo(); !reflect.DeepEqual(have, want) {
+		t.Errorf("minter cluster mismatch:\nhave %v\nwant %v", have, want)
+	}
+	// A verifier only knows the members and their roles
+	want = members("verifier", "minter", "learner")
+	if have := newTestCluster(2).ClusterInfo(); !reflect.DeepEqual(have, want) {
+		t.Errorf("verifier cluster mismatch:\nhave %v\nwant %v", have, want)
+	}
+}
+
+func TestNodeInfo(t *testing.T) {
+	pm := newTestCluster(1)
+	pm.role, pm.appliedIndex, pm.snapshotIndex = minterRole, 11, 10
+
+	info := pm.NodeInfo()
+	sort.Slice(info.PeerAddresses, func(i, j int) bool { return info.PeerAddresses[i].raftId < info.PeerAddresses[j].raftId })
+	want := &RaftNodeInfo{
+		ClusterSize:    3,
+		Role:           "minter",
+		Address:        pm.address,
+		PeerAddresses:  []*Address{pm.peers[2].address, pm.peers[3].address},
+		RemovedPeerIds: []uint16{4},
+		AppliedIndex:   11,
+		SnapshotIndex:  10,
+		RaftId:         1,
+		Leader:         1,
+		Term:           3,
+		CommitIndex:    12,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("node info mismatch:\nhave %+v\nwant %+v", info, want)
+	}
+}
+
+func TestLeaderAddress(t *testing.T) {
+	for _, lead := range []uint16{1, 2} {
+		address, err := newTestCluster(uint64(lead)).LeaderAddress()
+		if err != nil {
+			t.Fatalf("failed to retrieve leader %d: %v", lead, err)
+		}
+		if !reflect.DeepEqual(address, newTestAddress(lead)) {
+			t.Errorf("leader address mismatch: have %v, want %v", address, newTestAddress(lead))
+		}
+	}
+	if _, err := newTestCluster(0).LeaderAddress(); err != errNoLeaderElected {
+		t.Errorf("error mismatch without leader: have %v, want %v", err, errNoLeaderElected)
+	}
+	if _, err := newTestCluster(5).LeaderAddress(); err == nil {
+		t.Errorf("expected error for unknown leader")
+	}
+}
+
+// Tests that leadership is only handed over to voting members, and only by the
+// minter. The rejections happen before the minter is drained.
+func TestTransferLeadershipRejected(t *testing.T) {
+	pm := newTestCluster(1)
+	for _, raftId := range []uint16{1, 3, 4, 5} {
+		if err := pm.TransferLeadership(raftId); err == nil {
+			t.Errorf("transfer to raft ID %d accepted", raftId)
+		}
+	}
+	atomic.StoreInt32(&pm.transferring, 1)
+	if err := pm.TransferLeadership(2); err != errTransferInProgress {
+		t.Errorf("error mismatch during transfer: have %v, want %v", err, errTransferInProgress)
+	}
+	pm = newTestCluster(2)
+	if err := pm.TransferLeadership(2); err != errNotMinter {
+		t.Errorf("error mismatch on verifier: have %v, want %v", err, errNotMinter)
+	}
+	if transferring := atomic.LoadInt32(&pm.transferring); transferring != 0 {
+		t.Errorf("transfer still marked in progress after rejection")
+	}
+}
+
+// Tests that a learner does not count towards the quorum until it is promoted.
+func TestLearnerPromotion(t *testing.T) {
+	node := newTestMinterNode(t)
+	pm := newTestProtocolManager(1)
+
+	// Add the learner, which the single voter can commit blocks without
+	if err := node.ProposeConfChange(raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 2}); err != nil {
+		t.Fatalf("failed to propose learner: %v", err)
+	}
+	node.process()
+	if !reflect.DeepEqual(node.confState.Voters, []uint64{1}) || !reflect.DeepEqual(node.confState.Learners, []uint64{2}) {
+		t.Fatalf("conf state mismatch: voters %v, learners %v", node.confState.Voters, node.confState.Learners)
+	}
+	pm.updateLearners(node.confState)
+	if !pm.isLearner(2) || pm.isLearner(1) {
+		t.Errorf("learners mismatch: have %v, want [2]", pm.learners.List())
+	}
+	if role := pm.roleDescription(2, verifierRole); role != "learner" {
+		t.Errorf("role mismatch: have %s, want learner", role)
+	}
+	node.Propose([]byte("block 1"))
+	node.process()
+	if len(node.committed) != 1 {
+		t.Fatalf("block not committed without the learner: %d committed", len(node.committed))
+	}
+	// Promote the learner, after which blocks need its vote too
+	if err := node.ProposeConfChange(raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 2}); err != nil {
+		t.Fatalf("failed to propose promotion: %v", err)
+	}
+	node.process()
+	if !reflect.DeepEqual(node.confState.Voters, []uint64{1, 2}) || len(node.confState.Learners) != 0 {
+		t.Fatalf("conf state mismatch: voters %v, learners %v", node.confState.Voters, node.confState.Learners)
+	}
+	pm.updateLearners(node.confState)
+	if pm.isLearner(2) {
+		t.Errorf("promoted learner still recorded as learner")
+	}
+	if role := pm.roleDescription(2, verifierRole); role != "verifier" {
+		t.Errorf("role mismatch: have %s, want verifier", role)
+	}
+	node.Propose([]byte("block 2"))
+	node.process()
+	if len(node.committed) != 1 {
+		t.Errorf("block committed without the promoted member: %d committed", len(node.committed))
+	}
+}
+
+// Tests that a node restored from a snapshot with learners in its ConfState
+// knows the learners, and doesn't campaign if it is one itself.
+func TestSnapshotRestoreLearners(t *testing.T) {
+	confState := raftpb.ConfState{Voters: []uint64{1}, Learners: []uint64{2, 3}}
+	snapshot := raftpb.Snapshot{
+		Metadata: raftpb.SnapshotMetadata{ConfState: confState, Index: 10, Term: 2},
+		Data:     (&Snapshot{}).toBytes(),
+	}
+	storage := etcdRaft.NewMemoryStorage()
+	if err := storage.ApplySnapshot(snapshot); err != nil {
+		t.Fatalf("failed to apply snapshot: %v", err)
+	}
+	rawNode, err := etcdRaft.NewRawNode(newTestRaftConfig(2, storage))
+	if err != nil {
+		t.Fatalf("failed to restart raft node: %v", err)
+	}
+	node := &testRaftNode{RawNode: rawNode, storage: storage}
+
+	config := node.Status().Config
+	if _, ok := config.Learners[2]; !ok || len(config.Learners) != 2 {
+		t.Errorf("learners mismatch: have %v, want [2 3]", config.Learners)
+	}
+	if ids := config.Voters.IDs(); len(ids) != 1 {
+		t.Errorf("voters mismatch: have %v, want [1]", ids)
+	}
+	for i := 0; i < 50; i++ {
+		node.Tick()
+		node.process()
+	}
+	for _, msg := range node.messages {
+		if msg.Type == raftpb.MsgVote || msg.Type == raftpb.MsgPreVote {
+			t.Fatalf("learner campaigned: %v", msg.Type)
+		}
+	}
+	if state := node.Status().RaftState; state != etcdRaft.StateFollower {
+		t.Errorf("learner state mismatch: have %v, want %v", state, etcdRaft.StateFollower)
+	}
+	// The protocol manager takes the learners and members from the snapshot
+	pm := newTestProtocolManager(2)
+	pm.updateLearners(snapshot.Metadata.ConfState)
+	if !pm.isLearner(2) || !pm.isLearner(3) || pm.isLearner(1) {
+		t.Errorf("learners mismatch: have %v, want [2 3]", pm.learners.List())
+	}
+	if ids := confStateIds(snapshot.Metadata.ConfState); !reflect.DeepEqual(ids, []uint64{1, 2, 3}) {
+		t.Errorf("members mismatch: have %v, want [1 2 3]", ids)
+	}
+}
@@ -0,0 +1,19 @@
+package raft
+
+import (
+	"github.com/InsighterInc/bxmp/metrics"
+)
+
+var (
+	roleGauge    = metrics.NewGauge("raft/role")    // 1 while minting, 0 while verifying
+	termGauge    = metrics.NewGauge("raft/term")    // Current raft term
+	appliedGauge = metrics.NewGauge("raft/applied") // Index of the last applied raft entry
+)
+
+// roleGaugeValue is the value of the role gauge for the given raft role.
+func roleGaugeValue(role int) int64 {
+	if role == minterRole {
+		return 1
+	}
+	return 0
+}
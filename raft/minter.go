@@ -29,7 +29,6 @@ import (
 	"github.com/InsighterInc/bxmp/core"
 	"github.com/InsighterInc/bxmp/core/state"
 	"github.com/InsighterInc/bxmp/core/types"
-	"github.com/InsighterInc/bxmp/core/vm"
 	"github.com/InsighterInc/bxmp/bxmdb"
 	"github.com/InsighterInc/bxmp/event"
 	"github.com/InsighterInc/bxmp/log"
@@ -106,6 +105,31 @@ func (minter *minter) stop() {
 	atomic.StoreInt32(&minter.minting, 0)
 }
 
+// drain stops minting new blocks, but unlike stop keeps the speculative chain,
+// waiting until the blocks already minted have made it through raft into the
+// chain. This way no minted transactions are dropped when handing the minter
+// role over to another node.
+func (minter *minter) drain(timeout time.Duration) error {
+	minter.mu.Lock()
+	atomic.StoreInt32(&minter.minting, 0)
+	minter.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		minter.mu.Lock()
+		pending := minter.speculativeChain.unappliedBlocks.Size()
+		minter.mu.Unlock()
+
+		if pending == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d minted blocks still not applied after %v", pending, timeout)
+		}
+		time.Sleep(tickerMS * time.Millisecond)
+	}
+}
+
 // Notify the minting loop that minting should occur, if it's not already been
 // requested. Due to the use of a RingChannel, this function is idempotent if
 // called multiple times before the minting occurs.
@@ -161,7 +185,12 @@ func (minter *minter) eventLoop() {
 				minter.requestMinting()
 			} else {
 				minter.mu.Lock()
-				minter.speculativeChain.setHead(newHeadBlock)
+				if minter.speculativeChain.unappliedBlocks.Empty() {
+					minter.speculativeChain.setHead(newHeadBlock)
+				} else {
+					// Draining: still account for the blocks we minted
+					minter.speculativeChain.accept(newHeadBlock)
+				}
 				minter.mu.Unlock()
 			}
 
@@ -270,14 +299,14 @@ func (minter *minter) createWork() *work {
 	}
 }
 
-func (minter *minter) getTransactions() *types.TransactionsByPriceAndNonce {
+func (minter *minter) getTransactions() *types.TransactionsByRoundRobin {
 	allAddrTxes, err := minter.bxm.TxPool().Pending()
 	if err != nil { // TODO: handle
 		panic(err)
 	}
 	addrTxes := minter.speculativeChain.withoutProposedTxes(allAddrTxes)
 	signer := types.MakeSigner(minter.chain.Config(), minter.chain.CurrentBlock().Number())
-	return types.NewTransactionsByPriceAndNonce(signer, addrTxes)
+	return types.NewTransactionsByRoundRobin(signer, addrTxes)
 }
 
 // Sends-off events asynchronously.
@@ -299,6 +328,11 @@ func (minter *minter) mintNewBlock() {
 	minter.mu.Lock()
 	defer minter.mu.Unlock()
 
+	// Minting may have been stopped while we were waiting for the lock
+	if atomic.LoadInt32(&minter.minting) == 0 {
+		return
+	}
+
 	work := minter.createWork()
 	transactions := minter.getTransactions()
 
@@ -350,13 +384,15 @@ func (minter *minter) mintNewBlock() {
 	log.Info("🔨  Mined block", "number", block.Number(), "hash", fmt.Sprintf("%x", block.Hash().Bytes()[:4]), "elapsed", elapsed)
 }
 
-func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc *core.BlockChain) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
+func (env *work) commitTransactions(txes *types.TransactionsByRoundRobin, bc *core.BlockChain) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
 	var allLogs []*types.Log
 	var committedTxes types.Transactions
 	var publicReceipts types.Receipts
 	var privateReceipts types.Receipts
 
 	gp := new(core.GasPool).AddGas(env.header.GasLimit)
+	signer := types.MakeSigner(bc.Config(), env.header.Number)
+	policy := bc.AccountPolicy()
 	txCount := 0
 
 	for {
@@ -365,7 +401,15 @@ func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc
 			break
 		}
 
+		from, _ := types.Sender(signer, tx)
+		if err := policy.Check(from, tx.To() == nil); err != nil {
+			log.Info("TX not permitted by the account policy, will be removed", "hash", tx.Hash(), "err", err)
+			txes.Pop() // skip rest of txes from this account
+			continue
+		}
+
 		env.publicState.Prepare(tx.Hash(), common.Hash{}, txCount)
+		env.privateState.Prepare(tx.Hash(), common.Hash{}, txCount)
 
 		publicReceipt, privateReceipt, err := env.commitTransaction(tx, bc, gp)
 		switch {
@@ -396,7 +440,7 @@ func (env *work) commitTransaction(tx *types.Transaction, bc *core.BlockChain, g
 	privateSnapshot := env.privateState.Snapshot()
 
 	var author *common.Address
-	var vmConf vm.Config
+	vmConf := *bc.GetVMConfig()
 	publicReceipt, privateReceipt, _, err := core.ApplyTransaction(env.config, bc, author, gp, env.publicState, env.privateState, env.header, tx, env.header.GasUsed, vmConf)
 	if err != nil {
 		env.publicState.RevertToSnapshot(publicSnapshot)
@@ -0,0 +1,86 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package raft
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/InsighterInc/bxmp/core/types"
+)
+
+func newTestMintingMinter(blocks int) *minter {
+	minter := &minter{minting: 1, speculativeChain: newSpeculativeChain()}
+	minter.speculativeChain.clear(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)}))
+	for i := 1; i <= blocks; i++ {
+		minter.speculativeChain.extend(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))}))
+	}
+	return minter
+}
+
+// Tests that draining stops minting and waits for the minted blocks to be
+// applied.
+func TestMinterDrain(t *testing.T) {
+	minter := newTestMintingMinter(2)
+
+	errc := make(chan error, 1)
+	go func() { errc <- minter.drain(5 * time.Second) }()
+
+	for i := 0; i < 2; i++ {
+		time.Sleep(2 * tickerMS * time.Millisecond)
+		select {
+		case err := <-errc:
+			t.Fatalf("drain returned with %d blocks unapplied: %v", 2-i, err)
+		default:
+		}
+		if atomic.LoadInt32(&minter.minting) != 0 {
+			t.Fatalf("still minting while draining")
+		}
+		minter.mu.Lock()
+		minter.speculativeChain.accept(minter.speculativeChain.unappliedBlocks.First().(*types.Block))
+		minter.mu.Unlock()
+	}
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("failed to drain: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("drain did not return after all blocks were applied")
+	}
+	// Unlike stopping, draining keeps the speculative chain
+	if number := minter.speculativeChain.head.NumberU64(); number != 2 {
+		t.Errorf("speculative head mismatch: have %d, want 2", number)
+	}
+}
+
+// Tests that draining gives up if the minted blocks are not applied in time.
+func TestMinterDrainTimeout(t *testing.T) {
+	minter := newTestMintingMinter(1)
+
+	if err := minter.drain(3 * tickerMS * time.Millisecond); err == nil {
+		t.Fatalf("drain succeeded with an unapplied block")
+	}
+	if atomic.LoadInt32(&minter.minting) != 0 {
+		t.Errorf("still minting after draining")
+	}
+	if pending := minter.speculativeChain.unappliedBlocks.Size(); pending != 1 {
+		t.Errorf("unapplied blocks mismatch: have %d, want 1", pending)
+	}
+}
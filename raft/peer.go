@@ -1,6 +1,7 @@
 package raft
 
 import (
+	"encoding/json"
 	"io"
 	"net"
 
@@ -58,6 +59,35 @@ func (addr *Address) DecodeRLP(s *rlp.Stream) error {
 	}
 }
 
+// JSON Address encoding, for the RPC API.
+
+type addressJSON struct {
+	RaftId   uint16          `json:"raftId"`
+	NodeId   discover.NodeID `json:"nodeId"`
+	Ip       net.IP          `json:"ip"`
+	P2pPort  uint16          `json:"p2pPort"`
+	RaftPort uint16          `json:"raftPort"`
+}
+
+func (addr *Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(addressJSON{addr.raftId, addr.nodeId, addr.ip, addr.p2pPort, addr.raftPort})
+}
+
+func (addr *Address) UnmarshalJSON(input []byte) error {
+	var temp addressJSON
+	if err := json.Unmarshal(input, &temp); err != nil {
+		return err
+	}
+	addr.raftId, addr.nodeId, addr.ip, addr.p2pPort, addr.raftPort = temp.RaftId, temp.NodeId, temp.Ip, temp.P2pPort, temp.RaftPort
+	return nil
+}
+
+// RaftId returns the Raft ID of the member.
+func (addr *Address) RaftId() uint16 { return addr.raftId }
+
+// NodeId returns the ID of the member's p2p node.
+func (addr *Address) NodeId() discover.NodeID { return addr.nodeId }
+
 // RLP Address encoding, for transport over raft and storage in LevelDB.
 
 func (addr *Address) toBytes() []byte {
@@ -7,14 +7,14 @@ import (
 	"sort"
 	"time"
 
-	"github.com/coreos/etcd/raft/raftpb"
-	"github.com/coreos/etcd/snap"
-	"github.com/coreos/etcd/wal/walpb"
+	"github.com/InsighterInc/bxmp/bxm/downloader"
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/core/types"
-	"github.com/InsighterInc/bxmp/bxm/downloader"
 	"github.com/InsighterInc/bxmp/log"
 	"github.com/InsighterInc/bxmp/rlp"
+	"go.etcd.io/etcd/etcdserver/api/snap"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.etcd.io/etcd/wal/walpb"
 	"gopkg.in/fatih/set.v0"
 )
 
@@ -36,7 +36,7 @@ func (pm *ProtocolManager) buildSnapshot() *Snapshot {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
-	numNodes := len(pm.confState.Nodes)
+	numNodes := len(pm.confState.Voters) + len(pm.confState.Learners)
 	numRemovedNodes := pm.removedPeers.Size()
 
 	snapshot := &Snapshot{
@@ -45,9 +45,10 @@ func (pm *ProtocolManager) buildSnapshot() *Snapshot {
 		headBlockHash:  pm.blockchain.CurrentBlock().Hash(),
 	}
 
-	// Populate addresses
+	// Populate addresses of voters and learners alike; which members are
+	// learners is recorded in the ConfState of the raft snapshot.
 
-	for i, rawRaftId := range pm.confState.Nodes {
+	for i, rawRaftId := range confStateIds(pm.confState) {
 		raftId := uint16(rawRaftId)
 
 		if raftId == pm.raftId {
@@ -99,9 +100,14 @@ func (pm *ProtocolManager) triggerSnapshot(index uint64) {
 	pm.mu.Unlock()
 }
 
+// confStateIds returns the raft IDs of the voters and learners in the ConfState.
+func confStateIds(confState raftpb.ConfState) []uint64 {
+	return append(append([]uint64{}, confState.Voters...), confState.Learners...)
+}
+
 func confStateIdSet(confState raftpb.ConfState) *set.Set {
 	set := set.New()
-	for _, rawRaftId := range confState.Nodes {
+	for _, rawRaftId := range confStateIds(confState) {
 		set.Add(uint16(rawRaftId))
 	}
 	return set
@@ -122,6 +128,7 @@ func (pm *ProtocolManager) updateClusterMembership(newConfState raftpb.ConfState
 	pm.mu.Lock()
 	pm.removedPeers = removedPeers
 	pm.mu.Unlock()
+	pm.updateLearners(newConfState)
 
 	// Remove old peers that we're still connected to
 
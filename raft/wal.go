@@ -3,10 +3,10 @@ package raft
 import (
 	"os"
 
-	"github.com/coreos/etcd/raft/raftpb"
-	"github.com/coreos/etcd/wal"
-	"github.com/coreos/etcd/wal/walpb"
 	"github.com/InsighterInc/bxmp/log"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.etcd.io/etcd/wal"
+	"go.etcd.io/etcd/wal/walpb"
 )
 
 func (pm *ProtocolManager) openWAL(maybeRaftSnapshot *raftpb.Snapshot) *wal.WAL {
@@ -15,7 +15,7 @@ func (pm *ProtocolManager) openWAL(maybeRaftSnapshot *raftpb.Snapshot) *wal.WAL
 			fatalf("cannot create waldir: %s", err)
 		}
 
-		wal, err := wal.Create(pm.waldir, nil)
+		wal, err := wal.Create(nil, pm.waldir, nil)
 		if err != nil {
 			fatalf("failed to create waldir: %s", err)
 		}
@@ -30,7 +30,7 @@ func (pm *ProtocolManager) openWAL(maybeRaftSnapshot *raftpb.Snapshot) *wal.WAL
 		walsnap.Index, walsnap.Term = maybeRaftSnapshot.Metadata.Index, maybeRaftSnapshot.Metadata.Term
 	}
 
-	wal, err := wal.Open(pm.waldir, walsnap)
+	wal, err := wal.Open(nil, pm.waldir, walsnap)
 	if err != nil {
 		fatalf("error loading WAL: %s", err)
 	}
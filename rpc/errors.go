@@ -30,6 +30,18 @@ func (e *methodNotFoundError) Error() string {
 	return fmt.Sprintf("The method %s%s%s does not exist/is not available", e.service, serviceMethodSeparator, e.method)
 }
 
+// request is for a method the client isn't permitted to call
+type permissionDeniedError struct {
+	service string
+	method  string
+}
+
+func (e *permissionDeniedError) ErrorCode() int { return -32001 }
+
+func (e *permissionDeniedError) Error() string {
+	return fmt.Sprintf("The method %s%s%s is not permitted", e.service, serviceMethodSeparator, e.method)
+}
+
 // received message isn't a valid request
 type invalidRequestError struct{ message string }
 
@@ -146,10 +146,11 @@ func (t *httpReadWriteNopCloser) Close() error {
 //
 // Deprecated: Server implements http.Handler
 func NewHTTPServer(cors []string, srv *Server) *http.Server {
-	return &http.Server{Handler: newCorsHandler(srv, cors)}
+	return &http.Server{Handler: NewCorsHandler(srv, cors)}
 }
 
-// ServeHTTP serves JSON-RPC requests over HTTP.
+// ServeHTTP serves JSON-RPC requests over HTTP. Requests are restricted to the
+// permissions attached to the context of r, if any.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.ContentLength > maxHTTPRequestContentLength {
 		http.Error(w,
@@ -164,10 +165,13 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// a single request.
 	codec := NewJSONCodec(&httpReadWriteNopCloser{r.Body, w})
 	defer codec.Close()
-	srv.ServeSingleRequest(codec, OptionMethodInvocation)
+	srv.serveRequest(servingContext(r.Context()), codec, true, OptionMethodInvocation)
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+// NewCorsHandler wraps an HTTP handler with the checks of the Cross-Origin
+// Resource Sharing policy allowing the given origins. CORS preflight requests
+// are answered without calling the wrapped handler.
+func NewCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
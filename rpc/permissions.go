@@ -0,0 +1,69 @@
+// Copyright 2017 The BXMP Authors
+// This file is part of the BXMP library.
+//
+// The BXMP library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The BXMP library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the BXMP library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+)
+
+// Permissions lists the RPC methods a client is permitted to call. Every entry
+// is either "*" for all methods, a namespace such as "eth" or "eth_*" for all
+// the methods of the namespace, or a single method such as "admin_nodeInfo".
+// Subscriptions are permitted by their subscribe method, e.g. "eth_subscribe",
+// while unsubscribing and the built-in "rpc" namespace are always permitted.
+type Permissions []string
+
+// Allowed reports whether the given method of the given namespace may be called.
+func (p Permissions) Allowed(namespace, method string) bool {
+	if namespace == MetadataApi {
+		return true
+	}
+	for _, entry := range p {
+		switch entry {
+		case "*", namespace, namespace + serviceMethodSeparator + "*", namespace + serviceMethodSeparator + method:
+			return true
+		}
+	}
+	return false
+}
+
+type permissionsKey struct{}
+
+// WithPermissions returns a copy of the context restricting the RPC requests
+// served with it to the given permissions. Transports serving requests on
+// behalf of authenticated clients attach the permissions of the clients to the
+// context of their HTTP requests.
+func WithPermissions(ctx context.Context, permissions Permissions) context.Context {
+	return context.WithValue(ctx, permissionsKey{}, permissions)
+}
+
+// PermissionsFromContext returns the permissions attached to the context, if
+// any. Requests served without permissions attached aren't restricted.
+func PermissionsFromContext(ctx context.Context) (Permissions, bool) {
+	permissions, ok := ctx.Value(permissionsKey{}).(Permissions)
+	return permissions, ok
+}
+
+// servingContext returns the root context to serve the requests of a
+// connection with, carrying over the permissions attached to the context of
+// the HTTP request it was opened with.
+func servingContext(ctx context.Context) context.Context {
+	if permissions, ok := PermissionsFromContext(ctx); ok {
+		return WithPermissions(context.Background(), permissions)
+	}
+	return context.Background()
+}
@@ -20,7 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -160,3 +162,61 @@ func TestServerMethodExecution(t *testing.T) {
 func TestServerMethodWithCtx(t *testing.T) {
 	testServerMethodExecution(t, "echoWithCtx")
 }
+
+func TestPermissionsAllowed(t *testing.T) {
+	tests := []struct {
+		permissions Permissions
+		namespace   string
+		method      string
+		allowed     bool
+	}{
+		{nil, "eth", "blockNumber", false},
+		{nil, MetadataApi, "modules", true},
+		{Permissions{"*"}, "admin", "addPeer", true},
+		{Permissions{"eth"}, "eth", "blockNumber", true},
+		{Permissions{"eth_*"}, "eth", "blockNumber", true},
+		{Permissions{"eth"}, "personal", "unlockAccount", false},
+		{Permissions{"admin_nodeInfo"}, "admin", "nodeInfo", true},
+		{Permissions{"admin_nodeInfo"}, "admin", "addPeer", false},
+		{Permissions{"eth_subscribe"}, "eth", "subscribe", true},
+	}
+	for i, tt := range tests {
+		if allowed := tt.permissions.Allowed(tt.namespace, tt.method); allowed != tt.allowed {
+			t.Errorf("test %d: %v allowed %s_%s mismatch: have %v, want %v", i, tt.permissions, tt.namespace, tt.method, allowed, tt.allowed)
+		}
+	}
+}
+
+func TestServerPermissions(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	call := func(permissions Permissions, method string) *jsonError {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[]}`
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		if permissions != nil {
+			req = req.WithContext(WithPermissions(req.Context(), permissions))
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+
+		var response jsonErrResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: invalid response %q: %v", method, recorder.Body.String(), err)
+		}
+		return &response.Error
+	}
+	if err := call(nil, "test_rets"); err.Code != 0 {
+		t.Errorf("unrestricted call failed: %v", err.Message)
+	}
+	if err := call(Permissions{"test_rets"}, "test_rets"); err.Code != 0 {
+		t.Errorf("permitted call failed: %v", err.Message)
+	}
+	if err := call(Permissions{"test_rets"}, "rpc_modules"); err.Code != 0 {
+		t.Errorf("metadata call failed: %v", err.Message)
+	}
+	if err := call(Permissions{"test_rets"}, "test_noArgsRets"); err.Code != -32001 {
+		t.Errorf("forbidden call error code mismatch: have %d, want %d", err.Code, -32001)
+	}
+}
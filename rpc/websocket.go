@@ -40,7 +40,9 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 	return websocket.Server{
 		Handshake: wsHandshakeValidator(allowedOrigins),
 		Handler: func(conn *websocket.Conn) {
-			srv.ServeCodec(NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
+			codec := NewJSONCodec(conn)
+			defer codec.Close()
+			srv.serveRequest(servingContext(conn.Request().Context()), codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -21,6 +21,7 @@ import (
 
 	"github.com/InsighterInc/bxmp/common"
 	"github.com/InsighterInc/bxmp/log"
+	"github.com/InsighterInc/bxmp/rlp"
 )
 
 var secureKeyPrefix = []byte("secure-key-")
@@ -185,6 +186,12 @@ func (t *SecureTrie) CommitTo(db DatabaseWriter) (root common.Hash, err error) {
 	return t.trie.CommitTo(db)
 }
 
+// Prove constructs a merkle proof for key, see Trie.Prove. The key is hashed
+// before the lookup, VerifyProof must be called with the hashed key.
+func (t *SecureTrie) Prove(key []byte) []rlp.RawValue {
+	return t.trie.Prove(t.hashKey(key))
+}
+
 // secKey returns the database key for the preimage of key, as an ephemeral buffer.
 // The caller must not hold onto the return value because it will become
 // invalid on the next call to hashKey or secKey.